@@ -0,0 +1,44 @@
+package req
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/blocklist"
+	"github.com/gin-gonic/gin"
+)
+
+type CreateBlocklistEntryReqModel struct {
+	Type      string     `json:"type" binding:"required,oneof=card_fingerprint bin ip email"`
+	Value     string     `json:"value" binding:"required"`
+	Reason    string     `json:"reason" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" extensions:"x-nullable"`
+}
+
+func (model *CreateBlocklistEntryReqModel) Validate(c *gin.Context) error {
+	err := c.BindJSON(model)
+	if err != nil {
+		return err
+	}
+	switch model.Type {
+	case blocklist.BIN:
+		if len(model.Value) < 6 || len(model.Value) > 8 || !isNumeric(model.Value) {
+			return errors.New("bin must be 6 to 8 digits")
+		}
+	case blocklist.IP:
+		if _, _, cidrErr := net.ParseCIDR(model.Value); cidrErr != nil && net.ParseIP(model.Value) == nil {
+			return errors.New("ip must be an address or a CIDR range")
+		}
+	}
+	return nil
+}
+
+func isNumeric(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,17 @@
+package req
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type CapturePaymentReqModel struct {
+	Amount int `json:"amount" binding:"required,gt=0"`
+}
+
+func (model *CapturePaymentReqModel) Validate(c *gin.Context) error {
+	err := c.BindJSON(model)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package req
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type CapturePaymentV2ReqModel struct {
+	Amount MoneyV2ReqModel `json:"amount"`
+}
+
+func (model *CapturePaymentV2ReqModel) Validate(c *gin.Context) error {
+	err := c.BindJSON(model)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -5,12 +5,21 @@ import (
 )
 
 type CreatePaymentReqModel struct {
-	CardNumber      string `json:"card_number" binding:"required,gte=14,lte=19,number"`
-	ExpirationMonth int    `json:"expiration_month" binding:"required,gte=1,lte=12"`
-	ExpirationYear  int    `json:"expiration_year" binding:"required"`
+	CardNumber      string `json:"card_number,omitempty" binding:"required_without=PaymentMethodId,excluded_with=PaymentMethodId,omitempty,gte=14,lte=19,number"`
+	ExpirationMonth int    `json:"expiration_month,omitempty" binding:"required_without=PaymentMethodId,omitempty,gte=1,lte=12"`
+	ExpirationYear  int    `json:"expiration_year,omitempty" binding:"required_without=PaymentMethodId"`
 	Currency        string `json:"currency" binding:"required,iso4217"`
 	Amount          int    `json:"amount" binding:"required"`
 	CVV             string `json:"cvv" binding:"required,number,gte=3,lte=4"`
+	Email           string `json:"email,omitempty" binding:"omitempty,email"`
+	ThreeDS         bool   `json:"three_ds"`
+	// SetupMandate stores the card for later merchant-initiated payments
+	// once this payment is authorized.
+	SetupMandate bool `json:"setup_mandate"`
+	// PaymentMethodId pays with a card saved on CustomerId instead of the
+	// card fields above.
+	CustomerId      string `json:"customer_id,omitempty" binding:"required_with=PaymentMethodId"`
+	PaymentMethodId string `json:"payment_method_id,omitempty"`
 }
 
 func (model *CreatePaymentReqModel) Validate(c *gin.Context) error {
@@ -0,0 +1,37 @@
+package req
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type CardV2ReqModel struct {
+	Number      string `json:"number" binding:"required,gte=14,lte=19,number"`
+	ExpiryMonth int    `json:"expiry_month" binding:"required,gte=1,lte=12"`
+	ExpiryYear  int    `json:"expiry_year" binding:"required"`
+}
+
+type MoneyV2ReqModel struct {
+	Value    int    `json:"value" binding:"required,gt=0"`
+	Currency string `json:"currency" binding:"required,iso4217"`
+}
+
+type CreatePaymentV2ReqModel struct {
+	Card   *CardV2ReqModel `json:"card,omitempty" binding:"required_without=PaymentMethodId,excluded_with=PaymentMethodId"`
+	Amount MoneyV2ReqModel `json:"amount"`
+	// CVV stays outside card as it is asked for saved cards too.
+	CVV          string `json:"cvv" binding:"required,number,gte=3,lte=4"`
+	Email        string `json:"email,omitempty" binding:"omitempty,email"`
+	ThreeDS      bool   `json:"three_ds"`
+	SetupMandate bool   `json:"setup_mandate"`
+	// PaymentMethodId pays with a card saved on CustomerId instead of card.
+	CustomerId      string `json:"customer_id,omitempty" binding:"required_with=PaymentMethodId"`
+	PaymentMethodId string `json:"payment_method_id,omitempty"`
+}
+
+func (model *CreatePaymentV2ReqModel) Validate(c *gin.Context) error {
+	err := c.BindJSON(model)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package req
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type CreateCustomerReqModel struct {
+	Email string `json:"email" binding:"omitempty,email"`
+	Name  string `json:"name" binding:"max=200"`
+}
+
+func (model *CreateCustomerReqModel) Validate(c *gin.Context) error {
+	err := c.BindJSON(model)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type AttachCardReqModel struct {
+	CardNumber      string `json:"card_number" binding:"required,gte=14,lte=19,number"`
+	ExpirationMonth int    `json:"expiration_month" binding:"required,gte=1,lte=12"`
+	ExpirationYear  int    `json:"expiration_year" binding:"required"`
+}
+
+func (model *AttachCardReqModel) Validate(c *gin.Context) error {
+	err := c.BindJSON(model)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package req
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type MandatePaymentReqModel struct {
+	Currency string `json:"currency" binding:"required,iso4217"`
+	Amount   int    `json:"amount" binding:"required,gt=0"`
+}
+
+func (model *MandatePaymentReqModel) Validate(c *gin.Context) error {
+	err := c.BindJSON(model)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package req
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type RefundPaymentV2ReqModel struct {
+	Amount MoneyV2ReqModel `json:"amount"`
+}
+
+func (model *RefundPaymentV2ReqModel) Validate(c *gin.Context) error {
+	err := c.BindJSON(model)
+	if err != nil {
+		return err
+	}
+	return nil
+}
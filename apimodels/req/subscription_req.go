@@ -0,0 +1,33 @@
+package req
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateSubscriptionReqModel struct {
+	MandateId      string     `json:"mandate_id" binding:"required"`
+	Currency       string     `json:"currency" binding:"required,iso4217"`
+	Amount         int        `json:"amount" binding:"required,gt=0"`
+	IntervalMonths int        `json:"interval_months" binding:"required,gte=1,lte=12"`
+	StartAt        *time.Time `json:"start_at,omitempty" extensions:"x-nullable"`
+	// RetrySchedule lists the delays, such as "24h", before each retry of a
+	// declined charge.
+	RetrySchedule []string `json:"retry_schedule,omitempty" binding:"max=10"`
+}
+
+func (model *CreateSubscriptionReqModel) Validate(c *gin.Context) error {
+	err := c.BindJSON(model)
+	if err != nil {
+		return err
+	}
+	for _, delay := range model.RetrySchedule {
+		parsed, err := time.ParseDuration(delay)
+		if err != nil || parsed <= 0 {
+			return errors.New("retry_schedule must contain positive durations such as 24h")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package req
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// ThreeDSCallbackReqModel only names the challenge. Its outcome is read
+// from the directory server, never taken from the caller.
+type ThreeDSCallbackReqModel struct {
+	ChallengeId string `json:"challenge_id" form:"challenge_id" binding:"required"`
+}
+
+// Validate accepts JSON as well as the form posted by a challenge page.
+func (model *ThreeDSCallbackReqModel) Validate(c *gin.Context) error {
+	err := c.Bind(model)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+package res
+
+type Balance struct {
+	Currency  string `json:"currency"`
+	Available int    `json:"available"`
+	Pending   int    `json:"pending"`
+}
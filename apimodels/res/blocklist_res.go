@@ -0,0 +1,12 @@
+package res
+
+import "time"
+
+type BlocklistEntry struct {
+	Id        string     `json:"id"`
+	Type      string     `json:"type"`
+	Value     string     `json:"value"`
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at" extensions:"x-nullable"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package res
+
+import "time"
+
+type Customer struct {
+	Id             string          `json:"id"`
+	Email          string          `json:"email"`
+	Name           string          `json:"name"`
+	PaymentMethods []PaymentMethod `json:"payment_methods"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type PaymentMethod struct {
+	Id                string    `json:"id"`
+	Brand             string    `json:"brand"`
+	LastFourCardDigit string    `json:"last_four_card_digit"`
+	ExpiryMonth       int       `json:"expiry_month"`
+	ExpiryYear        int       `json:"expiry_year"`
+	CreatedAt         time.Time `json:"created_at"`
+}
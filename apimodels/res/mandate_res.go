@@ -0,0 +1,27 @@
+package res
+
+import "time"
+
+type Mandate struct {
+	Id                string    `json:"id"`
+	Status            string    `json:"status"`
+	LastFourCardDigit string    `json:"last_four_card_digit"`
+	ExpiryMonth       int       `json:"expiry_month"`
+	ExpiryYear        int       `json:"expiry_year"`
+	InitialPaymentId  string    `json:"initial_payment_id"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type Subscription struct {
+	Id             string    `json:"id"`
+	MandateId      string    `json:"mandate_id"`
+	Status         string    `json:"status"`
+	Currency       string    `json:"currency"`
+	Amount         int       `json:"amount"`
+	IntervalMonths int       `json:"interval_months"`
+	RetrySchedule  []string  `json:"retry_schedule"`
+	NextChargeAt   time.Time `json:"next_charge_at"`
+	FailedAttempts int       `json:"failed_attempts"`
+	PaymentIds     []string  `json:"payment_ids"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -1,16 +1,87 @@
 package res
 
+import "time"
+
 type PaymentDetails struct {
-	Id                string `json:"id"`
-	Status            string `json:"status"`
-	LastFourCardDigit string `json:"last_four_card_digit"`
-	ExpiryMonth       int    `json:"expiry_month"`
-	ExpiryYear        int    `json:"expiry_year"`
-	CurrencyCode      string `json:"currency_code"`
-	Amount            int    `json:"amount"`
+	Id                string    `json:"id"`
+	Status            string    `json:"status"`
+	CustomerId        string    `json:"customer_id,omitempty"`
+	ReasonCode        string    `json:"reason_code,omitempty"`
+	LastFourCardDigit string    `json:"last_four_card_digit"`
+	CardFingerprint   string    `json:"card_fingerprint,omitempty"`
+	Bin               *Bin      `json:"bin,omitempty"`
+	ExpiryMonth       int       `json:"expiry_month"`
+	ExpiryYear        int       `json:"expiry_year"`
+	CurrencyCode      string    `json:"currency_code"`
+	Amount            int       `json:"amount"`
+	Acquirer          string    `json:"acquirer"`
+	CapturedAmount    int       `json:"captured_amount"`
+	RefundedAmount    int       `json:"refunded_amount"`
+	Refunds           []Refund  `json:"refunds"`
+	ThreeDS           *ThreeDS  `json:"three_ds,omitempty"`
+	Risk              *Risk     `json:"risk,omitempty"`
+	FX                *FX       `json:"fx,omitempty"`
+	MandateId         string    `json:"mandate_id,omitempty"`
+	MerchantInitiated bool      `json:"merchant_initiated"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// Bin describes the issuer of the card, when its BIN is known.
+type Bin struct {
+	Scheme     string `json:"scheme"`
+	Issuer     string `json:"issuer"`
+	Country    string `json:"country"`
+	Funding    string `json:"funding"`
+	Commercial bool   `json:"commercial"`
+}
+
+type Risk struct {
+	Score    int      `json:"score"`
+	Decision string   `json:"decision"`
+	Rules    []string `json:"rules"`
+}
+
+type FX struct {
+	SettlementCurrency string    `json:"settlement_currency"`
+	SettlementAmount   int       `json:"settlement_amount"`
+	Rate               float64   `json:"rate"`
+	RateTimestamp      time.Time `json:"rate_timestamp"`
+	RateSource         string    `json:"rate_source"`
+}
+
+type ThreeDS struct {
+	ChallengeURL   string `json:"challenge_url,omitempty"`
+	Status         string `json:"status,omitempty"`
+	ECI            string `json:"eci,omitempty"`
+	LiabilityShift bool   `json:"liability_shift"`
+}
+
+type Refund struct {
+	Id        string    `json:"id"`
+	Amount    int       `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PaymentEvent struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Timestamp time.Time `json:"timestamp"`
+	Reason    string    `json:"reason"`
+	Actor     string    `json:"actor"`
 }
 
 type ProcessPaymentRes struct {
 	Id     string `json:"id"`
 	Status string `json:"status"`
 }
+
+type PaymentStatusUpdate struct {
+	PaymentId string    `json:"payment_id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Timestamp time.Time `json:"timestamp"`
+	Reason    string    `json:"reason"`
+	Actor     string    `json:"actor"`
+	Version   int       `json:"version"`
+}
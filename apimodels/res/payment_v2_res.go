@@ -0,0 +1,49 @@
+package res
+
+import "time"
+
+type PaymentDetailsV2 struct {
+	Id                string     `json:"id"`
+	Status            string     `json:"status"`
+	CustomerId        string     `json:"customer_id,omitempty"`
+	ReasonCode        string     `json:"reason_code,omitempty"`
+	Card              CardV2     `json:"card"`
+	Amount            MoneyV2    `json:"amount"`
+	Captured          MoneyV2    `json:"captured"`
+	Refunded          MoneyV2    `json:"refunded"`
+	Acquirer          string     `json:"acquirer"`
+	Refunds           []RefundV2 `json:"refunds"`
+	ThreeDS           *ThreeDS   `json:"three_ds,omitempty"`
+	Risk              *Risk      `json:"risk,omitempty"`
+	FX                *FXV2      `json:"fx,omitempty"`
+	MandateId         string     `json:"mandate_id,omitempty"`
+	MerchantInitiated bool       `json:"merchant_initiated"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+type CardV2 struct {
+	Last4       string `json:"last4"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Bin         *Bin   `json:"bin,omitempty"`
+	ExpiryMonth int    `json:"expiry_month"`
+	ExpiryYear  int    `json:"expiry_year"`
+}
+
+type MoneyV2 struct {
+	Value    int    `json:"value"`
+	Currency string `json:"currency"`
+}
+
+type RefundV2 struct {
+	Id        string    `json:"id"`
+	Amount    MoneyV2   `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type FXV2 struct {
+	Settlement    MoneyV2   `json:"settlement"`
+	Rate          float64   `json:"rate"`
+	RateTimestamp time.Time `json:"rate_timestamp"`
+	RateSource    string    `json:"rate_source"`
+}
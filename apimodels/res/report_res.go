@@ -0,0 +1,32 @@
+package res
+
+import "time"
+
+type Report struct {
+	Id          string      `json:"id"`
+	Date        string      `json:"date"`
+	Rows        []ReportRow `json:"rows"`
+	GeneratedAt time.Time   `json:"generated_at"`
+}
+
+type ReportRow struct {
+	Currency    string `json:"currency"`
+	Gross       int    `json:"gross"`
+	Fees        int    `json:"fees"`
+	Refunds     int    `json:"refunds"`
+	Net         int    `json:"net"`
+	Captures    int    `json:"captures"`
+	RefundCount int    `json:"refund_count"`
+}
+
+type Reconciliation struct {
+	Matched       int           `json:"matched"`
+	Discrepancies []Discrepancy `json:"discrepancies"`
+}
+
+type Discrepancy struct {
+	PaymentId string `json:"payment_id"`
+	Code      string `json:"code"`
+	Expected  string `json:"expected,omitempty"`
+	Actual    string `json:"actual,omitempty"`
+}
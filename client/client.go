@@ -0,0 +1,216 @@
+// Package client calls the payment gateway's API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"github.com/google/uuid"
+)
+
+const (
+	MERCHANT_ID_HEADER     = "X-Merchant-Id"
+	API_KEY_HEADER         = "X-Api-Key"
+	IDEMPOTENCY_KEY_HEADER = "Idempotency-Key"
+	IF_MATCH_HEADER        = "If-Match"
+	DEFAULT_MAX_RETRIES    = 3
+)
+
+type Config struct {
+	BaseURL    string
+	MerchantId string
+	APIKey     string
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// MaxRetries is how often a request answered with a 429 or 5xx, or that
+	// failed to reach the gateway, is sent again. Negative disables retries.
+	MaxRetries int
+	// Backoff returns the delay before the given retry, starting at 1. A
+	// 429's Retry-After takes precedence.
+	Backoff func(retry int) time.Duration
+}
+
+type Client struct {
+	baseURL    string
+	merchantId string
+	apiKey     string
+	httpClient *http.Client
+	maxRetries int
+	backoff    func(retry int) time.Duration
+}
+
+func New(config Config) *Client {
+	client := &Client{
+		baseURL:    strings.TrimSuffix(config.BaseURL, "/"),
+		merchantId: config.MerchantId,
+		apiKey:     config.APIKey,
+		httpClient: config.HTTPClient,
+		maxRetries: config.MaxRetries,
+		backoff:    config.Backoff,
+	}
+	if client.httpClient == nil {
+		client.httpClient = http.DefaultClient
+	}
+	if client.maxRetries == 0 {
+		client.maxRetries = DEFAULT_MAX_RETRIES
+	}
+	if client.backoff == nil {
+		client.backoff = ExponentialBackoff
+	}
+	return client
+}
+
+// ExponentialBackoff waits 200ms before the first retry and doubles the
+// delay for each one after, with up to 50% jitter.
+func ExponentialBackoff(retry int) time.Duration {
+	delay := 200 * time.Millisecond << (retry - 1)
+	return delay + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey makes the POST sent with ctx use key rather than a
+// generated one, so that it can be retried safely after a restart.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+type ifMatch struct{}
+
+// WithIfMatch makes the request sent with ctx apply only while the payment
+// still has etag, failing with ErrPreconditionFailed once it has changed.
+func WithIfMatch(ctx context.Context, etag string) context.Context {
+	return context.WithValue(ctx, ifMatch{}, etag)
+}
+
+// envelope is api_response.Response with its data left encoded.
+type envelope struct {
+	Code       int                              `json:"code"`
+	Message    string                           `json:"message"`
+	Errors     []string                         `json:"errors"`
+	Data       json.RawMessage                  `json:"data"`
+	Pagination *api_response.PaginationResponse `json:"pagination"`
+	ETag       string                           `json:"-"`
+}
+
+// do sends the request, retrying it as configured, and decodes the data of
+// a successful response into out, or copies the body when out is a *[]byte.
+// POSTs carry an idempotency key that is kept across retries.
+func (client *Client) do(ctx context.Context, method string, path string, query url.Values, body interface{}, out interface{}) (*envelope, error) {
+	var payload []byte
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		payload = encoded
+	}
+	key := ""
+	if method == http.MethodPost {
+		key, _ = ctx.Value(idempotencyKey{}).(string)
+		if key == "" {
+			key = uuid.NewString()
+		}
+	}
+	target := client.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		response, err := client.send(ctx, method, target, payload, key)
+		var delay time.Duration
+		if err == nil {
+			result, retryAfter, retry, decodeErr := decode(response, out)
+			if !retry || attempt >= client.maxRetries {
+				return result, decodeErr
+			}
+			delay = retryAfter
+			err = decodeErr
+		} else if ctx.Err() != nil || attempt >= client.maxRetries {
+			return nil, err
+		}
+		if delay == 0 {
+			delay = client.backoff(attempt + 1)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (client *Client) send(ctx context.Context, method string, target string, payload []byte, key string) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+	if payload != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if client.merchantId != "" {
+		request.Header.Set(MERCHANT_ID_HEADER, client.merchantId)
+	}
+	if client.apiKey != "" {
+		request.Header.Set(API_KEY_HEADER, client.apiKey)
+	}
+	if key != "" {
+		request.Header.Set(IDEMPOTENCY_KEY_HEADER, key)
+	}
+	if etag, _ := ctx.Value(ifMatch{}).(string); etag != "" {
+		request.Header.Set(IF_MATCH_HEADER, etag)
+	}
+	return client.httpClient.Do(request)
+}
+
+// decode reads the response envelope. It reports whether the request should
+// be retried and for how long the gateway asked the client to wait.
+func decode(response *http.Response, out interface{}) (*envelope, time.Duration, bool, error) {
+	defer response.Body.Close()
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, 0, true, err
+	}
+	retry := response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= http.StatusInternalServerError
+	var retryAfter time.Duration
+	if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+
+	if raw, ok := out.(*[]byte); ok && response.StatusCode < http.StatusBadRequest {
+		*raw = content
+		return &envelope{Code: response.StatusCode}, 0, false, nil
+	}
+	var result envelope
+	if err := json.Unmarshal(content, &result); err != nil {
+		if response.StatusCode >= http.StatusBadRequest {
+			return nil, retryAfter, retry, &APIError{StatusCode: response.StatusCode, Message: http.StatusText(response.StatusCode)}
+		}
+		return nil, 0, false, err
+	}
+	if response.StatusCode >= http.StatusBadRequest {
+		return &result, retryAfter, retry, &APIError{StatusCode: response.StatusCode, Message: result.Message, Errors: result.Errors}
+	}
+	result.ETag = response.Header.Get("ETag")
+	if out != nil && len(result.Data) > 0 {
+		if err := json.Unmarshal(result.Data, out); err != nil {
+			return nil, 0, false, err
+		}
+	}
+	return &result, 0, false, nil
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrBadRequest     = errors.New("bad request")
+	ErrNotFound       = errors.New("not found")
+	ErrConflict       = errors.New("conflict")
+	ErrUnprocessable  = errors.New("unprocessable")
+	ErrRateLimited    = errors.New("rate limited")
+	ErrGatewayFailure = errors.New("gateway failure")
+	// ErrPreconditionFailed is a request whose If-Match no longer matches.
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// APIError is an error response from the gateway. It matches the sentinel
+// errors above with errors.Is according to its status code.
+type APIError struct {
+	StatusCode int
+	Message    string
+	Errors     []string
+}
+
+func (err *APIError) Error() string {
+	message := strconv.Itoa(err.StatusCode) + " " + err.Message
+	if len(err.Errors) > 0 {
+		message += ": " + strings.Join(err.Errors, "; ")
+	}
+	return message
+}
+
+func (err *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return err.StatusCode == http.StatusBadRequest
+	case ErrNotFound:
+		return err.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return err.StatusCode == http.StatusConflict
+	case ErrUnprocessable:
+		return err.StatusCode == http.StatusUnprocessableEntity
+	case ErrPreconditionFailed:
+		return err.StatusCode == http.StatusPreconditionFailed
+	case ErrRateLimited:
+		return err.StatusCode == http.StatusTooManyRequests
+	case ErrGatewayFailure:
+		return err.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/res"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+)
+
+const paymentsPath = "/api/v1/payments"
+
+type ListPaymentsParams struct {
+	// Status only lists payments with this status when set.
+	Status string
+	Page   int
+	Limit  int
+}
+
+type PaymentPage struct {
+	Payments   []res.PaymentDetails            `json:"payments"`
+	Pagination api_response.PaginationResponse `json:"pagination"`
+}
+
+// CreatePayment authorizes a payment. A payment that was declined, blocked
+// or needs 3-D Secure is returned without an error; check its Status.
+func (client *Client) CreatePayment(ctx context.Context, body req.CreatePaymentReqModel) (res.PaymentDetails, error) {
+	var payment res.PaymentDetails
+	_, err := client.do(ctx, http.MethodPost, paymentsPath, nil, body, &payment)
+	return payment, err
+}
+
+func (client *Client) GetPayment(ctx context.Context, id string) (res.PaymentDetails, error) {
+	var payment res.PaymentDetails
+	_, err := client.do(ctx, http.MethodGet, paymentsPath+"/"+url.PathEscape(id), nil, nil, &payment)
+	return payment, err
+}
+
+// GetPaymentWithETag also returns the payment's ETag, to pass to
+// WithIfMatch.
+func (client *Client) GetPaymentWithETag(ctx context.Context, id string) (res.PaymentDetails, string, error) {
+	var payment res.PaymentDetails
+	result, err := client.do(ctx, http.MethodGet, paymentsPath+"/"+url.PathEscape(id), nil, nil, &payment)
+	if err != nil {
+		return res.PaymentDetails{}, "", err
+	}
+	return payment, result.ETag, nil
+}
+
+func (client *Client) ListPayments(ctx context.Context, params ListPaymentsParams) (PaymentPage, error) {
+	query := url.Values{}
+	if params.Status != "" {
+		query.Set("status", params.Status)
+	}
+	if params.Page > 0 {
+		query.Set("page", strconv.Itoa(params.Page))
+	}
+	if params.Limit > 0 {
+		query.Set("limit", strconv.Itoa(params.Limit))
+	}
+	var page PaymentPage
+	result, err := client.do(ctx, http.MethodGet, paymentsPath, query, nil, &page.Payments)
+	if err != nil {
+		return PaymentPage{}, err
+	}
+	if result.Pagination != nil {
+		page.Pagination = *result.Pagination
+	}
+	return page, nil
+}
+
+func (client *Client) GetPaymentEvents(ctx context.Context, id string) ([]res.PaymentEvent, error) {
+	var events []res.PaymentEvent
+	_, err := client.do(ctx, http.MethodGet, paymentsPath+"/"+url.PathEscape(id)+"/events", nil, nil, &events)
+	return events, err
+}
+
+// Capture captures the payment whatever its current version, unless ctx
+// carries an ETag set with WithIfMatch.
+func (client *Client) Capture(ctx context.Context, id string, amount int) (res.PaymentDetails, error) {
+	if _, ok := ctx.Value(ifMatch{}).(string); !ok {
+		ctx = WithIfMatch(ctx, "*")
+	}
+	var payment res.PaymentDetails
+	_, err := client.do(ctx, http.MethodPost, paymentsPath+"/"+url.PathEscape(id)+"/captures", nil, req.CapturePaymentReqModel{Amount: amount}, &payment)
+	return payment, err
+}
+
+// Refund refunds the payment whatever its current version, unless ctx
+// carries an ETag set with WithIfMatch.
+func (client *Client) Refund(ctx context.Context, id string, amount int) (res.PaymentDetails, error) {
+	if _, ok := ctx.Value(ifMatch{}).(string); !ok {
+		ctx = WithIfMatch(ctx, "*")
+	}
+	var payment res.PaymentDetails
+	_, err := client.do(ctx, http.MethodPost, paymentsPath+"/"+url.PathEscape(id)+"/refunds", nil, req.RefundPaymentReqModel{Amount: amount}, &payment)
+	return payment, err
+}
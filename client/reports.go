@@ -0,0 +1,30 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/res"
+)
+
+const reportsPath = "/api/v1/reports"
+
+func (client *Client) ListReports(ctx context.Context) ([]res.Report, error) {
+	var reports []res.Report
+	_, err := client.do(ctx, http.MethodGet, reportsPath, nil, nil, &reports)
+	return reports, err
+}
+
+func (client *Client) GetReport(ctx context.Context, id string) (res.Report, error) {
+	var report res.Report
+	_, err := client.do(ctx, http.MethodGet, reportsPath+"/"+url.PathEscape(id), nil, nil, &report)
+	return report, err
+}
+
+// ReportCSV downloads a settlement report as CSV.
+func (client *Client) ReportCSV(ctx context.Context, id string) ([]byte, error) {
+	var content []byte
+	_, err := client.do(ctx, http.MethodGet, reportsPath+"/"+url.PathEscape(id), url.Values{"format": {"csv"}}, nil, &content)
+	return content, err
+}
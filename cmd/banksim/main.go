@@ -0,0 +1,21 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/banksim"
+)
+
+func main() {
+	var addr string
+	var config banksim.Config
+	flag.StringVar(&addr, "addr", ":8080", "Address to listen on")
+	flag.DurationVar(&config.Latency, "latency", 0, "Delay added to every response")
+	flag.Float64Var(&config.ErrorRate, "error-rate", 0, "Fraction of requests answered with a 500")
+	flag.Parse()
+
+	log.Printf("bank simulator listening on %s", addr)
+	log.Fatal(http.ListenAndServe(addr, banksim.NewHandler(config)))
+}
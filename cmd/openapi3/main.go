@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/docs"
+	"gopkg.in/yaml.v3"
+)
+
+// Writes the OpenAPI 3 export of docs/swagger.json. Run it after swag init.
+func main() {
+	var output string
+	flag.StringVar(&output, "output", "docs", "Directory to write openapi3.json and openapi3.yaml to")
+	flag.Parse()
+
+	spec, err := docs.ConvertSwagger()
+	if err != nil {
+		log.Fatalf("could not convert swagger spec: %v", err)
+	}
+	content, err := json.MarshalIndent(spec, "", "    ")
+	if err != nil {
+		log.Fatalf("could not encode openapi spec: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(output, "openapi3.json"), append(content, '\n'), 0o644); err != nil {
+		log.Fatalf("could not write openapi spec: %v", err)
+	}
+
+	var tree interface{}
+	if err := json.Unmarshal(content, &tree); err != nil {
+		log.Fatalf("could not decode openapi spec: %v", err)
+	}
+	yamlContent, err := yaml.Marshal(tree)
+	if err != nil {
+		log.Fatalf("could not encode openapi spec: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(output, "openapi3.yaml"), yamlContent, 0o644); err != nil {
+		log.Fatalf("could not write openapi spec: %v", err)
+	}
+}
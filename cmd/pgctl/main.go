@@ -0,0 +1,11 @@
+package main
+
+import (
+	"os"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/pgctl"
+)
+
+func main() {
+	os.Exit(pgctl.Run(os.Args[1:], os.Stdout, os.Stderr))
+}
@@ -0,0 +1,30 @@
+package docs
+
+import (
+	_ "embed"
+	"encoding/json"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+//go:embed swagger.json
+var swaggerJSON []byte
+
+//go:embed openapi3.json
+var openAPI3JSON []byte
+
+// OpenAPI3 loads the OpenAPI 3 export written by cmd/openapi3.
+func OpenAPI3() (*openapi3.T, error) {
+	return openapi3.NewLoader().LoadFromData(openAPI3JSON)
+}
+
+// ConvertSwagger converts the Swagger 2 spec generated by swag to OpenAPI 3.
+func ConvertSwagger() (*openapi3.T, error) {
+	var swagger openapi2.T
+	if err := json.Unmarshal(swaggerJSON, &swagger); err != nil {
+		return nil, err
+	}
+	return openapi2conv.ToV3(&swagger)
+}
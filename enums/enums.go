@@ -1,7 +1,21 @@
 package enums
 
 const (
-	AUTHORIZED string = "Authorized"
-	DECLIEND          = "Declined"
-	REJECTED          = "Rejected"
+	PENDING            string = "Pending"
+	REQUIRES_ACTION           = "RequiresAction"
+	AUTHORIZED                = "Authorized"
+	DECLIEND                  = "Declined"
+	REJECTED                  = "Rejected"
+	CAPTURED                  = "Captured"
+	PARTIALLY_REFUNDED        = "PartiallyRefunded"
+	REFUNDED                  = "Refunded"
+)
+
+const (
+	ACTOR_GATEWAY          string = "gateway"
+	ACTOR_ACQUIRING_BANK          = "acquiring_bank"
+	ACTOR_MERCHANT                = "merchant"
+	ACTOR_DIRECTORY_SERVER        = "directory_server"
+	ACTOR_RISK_ENGINE             = "risk_engine"
+	ACTOR_BLOCKLIST               = "blocklist"
 )
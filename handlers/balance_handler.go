@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"github.com/cko-recruitment/payment-gateway-challenge-go/ledger"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/mapper"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/middlewares"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+var merchantLedger = ledger.New(ledger.FeeSchedule{})
+
+// SetLedger replaces the ledger used to report merchant balances.
+func SetLedger(l *ledger.Ledger) {
+	merchantLedger = l
+}
+
+// GetBalances godoc
+// @Summary Get the merchant's balances
+// @Tags balances
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Success 200 {object} api_response.Response{data=[]res.Balance}
+// @Router /api/v1/balances [get]
+func GetBalances(context *gin.Context) {
+	balances := merchantLedger.Balances(merchantIdFrom(context))
+	res := api_response.BuildResponse(http.StatusOK, "", mapper.ToBalancesRes(balances))
+	context.JSON(res.Code, res)
+	return
+}
+
+func merchantIdFrom(context *gin.Context) string {
+	return middlewares.MerchantIdFrom(context)
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/mapper"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/blocklist"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ids"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+var paymentBlocklist = blocklist.New()
+
+// SetBlocklist replaces the blocklist checked before authorization.
+func SetBlocklist(b *blocklist.Blocklist) {
+	paymentBlocklist = b
+	updatePaymentService()
+}
+
+// AddBlocklistEntry godoc
+// @Summary Add a blocklist entry
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminApiKey
+// @Param request body req.CreateBlocklistEntryReqModel true "Entry"
+// @Success 201 {object} api_response.Response{data=res.BlocklistEntry}
+// @Failure 400 {object} api_response.Response
+// @Failure 401 {object} api_response.Response
+// @Failure 500 {object} api_response.Response
+// @Router /api/v1/admin/blocklist [post]
+func AddBlocklistEntry(context *gin.Context) {
+	body := &req.CreateBlocklistEntryReqModel{}
+	if err := body.Validate(context); err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	entry, err := paymentBlocklist.Add(mapper.ToBlocklistEntry(body, gatewayClock.Now()))
+	if err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusInternalServerError, "Internal Server Error", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	res := api_response.BuildResponse(http.StatusCreated, "", mapper.ToBlocklistEntryRes(entry))
+	context.JSON(res.Code, res)
+	return
+}
+
+// ListBlocklistEntries godoc
+// @Summary List active blocklist entries
+// @Tags admin
+// @Produce json
+// @Security AdminApiKey
+// @Success 200 {object} api_response.Response{data=[]res.BlocklistEntry}
+// @Failure 401 {object} api_response.Response
+// @Router /api/v1/admin/blocklist [get]
+func ListBlocklistEntries(context *gin.Context) {
+	entries := paymentBlocklist.List(gatewayClock.Now())
+	res := api_response.BuildResponse(http.StatusOK, "", mapper.ToBlocklistEntriesRes(entries))
+	context.JSON(res.Code, res)
+	return
+}
+
+// RemoveBlocklistEntry godoc
+// @Summary Remove a blocklist entry
+// @Tags admin
+// @Produce json
+// @Security AdminApiKey
+// @Param id path string true "Blocklist entry id"
+// @Success 200 {object} api_response.Response
+// @Failure 400 {object} api_response.Response
+// @Failure 401 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Failure 500 {object} api_response.Response
+// @Router /api/v1/admin/blocklist/{id} [delete]
+func RemoveBlocklistEntry(context *gin.Context) {
+	ID := context.Param("id")
+	if err := ids.Validate(ids.BLOCKLIST_ENTRY, ID); err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	removed, err := paymentBlocklist.Remove(ID, gatewayClock.Now())
+	if err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusInternalServerError, "Internal Server Error", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	if !removed {
+		errRes := api_response.BuildErrorResponse(http.StatusNotFound, "Not Found", "", nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	res := api_response.BuildResponse(http.StatusOK, "", nil)
+	context.JSON(res.Code, res)
+	return
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/mapper"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/models"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/customers"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ids"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DEFAULT_PAGE_SIZE = 20
+	MAX_PAGE_SIZE     = 100
+)
+
+var paymentCustomers = customers.New()
+
+// SetCustomers replaces the store of customers and their saved cards.
+func SetCustomers(c *customers.Store) {
+	paymentCustomers = c
+	updatePaymentService()
+}
+
+// CreateCustomer godoc
+// @Summary Create a customer
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param request body req.CreateCustomerReqModel true "Customer"
+// @Success 201 {object} api_response.Response{data=res.Customer}
+// @Failure 400 {object} api_response.Response
+// @Failure 500 {object} api_response.Response
+// @Router /api/v1/customers [post]
+func CreateCustomer(context *gin.Context) {
+	body := &req.CreateCustomerReqModel{}
+	if err := body.Validate(context); err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	customer, err := paymentCustomers.Create(mapper.ToCustomer(merchantIdFrom(context), body, gatewayClock.Now()))
+	if err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusInternalServerError, "Internal Server Error", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	res := api_response.BuildResponse(http.StatusCreated, "", mapper.ToCustomerRes(customer))
+	context.JSON(res.Code, res)
+	return
+}
+
+// GetCustomer godoc
+// @Summary Get a customer
+// @Tags customers
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param id path string true "Customer id"
+// @Success 200 {object} api_response.Response{data=res.Customer}
+// @Failure 400 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Router /api/v1/customers/{id} [get]
+func GetCustomer(context *gin.Context) {
+	ID := context.Param("id")
+	if err := ids.Validate(ids.CUSTOMER, ID); err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	customer, err := paymentCustomers.Get(ID, merchantIdFrom(context))
+	if err != nil {
+		errRes := buildCustomerErrorResponse(err)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	res := api_response.BuildResponse(http.StatusOK, "", mapper.ToCustomerRes(customer))
+	context.JSON(res.Code, res)
+	return
+}
+
+// AttachCard saves a card on the customer and returns its token.
+//
+// @Summary Save a card on a customer
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param id path string true "Customer id"
+// @Param request body req.AttachCardReqModel true "Card"
+// @Success 201 {object} api_response.Response{data=res.PaymentMethod}
+// @Failure 400 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Failure 500 {object} api_response.Response
+// @Router /api/v1/customers/{id}/payment_methods [post]
+func AttachCard(context *gin.Context) {
+	ID := context.Param("id")
+	if err := ids.Validate(ids.CUSTOMER, ID); err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	body := &req.AttachCardReqModel{}
+	if err := body.Validate(context); err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	if _, err := BuildExpiryDate(body.ExpirationMonth, body.ExpirationYear); err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+
+	cardToken, err := cardVault.Tokenize(body.CardNumber)
+	if err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusInternalServerError, "Internal Server Error", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	method, err := paymentCustomers.AttachCard(ID, merchantIdFrom(context), mapper.ToPaymentMethod(body, cardToken, gatewayClock.Now()))
+	if err != nil {
+		errRes := buildCustomerErrorResponse(err)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	res := api_response.BuildResponse(http.StatusCreated, "", mapper.ToPaymentMethodRes(method))
+	context.JSON(res.Code, res)
+	return
+}
+
+// ListCustomerPayments godoc
+// @Summary List a customer's payments
+// @Tags customers
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param id path string true "Customer id"
+// @Param page query int false "Page number" minimum(1) default(1)
+// @Param limit query int false "Payments per page" minimum(1) maximum(100) default(20)
+// @Success 200 {object} api_response.ResponseWithPagination{data=[]res.PaymentDetails}
+// @Failure 400 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Failure 500 {object} api_response.Response
+// @Router /api/v1/customers/{id}/payments [get]
+func ListCustomerPayments(context *gin.Context) {
+	ID := context.Param("id")
+	if err := ids.Validate(ids.CUSTOMER, ID); err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	merchantId := merchantIdFrom(context)
+	if _, err := paymentCustomers.Get(ID, merchantId); err != nil {
+		errRes := buildCustomerErrorResponse(err)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	page, limit, err := paginationFrom(context)
+	if err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+
+	payments := paymentService.ListPayments(services.PaymentFilter{MerchantId: merchantId, CustomerId: ID})
+	pageItems, pagination := paginate(payments, page, limit)
+	res := api_response.BuildResponseWithPagination(http.StatusOK, "", mapper.ToPaymentsRes(pageItems), pagination)
+	context.JSON(res.Code, res)
+	return
+}
+
+func buildCustomerErrorResponse(err error) api_response.Response {
+	switch {
+	case errors.Is(err, customers.ErrCustomerNotFound), errors.Is(err, customers.ErrPaymentMethodNotFound):
+		return api_response.BuildErrorResponse(http.StatusNotFound, "Not Found", err.Error(), nil)
+	default:
+		return api_response.BuildErrorResponse(http.StatusInternalServerError, "Internal Server Error", err.Error(), nil)
+	}
+}
+
+// paginationFrom reads the 1-based page and the limit query parameters.
+func paginationFrom(context *gin.Context) (int, int, error) {
+	page, err := strconv.Atoi(context.DefaultQuery("page", "1"))
+	if err != nil {
+		return 0, 0, errPageOutOfRange
+	}
+	limit, err := strconv.Atoi(context.DefaultQuery("limit", strconv.Itoa(DEFAULT_PAGE_SIZE)))
+	if err != nil {
+		return 0, 0, errLimitOutOfRange
+	}
+	return page, limit, checkPagination(page, limit)
+}
+
+var (
+	errPageOutOfRange  = errors.New("page must be a positive number")
+	errLimitOutOfRange = errors.New("limit must be between 1 and " + strconv.Itoa(MAX_PAGE_SIZE))
+)
+
+func checkPagination(page int, limit int) error {
+	if page < 1 {
+		return errPageOutOfRange
+	}
+	if limit < 1 || limit > MAX_PAGE_SIZE {
+		return errLimitOutOfRange
+	}
+	return nil
+}
+
+func paginate(payments []models.Payment, page int, limit int) ([]models.Payment, *api_response.PaginationResponse) {
+	total := len(payments)
+	totalPages := (total + limit - 1) / limit
+	// Pages past the last one are checked before multiplying, which
+	// overflows for very large pages.
+	start := total
+	if page-1 < totalPages {
+		start = (page - 1) * limit
+	}
+	end := start + limit
+	if end > total {
+		end = total
+	}
+	return payments[start:end], &api_response.PaginationResponse{
+		TotalPage:    totalPages,
+		ItemsPerPage: limit,
+		CurrentPage:  page,
+		TotalItems:   total,
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/mapper"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ids"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/mandates"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/services"
+	"github.com/gin-gonic/gin"
+)
+
+var paymentMandates = mandates.New()
+
+// SetMandates replaces the store of stored-credential mandates.
+func SetMandates(m *mandates.Store) {
+	paymentMandates = m
+	updatePaymentService()
+}
+
+// GetMandate godoc
+// @Summary Get a mandate
+// @Tags mandates
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param id path string true "Mandate id"
+// @Success 200 {object} api_response.Response{data=res.Mandate}
+// @Failure 400 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Router /api/v1/mandates/{id} [get]
+func GetMandate(context *gin.Context) {
+	ID := context.Param("id")
+	if err := ids.Validate(ids.MANDATE, ID); err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	mandate, ok := paymentMandates.Get(ID)
+	if !ok || mandate.MerchantId != merchantIdFrom(context) {
+		errRes := api_response.BuildErrorResponse(http.StatusNotFound, "Not Found", "", nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	res := api_response.BuildResponse(http.StatusOK, "", mapper.ToMandateRes(mandate))
+	context.JSON(res.Code, res)
+	return
+}
+
+// RevokeMandate godoc
+// @Summary Revoke a mandate
+// @Tags mandates
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param id path string true "Mandate id"
+// @Success 200 {object} api_response.Response{data=res.Mandate}
+// @Failure 400 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Failure 500 {object} api_response.Response
+// @Router /api/v1/mandates/{id} [delete]
+func RevokeMandate(context *gin.Context) {
+	ID := context.Param("id")
+	if err := ids.Validate(ids.MANDATE, ID); err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	if _, err := paymentMandates.Active(ID, merchantIdFrom(context)); errors.Is(err, mandates.ErrMandateNotFound) {
+		errRes := api_response.BuildErrorResponse(http.StatusNotFound, "Not Found", "", nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	mandate, err := paymentMandates.Revoke(ID, gatewayClock.Now())
+	if err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusInternalServerError, "Internal Server Error", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	res := api_response.BuildResponse(http.StatusOK, "", mapper.ToMandateRes(mandate))
+	context.JSON(res.Code, res)
+	return
+}
+
+// CreateMandatePayment charges a stored card without the cardholder being
+// present.
+//
+// @Summary Charge a mandate
+// @Tags mandates
+// @Accept json
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param id path string true "Mandate id"
+// @Param request body req.MandatePaymentReqModel true "Amount to charge"
+// @Param Idempotency-Key header string false "Key that makes retries of this request return the first response"
+// @Success 200 {object} api_response.Response{data=res.PaymentDetails}
+// @Failure 400 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Failure 409 {object} api_response.Response
+// @Failure 422 {object} api_response.Response
+// @Failure 500 {object} api_response.Response
+// @Failure 502 {object} api_response.Response
+// @Failure 504 {object} api_response.Response
+// @Router /api/v1/mandates/{id}/payments [post]
+func CreateMandatePayment(context *gin.Context) {
+	body := &req.MandatePaymentReqModel{}
+	if err := body.Validate(context); err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, enums.REJECTED, err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+
+	paymentModel, err := paymentService.ChargeMandate(services.MandatePaymentInput{
+		MandateId:  context.Param("id"),
+		MerchantId: merchantIdFrom(context),
+		Currency:   body.Currency,
+		Amount:     body.Amount,
+	})
+	if err != nil {
+		errRes := buildServiceErrorResponse(err)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	res := api_response.BuildResponse(http.StatusOK, "", mapper.ToPaymentDetailsRes(paymentModel))
+	context.JSON(res.Code, res)
+	return
+}
+
+func buildMandateErrorResponse(err error) api_response.Response {
+	switch {
+	case errors.Is(err, mandates.ErrMandateNotFound):
+		return api_response.BuildErrorResponse(http.StatusNotFound, "Not Found", err.Error(), nil)
+	case errors.Is(err, mandates.ErrMandateRevoked):
+		return api_response.BuildErrorResponse(http.StatusConflict, "Conflict", err.Error(), nil)
+	default:
+		return api_response.BuildErrorResponse(http.StatusInternalServerError, "Internal Server Error", err.Error(), nil)
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/models"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/etags"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+// The API versions a payment's representations are tagged with.
+const (
+	apiV1 = "v1"
+	apiV2 = "v2"
+)
+
+var (
+	errIfMatchRequired = errors.New("If-Match header with the payment's ETag is required")
+	errPaymentChanged  = errors.New("payment has changed since the given ETag")
+)
+
+// setPaymentETag tags the response with the payment's version as served by
+// apiVersion.
+func setPaymentETag(context *gin.Context, apiVersion string, paymentModel models.Payment) {
+	context.Header("ETag", etags.Strong(apiVersion, paymentModel.Version))
+}
+
+// notModified answers a GET whose If-None-Match lists the payment's current
+// ETag with 304.
+func notModified(context *gin.Context, apiVersion string, paymentModel models.Payment) bool {
+	setPaymentETag(context, apiVersion, paymentModel)
+	if !etags.MatchWeak(context.GetHeader("If-None-Match"), etags.Strong(apiVersion, paymentModel.Version)) {
+		return false
+	}
+	context.Status(http.StatusNotModified)
+	return true
+}
+
+// ifMatchVersion checks the If-Match header of an update against the
+// payment and returns the version the update must be applied at, or 0 when
+// any version will do.
+func ifMatchVersion(context *gin.Context, apiVersion string, required bool) (int, api_response.Response, bool) {
+	header := context.GetHeader("If-Match")
+	if header == "" {
+		if required {
+			return 0, api_response.BuildErrorResponse(http.StatusPreconditionRequired, "Precondition Required", errIfMatchRequired.Error(), nil), false
+		}
+		return 0, api_response.Response{}, true
+	}
+	if strings.TrimSpace(header) == "*" {
+		return 0, api_response.Response{}, true
+	}
+	paymentModel, err := paymentService.GetPayment(context.Param("id"), merchantIdFrom(context))
+	if err != nil {
+		return 0, buildServiceErrorResponse(err), false
+	}
+	if !etags.MatchStrong(header, etags.Strong(apiVersion, paymentModel.Version)) {
+		setPaymentETag(context, apiVersion, paymentModel)
+		return 0, api_response.BuildErrorResponse(http.StatusPreconditionFailed, "Precondition Failed", errPaymentChanged.Error(), nil), false
+	}
+	return paymentModel.Version, api_response.Response{}, true
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/mapper"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/middlewares"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/proto/paymentpb"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PaymentServiceServer serves the payments API over gRPC on the same
+// payment service as the REST handlers.
+type PaymentServiceServer struct {
+	paymentpb.UnimplementedPaymentServiceServer
+}
+
+func (server *PaymentServiceServer) CreatePayment(ctx context.Context, request *paymentpb.CreatePaymentRequest) (*paymentpb.Payment, error) {
+	paymentModel, err := paymentService.CreatePayment(services.CreatePaymentInput{
+		MerchantId:      merchantIdFromMetadata(ctx),
+		IP:              middlewares.PeerIP(ctx),
+		CardNumber:      request.CardNumber,
+		ExpirationMonth: int(request.ExpirationMonth),
+		ExpirationYear:  int(request.ExpirationYear),
+		Currency:        request.Currency,
+		Amount:          int(request.Amount),
+		CVV:             request.Cvv,
+		Email:           request.Email,
+		ThreeDS:         request.ThreeDs,
+		SetupMandate:    request.SetupMandate,
+		CustomerId:      request.CustomerId,
+		PaymentMethodId: request.PaymentMethodId,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return mapper.ToPaymentMessage(paymentModel), nil
+}
+
+func (server *PaymentServiceServer) GetPayment(ctx context.Context, request *paymentpb.GetPaymentRequest) (*paymentpb.Payment, error) {
+	paymentModel, err := paymentService.GetPayment(request.Id, merchantIdFromMetadata(ctx))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return mapper.ToPaymentMessage(paymentModel), nil
+}
+
+func (server *PaymentServiceServer) ListPayments(ctx context.Context, request *paymentpb.ListPaymentsRequest) (*paymentpb.ListPaymentsResponse, error) {
+	page, limit := int(request.Page), int(request.Limit)
+	if page == 0 {
+		page = 1
+	}
+	if limit == 0 {
+		limit = DEFAULT_PAGE_SIZE
+	}
+	if err := checkPagination(page, limit); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	payments := paymentService.ListPayments(services.PaymentFilter{
+		MerchantId: merchantIdFromMetadata(ctx),
+		Status:     request.Status,
+	})
+	pageItems, pagination := paginate(payments, page, limit)
+	return &paymentpb.ListPaymentsResponse{
+		Payments:   mapper.ToPaymentMessages(pageItems),
+		Pagination: mapper.ToPaginationMessage(pagination),
+	}, nil
+}
+
+func merchantIdFromMetadata(ctx context.Context) string {
+	return middlewares.MerchantIdFromContext(ctx)
+}
+
+// grpcError maps the kind of a payment service error to the status code
+// it is reported with, the counterpart of buildServiceErrorResponse.
+func grpcError(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, services.ErrRejected), errors.Is(err, services.ErrInvalid):
+		code = codes.InvalidArgument
+	case errors.Is(err, services.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, services.ErrConflict):
+		code = codes.FailedPrecondition
+	case errors.Is(err, services.ErrPreconditionFailed):
+		code = codes.Aborted
+	case errors.Is(err, services.ErrUnavailable):
+		code = codes.Unavailable
+	case errors.Is(err, services.ErrOutcomeUnknown):
+		code = codes.DeadlineExceeded
+	}
+	return status.Error(code, err.Error())
+}
@@ -5,92 +5,405 @@ import (
 	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
 	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
 	"github.com/cko-recruitment/payment-gateway-challenge-go/mapper"
-	"github.com/cko-recruitment/payment-gateway-challenge-go/models"
 	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
-	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/http_clients"
-	"github.com/cko-recruitment/payment-gateway-challenge-go/utils"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/bins"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/clock"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/eventlog"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/fx"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/risk"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/routing"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/vault"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/services"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/store"
 	"github.com/gin-gonic/gin"
+	"log"
 	"net/http"
-	"strconv"
 	"time"
 )
 
-var paymentMap = make(map[string]models.Payment)
+var paymentStore = newMemoryPaymentStore()
 
-func CreatePayment(context *gin.Context) {
-	body := &req.CreatePaymentReqModel{}
-	ID, uuidErr := utils.GenerateUUID()
-	if uuidErr != nil {
-		errRes := api_response.BuildErrorResponse(http.StatusInternalServerError, "Internal Server Error", uuidErr.Error(), nil)
-		context.JSON(errRes.Code, errRes)
-		return
+func newMemoryPaymentStore() *store.PaymentStore {
+	s := store.NewPaymentStore(eventlog.NewMemoryLog(), store.Options{})
+	s.Subscribe(func(event eventlog.Event) {
+		if err := merchantLedger.Apply(event); err != nil {
+			log.Printf("could not post event %d to the ledger: %v", event.Sequence, err)
+		}
+	})
+	s.SubscribeUpdates(PublishPaymentUpdate)
+	return s
+}
+
+// SetPaymentStore replaces the in-memory store used by the payment handlers.
+func SetPaymentStore(s *store.PaymentStore) {
+	paymentStore = s
+	updatePaymentService()
+}
+
+var riskEngine = risk.NewEngine(risk.Config{})
+
+// SetRiskEngine replaces the engine scoring payments before authorization.
+func SetRiskEngine(engine *risk.Engine) {
+	riskEngine = engine
+	updatePaymentService()
+}
+
+var acquirerRouter = routing.NewSingleAcquirerRouter()
+
+// SetAcquirerRouter replaces the router choosing the acquiring bank.
+func SetAcquirerRouter(router *routing.Router) {
+	acquirerRouter = router
+	updatePaymentService()
+}
+
+var fxProvider fx.Provider
+var settlementCurrency string
+
+// SetSettlement converts payments taken in other currencies into currency
+// at the provider's rates. An empty currency turns conversion off.
+func SetSettlement(currency string, provider fx.Provider) {
+	settlementCurrency = currency
+	fxProvider = provider
+	updatePaymentService()
+}
+
+var expiryLocation = time.UTC
+
+// SetExpiryLocation sets the timezone in which card expiry months end.
+func SetExpiryLocation(loc *time.Location) {
+	expiryLocation = loc
+	updatePaymentService()
+}
+
+var gatewayClock clock.Clock = clock.Real{}
+
+// SetClock replaces the clock the handlers and the payment service read the
+// time from.
+func SetClock(c clock.Clock) {
+	gatewayClock = c
+	updatePaymentService()
+}
+
+var cardVault = vault.New()
+
+// SetCardVault replaces the vault payments tokenize their card into.
+func SetCardVault(v *vault.Vault) {
+	cardVault = v
+	updatePaymentService()
+}
+
+var binLookup bins.Lookup
+
+// SetBinLookup sets where payments look up the issuer of their card. A nil
+// lookup leaves payments without issuer details.
+func SetBinLookup(lookup bins.Lookup) {
+	binLookup = lookup
+	updatePaymentService()
+}
+
+// BuildExpiryDate formats the expiry as MM/YYYY for the acquiring bank. The
+// card is accepted through the last day of its expiry month.
+func BuildExpiryDate(expiryMonth int, expiryYear int) (string, error) {
+	return paymentService.BuildExpiryDate(expiryMonth, expiryYear)
+}
+
+var paymentService = services.NewPaymentService(paymentDependencies())
+
+func paymentDependencies() services.Dependencies {
+	return services.Dependencies{
+		Store:              paymentStore,
+		Risk:               riskEngine,
+		Router:             acquirerRouter,
+		Blocklist:          paymentBlocklist,
+		Customers:          paymentCustomers,
+		Mandates:           paymentMandates,
+		DirectoryServer:    directoryServer,
+		SettlementCurrency: settlementCurrency,
+		FX:                 fxProvider,
+		ExpiryLocation:     expiryLocation,
+		Bins:               binLookup,
+		Vault:              cardVault,
+		Clock:              gatewayClock,
 	}
+}
 
-	paymentStatus := ""
-	message := ""
+// updatePaymentService hands a replaced dependency to the payment service.
+func updatePaymentService() {
+	paymentService.SetDependencies(paymentDependencies())
+}
 
+// CreatePayment godoc
+// @Summary Create a payment
+// @Description Authorizes a payment with the acquiring bank. Blocked, high risk and declined payments are returned with their status; a payment requiring 3-D Secure is returned as requires_action with a challenge URL.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param request body req.CreatePaymentReqModel true "Card details, or a customer's saved payment method"
+// @Param X-Api-Key header string false "Key the payment creation rate limit is counted against"
+// @Param Idempotency-Key header string false "Key that makes retries of this request return the first response"
+// @Success 200 {object} api_response.Response{data=res.PaymentDetails}
+// @Header 200 {string} ETag "Version of the payment, for If-None-Match and If-Match"
+// @Failure 400 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Failure 413 {object} api_response.Response
+// @Failure 429 {object} api_response.Response
+// @Failure 422 {object} api_response.Response
+// @Failure 500 {object} api_response.Response
+// @Failure 502 {object} api_response.Response
+// @Failure 504 {object} api_response.Response
+// @Header 200 {string} Deprecation "When v1 was deprecated, as @ and Unix seconds"
+// @Header 200 {string} Sunset "When v1 stops being served"
+// @Deprecated
+// @Router /api/v1/payments [post]
+func CreatePayment(context *gin.Context) {
+	body := &req.CreatePaymentReqModel{}
 	err := body.Validate(context)
 	if err != nil {
 		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, enums.REJECTED, err.Error(), nil)
 		context.JSON(errRes.Code, errRes)
 		return
-	} else {
-		expiryDate, expiryDateErr := BuildExpiryDate(body.ExpirationMonth, body.ExpirationYear)
-		if expiryDateErr != nil {
-			errRes := api_response.BuildErrorResponse(http.StatusBadRequest, enums.REJECTED, expiryDateErr.Error(), nil)
-			context.JSON(errRes.Code, errRes)
-			return
-		}
-		status, authError := http_clients.AuthorizePayment(body.CardNumber, expiryDate, body.Currency, body.Amount, body.CVV)
-		if authError != nil {
-			errRes := api_response.BuildErrorResponse(http.StatusInternalServerError, "Internal Server Error", authError.Error(), nil)
-			context.JSON(errRes.Code, errRes)
-			return
-		} else {
-			paymentStatus = status
-		}
 	}
 
-	paymentModel := mapper.ToPaymentModel(ID, paymentStatus, body.CardNumber, body.ExpirationMonth, body.ExpirationYear, body.Currency, body.Amount)
-	paymentMap[paymentModel.Id] = paymentModel
+	paymentModel, err := paymentService.CreatePayment(services.CreatePaymentInput{
+		MerchantId:      merchantIdFrom(context),
+		IP:              context.ClientIP(),
+		CardNumber:      body.CardNumber,
+		ExpirationMonth: body.ExpirationMonth,
+		ExpirationYear:  body.ExpirationYear,
+		Currency:        body.Currency,
+		Amount:          body.Amount,
+		CVV:             body.CVV,
+		Email:           body.Email,
+		ThreeDS:         body.ThreeDS,
+		SetupMandate:    body.SetupMandate,
+		CustomerId:      body.CustomerId,
+		PaymentMethodId: body.PaymentMethodId,
+	})
+	if err != nil {
+		errRes := buildServiceErrorResponse(err)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	setPaymentETag(context, apiV1, paymentModel)
 
 	paymentDetailRes := mapper.ToPaymentDetailsRes(paymentModel)
-	res := api_response.BuildResponse(http.StatusOK, message, paymentDetailRes)
+	res := api_response.BuildResponse(http.StatusOK, "", paymentDetailRes)
+	context.JSON(res.Code, res)
+	return
+}
+
+// ListPayments godoc
+// @Summary List the merchant's payments
+// @Tags payments
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param status query string false "Only payments with this status"
+// @Param page query int false "Page number" minimum(1) default(1)
+// @Param limit query int false "Payments per page" minimum(1) maximum(100) default(20)
+// @Success 200 {object} api_response.ResponseWithPagination{data=[]res.PaymentDetails}
+// @Failure 400 {object} api_response.Response
+// @Header 200 {string} Deprecation "When v1 was deprecated, as @ and Unix seconds"
+// @Header 200 {string} Sunset "When v1 stops being served"
+// @Deprecated
+// @Router /api/v1/payments [get]
+func ListPayments(context *gin.Context) {
+	page, limit, err := paginationFrom(context)
+	if err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	payments := paymentService.ListPayments(services.PaymentFilter{
+		MerchantId: merchantIdFrom(context),
+		Status:     context.Query("status"),
+	})
+	pageItems, pagination := paginate(payments, page, limit)
+	res := api_response.BuildResponseWithPagination(http.StatusOK, "", mapper.ToPaymentsRes(pageItems), pagination)
 	context.JSON(res.Code, res)
 	return
 }
 
+// GetPaymentById godoc
+// @Summary Get a payment
+// @Tags payments
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param id path string true "Payment id"
+// @Param If-None-Match header string false "ETag from an earlier response; an unchanged payment is answered with 304"
+// @Success 200 {object} api_response.Response{data=res.PaymentDetails}
+// @Header 200 {string} ETag "Version of the payment, for If-None-Match and If-Match"
+// @Success 304 "Payment unchanged"
+// @Failure 400 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Header 200 {string} Deprecation "When v1 was deprecated, as @ and Unix seconds"
+// @Header 200 {string} Sunset "When v1 stops being served"
+// @Deprecated
+// @Router /api/v1/payments/{id} [get]
 func GetPaymentById(context *gin.Context) {
-	ID := context.Param("id")
-	paymentModel, ok := paymentMap[ID]
-	if !ok {
-		errRes := api_response.BuildErrorResponse(http.StatusNotFound, "Not Found", "", nil)
+	paymentModel, err := paymentService.GetPayment(context.Param("id"), merchantIdFrom(context))
+	if err != nil {
+		errRes := buildServiceErrorResponse(err)
 		context.JSON(errRes.Code, errRes)
 		return
 	}
+	if notModified(context, apiV1, paymentModel) {
+		return
+	}
 	paymentDetailRes := mapper.ToPaymentDetailsRes(paymentModel)
 	res := api_response.BuildResponse(http.StatusOK, "", paymentDetailRes)
 	context.JSON(res.Code, res)
 	return
 }
 
-func BuildExpiryDate(expiryMonth int, expiryYear int) (string, error) {
-	currentYear, currentMonth, _ := time.Now().Date()
-	currentMonthInt := int(currentMonth)
+// GetPaymentEvents godoc
+// @Summary List a payment's status transitions
+// @Tags payments
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param id path string true "Payment id"
+// @Success 200 {object} api_response.Response{data=[]res.PaymentEvent}
+// @Failure 400 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Header 200 {string} Deprecation "When v1 was deprecated, as @ and Unix seconds"
+// @Header 200 {string} Sunset "When v1 stops being served"
+// @Deprecated
+// @Router /api/v1/payments/{id}/events [get]
+func GetPaymentEvents(context *gin.Context) {
+	paymentModel, err := paymentService.GetPayment(context.Param("id"), merchantIdFrom(context))
+	if err != nil {
+		errRes := buildServiceErrorResponse(err)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	res := api_response.BuildResponse(http.StatusOK, "", mapper.ToPaymentEventsRes(paymentModel))
+	context.JSON(res.Code, res)
+	return
+}
 
-	if expiryYear < currentYear {
-		return "", errors.New("expiry date must be in future date")
+// CapturePayment godoc
+// @Summary Capture an authorized payment
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param id path string true "Payment id"
+// @Param request body req.CapturePaymentReqModel true "Amount to capture"
+// @Param Idempotency-Key header string false "Key that makes retries of this request return the first response"
+// @Param If-Match header string false "ETag of the payment the capture was decided on, or *. Required"
+// @Success 200 {object} api_response.Response{data=res.PaymentDetails}
+// @Header 200 {string} ETag "Version of the payment, for If-None-Match and If-Match"
+// @Failure 400 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Failure 409 {object} api_response.Response
+// @Failure 412 {object} api_response.Response
+// @Failure 422 {object} api_response.Response
+// @Failure 428 {object} api_response.Response
+// @Failure 500 {object} api_response.Response
+// @Header 200 {string} Deprecation "When v1 was deprecated, as @ and Unix seconds"
+// @Header 200 {string} Sunset "When v1 stops being served"
+// @Deprecated
+// @Router /api/v1/payments/{id}/captures [post]
+func CapturePayment(context *gin.Context) {
+	body := &req.CapturePaymentReqModel{}
+	if err := body.Validate(context); err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
 	}
-	if expiryYear == currentYear {
-		if expiryMonth < currentMonthInt {
-			return "", errors.New("expiry date must be in future date")
-		}
+
+	version, errRes, ok := ifMatchVersion(context, apiV1, true)
+	if !ok {
+		context.JSON(errRes.Code, errRes)
+		return
 	}
 
-	expiryMonthInString := strconv.Itoa(expiryMonth)
-	if expiryMonth < 10 {
-		expiryMonthInString = "0" + strconv.Itoa(expiryMonth)
+	paymentModel, err := paymentService.Capture(context.Param("id"), merchantIdFrom(context), body.Amount, version)
+	if err != nil {
+		errRes := buildServiceErrorResponse(err)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	setPaymentETag(context, apiV1, paymentModel)
+	res := api_response.BuildResponse(http.StatusOK, "", mapper.ToPaymentDetailsRes(paymentModel))
+	context.JSON(res.Code, res)
+	return
+}
+
+// RefundPayment godoc
+// @Summary Refund a captured payment
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param id path string true "Payment id"
+// @Param request body req.RefundPaymentReqModel true "Amount to refund"
+// @Param Idempotency-Key header string false "Key that makes retries of this request return the first response"
+// @Param If-Match header string false "ETag of the payment the refund was decided on, or *. Required"
+// @Success 200 {object} api_response.Response{data=res.PaymentDetails}
+// @Header 200 {string} ETag "Version of the payment, for If-None-Match and If-Match"
+// @Failure 400 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Failure 409 {object} api_response.Response
+// @Failure 412 {object} api_response.Response
+// @Failure 422 {object} api_response.Response
+// @Failure 428 {object} api_response.Response
+// @Failure 500 {object} api_response.Response
+// @Header 200 {string} Deprecation "When v1 was deprecated, as @ and Unix seconds"
+// @Header 200 {string} Sunset "When v1 stops being served"
+// @Deprecated
+// @Router /api/v1/payments/{id}/refunds [post]
+func RefundPayment(context *gin.Context) {
+	body := &req.RefundPaymentReqModel{}
+	if err := body.Validate(context); err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+
+	version, errRes, ok := ifMatchVersion(context, apiV1, true)
+	if !ok {
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+
+	paymentModel, err := paymentService.Refund(context.Param("id"), merchantIdFrom(context), body.Amount, version)
+	if err != nil {
+		errRes := buildServiceErrorResponse(err)
+		context.JSON(errRes.Code, errRes)
+		return
 	}
+	setPaymentETag(context, apiV1, paymentModel)
+	res := api_response.BuildResponse(http.StatusOK, "", mapper.ToPaymentDetailsRes(paymentModel))
+	context.JSON(res.Code, res)
+	return
+}
 
-	return expiryMonthInString + "/" + strconv.Itoa(expiryYear), nil
+// buildServiceErrorResponse maps the kind of a payment service error to
+// the HTTP status it is reported with.
+func buildServiceErrorResponse(err error) api_response.Response {
+	switch {
+	case errors.Is(err, services.ErrRejected):
+		return api_response.BuildErrorResponse(http.StatusBadRequest, enums.REJECTED, err.Error(), nil)
+	case errors.Is(err, services.ErrInvalid):
+		return api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+	case errors.Is(err, services.ErrNotFound):
+		return api_response.BuildErrorResponse(http.StatusNotFound, "Not Found", err.Error(), nil)
+	case errors.Is(err, services.ErrConflict):
+		return api_response.BuildErrorResponse(http.StatusConflict, "Conflict", err.Error(), nil)
+	case errors.Is(err, services.ErrPreconditionFailed):
+		return api_response.BuildErrorResponse(http.StatusPreconditionFailed, "Precondition Failed", err.Error(), nil)
+	case errors.Is(err, services.ErrUnavailable):
+		return api_response.BuildErrorResponse(http.StatusBadGateway, "Bad Gateway", err.Error(), nil)
+	case errors.Is(err, services.ErrOutcomeUnknown):
+		return api_response.BuildErrorResponse(http.StatusGatewayTimeout, "Gateway Timeout", err.Error(), nil)
+	default:
+		return api_response.BuildErrorResponse(http.StatusInternalServerError, "Internal Server Error", err.Error(), nil)
+	}
 }
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/mapper"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/broadcast"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/store"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	PAYMENT_STATUS_EVENT = "payment.status"
+	// STREAM_RESET_EVENT tells a resuming client that updates it missed are
+	// no longer buffered, so it should fetch the payments it follows again.
+	STREAM_RESET_EVENT        = "stream.reset"
+	LAST_EVENT_ID_HEADER      = "Last-Event-ID"
+	STREAM_HEARTBEAT_INTERVAL = 15 * time.Second
+	DEFAULT_STREAM_BUFFER     = 1000
+)
+
+var errInvalidLastEventId = errors.New("Last-Event-ID must be a non-negative integer")
+
+var paymentStreams = broadcast.NewBroker(DEFAULT_STREAM_BUFFER, 0)
+
+// SetPaymentStreams replaces the broker the payment streams are served
+// from.
+func SetPaymentStreams(broker *broadcast.Broker) {
+	paymentStreams = broker
+}
+
+// PublishPaymentUpdate sends the status transition of an update, if any, to
+// the streams following the payment. The event's sequence is its id.
+func PublishPaymentUpdate(update store.Update) {
+	if update.Transition == nil {
+		return
+	}
+	paymentStreams.Publish(broadcast.Message{
+		Id:         update.Event.Sequence,
+		MerchantId: update.Payment.MerchantId,
+		PaymentId:  update.Payment.Id,
+		Event:      PAYMENT_STATUS_EVENT,
+		Data:       mapper.ToPaymentStatusUpdateRes(update.Payment, *update.Transition),
+	})
+}
+
+// StreamPayment godoc
+// @Summary Stream a payment's status transitions
+// @Description Server-Sent Events named payment.status, with a res.PaymentStatusUpdate as data. Transitions still buffered are sent first; reconnecting with Last-Event-ID resumes after that event, and a stream.reset event is sent when some of the missed ones are no longer buffered.
+// @Tags payments
+// @Produce json,text/event-stream
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param id path string true "Payment id"
+// @Param Last-Event-ID header string false "Id of the last event received"
+// @Success 200 {object} res.PaymentStatusUpdate
+// @Failure 400 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Router /api/v1/payments/{id}/stream [get]
+func StreamPayment(context *gin.Context) {
+	merchantId := merchantIdFrom(context)
+	paymentModel, err := paymentService.GetPayment(context.Param("id"), merchantId)
+	if err != nil {
+		errRes := buildServiceErrorResponse(err)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	streamPaymentUpdates(context, 0, func(message broadcast.Message) bool {
+		return message.MerchantId == merchantId && message.PaymentId == paymentModel.Id
+	})
+}
+
+// StreamMerchantEvents godoc
+// @Summary Stream the status transitions of all the merchant's payments
+// @Description Server-Sent Events named payment.status, with a res.PaymentStatusUpdate as data, starting from the time of the request. Reconnecting with Last-Event-ID resumes after that event, and a stream.reset event is sent when some of the missed ones are no longer buffered.
+// @Tags payments
+// @Produce json,text/event-stream
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param Last-Event-ID header string false "Id of the last event received"
+// @Success 200 {object} res.PaymentStatusUpdate
+// @Failure 400 {object} api_response.Response
+// @Router /api/v1/events/stream [get]
+func StreamMerchantEvents(context *gin.Context) {
+	merchantId := merchantIdFrom(context)
+	streamPaymentUpdates(context, paymentStreams.LastId(), func(message broadcast.Message) bool {
+		return message.MerchantId == merchantId
+	})
+}
+
+// streamPaymentUpdates sends the matching updates after Last-Event-ID, or
+// after from when it is not sent, until the client goes away or the broker
+// is closed.
+func streamPaymentUpdates(context *gin.Context, from int64, match func(broadcast.Message) bool) {
+	lastId := from
+	resuming := context.GetHeader(LAST_EVENT_ID_HEADER) != ""
+	if resuming {
+		parsed, err := strconv.ParseInt(context.GetHeader(LAST_EVENT_ID_HEADER), 10, 64)
+		if err != nil || parsed < 0 {
+			errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", errInvalidLastEventId.Error(), nil)
+			context.JSON(errRes.Code, errRes)
+			return
+		}
+		lastId = parsed
+	}
+
+	broker := paymentStreams
+	subscription, backlog, complete := broker.Subscribe(lastId, match)
+	defer broker.Unsubscribe(subscription)
+
+	context.Header("Content-Type", "text/event-stream")
+	context.Header("Cache-Control", "no-cache")
+	context.Header("X-Accel-Buffering", "no")
+	context.Status(http.StatusOK)
+	context.Writer.WriteString("retry: 3000\n\n")
+	if resuming && !complete {
+		context.Render(-1, sse.Event{Event: STREAM_RESET_EVENT, Data: "some updates since Last-Event-ID are no longer available"})
+	}
+	for _, message := range backlog {
+		renderStreamMessage(context, message)
+	}
+	context.Writer.Flush()
+
+	heartbeat := time.NewTicker(STREAM_HEARTBEAT_INTERVAL)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case message, ok := <-subscription.Messages():
+			if !ok {
+				return
+			}
+			renderStreamMessage(context, message)
+		case <-heartbeat.C:
+			context.Writer.WriteString(": heartbeat\n\n")
+		case <-context.Request.Context().Done():
+			return
+		}
+		context.Writer.Flush()
+	}
+}
+
+func renderStreamMessage(context *gin.Context, message broadcast.Message) {
+	context.Render(-1, sse.Event{
+		Id:    strconv.FormatInt(message.Id, 10),
+		Event: message.Event,
+		Data:  message.Data,
+	})
+}
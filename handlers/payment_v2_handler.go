@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/mapper"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/services"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+var errCurrencyMismatch = errors.New("amount currency does not match the payment currency")
+
+// CreatePaymentV2 godoc
+// @Summary Create a payment
+// @Description Authorizes a payment with the acquiring bank. Blocked, high risk and declined payments are returned with their status; a payment requiring 3-D Secure is returned as requires_action with a challenge URL.
+// @Tags payments v2
+// @Accept json
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param request body req.CreatePaymentV2ReqModel true "Card details, or a customer's saved payment method"
+// @Param X-Api-Key header string false "Key the payment creation rate limit is counted against"
+// @Param Idempotency-Key header string false "Key that makes retries of this request return the first response"
+// @Success 200 {object} api_response.Response{data=res.PaymentDetailsV2}
+// @Header 200 {string} ETag "Version of the payment, for If-None-Match and If-Match"
+// @Failure 400 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Failure 413 {object} api_response.Response
+// @Failure 429 {object} api_response.Response
+// @Failure 422 {object} api_response.Response
+// @Failure 500 {object} api_response.Response
+// @Failure 502 {object} api_response.Response
+// @Failure 504 {object} api_response.Response
+// @Router /api/v2/payments [post]
+func CreatePaymentV2(context *gin.Context) {
+	body := &req.CreatePaymentV2ReqModel{}
+	err := body.Validate(context)
+	if err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, enums.REJECTED, err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+
+	input := services.CreatePaymentInput{
+		MerchantId:      merchantIdFrom(context),
+		IP:              context.ClientIP(),
+		Currency:        body.Amount.Currency,
+		Amount:          body.Amount.Value,
+		CVV:             body.CVV,
+		Email:           body.Email,
+		ThreeDS:         body.ThreeDS,
+		SetupMandate:    body.SetupMandate,
+		CustomerId:      body.CustomerId,
+		PaymentMethodId: body.PaymentMethodId,
+	}
+	if body.Card != nil {
+		input.CardNumber = body.Card.Number
+		input.ExpirationMonth = body.Card.ExpiryMonth
+		input.ExpirationYear = body.Card.ExpiryYear
+	}
+	paymentModel, err := paymentService.CreatePayment(input)
+	if err != nil {
+		errRes := buildServiceErrorResponse(err)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	setPaymentETag(context, apiV2, paymentModel)
+
+	res := api_response.BuildResponse(http.StatusOK, "", mapper.ToPaymentDetailsV2Res(paymentModel))
+	context.JSON(res.Code, res)
+	return
+}
+
+// ListPaymentsV2 godoc
+// @Summary List the merchant's payments
+// @Tags payments v2
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param status query string false "Only payments with this status"
+// @Param page query int false "Page number" minimum(1) default(1)
+// @Param limit query int false "Payments per page" minimum(1) maximum(100) default(20)
+// @Success 200 {object} api_response.ResponseWithPagination{data=[]res.PaymentDetailsV2}
+// @Failure 400 {object} api_response.Response
+// @Router /api/v2/payments [get]
+func ListPaymentsV2(context *gin.Context) {
+	page, limit, err := paginationFrom(context)
+	if err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	payments := paymentService.ListPayments(services.PaymentFilter{
+		MerchantId: merchantIdFrom(context),
+		Status:     context.Query("status"),
+	})
+	pageItems, pagination := paginate(payments, page, limit)
+	res := api_response.BuildResponseWithPagination(http.StatusOK, "", mapper.ToPaymentsV2Res(pageItems), pagination)
+	context.JSON(res.Code, res)
+	return
+}
+
+// GetPaymentV2 godoc
+// @Summary Get a payment
+// @Tags payments v2
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param id path string true "Payment id"
+// @Param If-None-Match header string false "ETag from an earlier response; an unchanged payment is answered with 304"
+// @Success 200 {object} api_response.Response{data=res.PaymentDetailsV2}
+// @Header 200 {string} ETag "Version of the payment, for If-None-Match and If-Match"
+// @Success 304 "Payment unchanged"
+// @Failure 400 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Router /api/v2/payments/{id} [get]
+func GetPaymentV2(context *gin.Context) {
+	paymentModel, err := paymentService.GetPayment(context.Param("id"), merchantIdFrom(context))
+	if err != nil {
+		errRes := buildServiceErrorResponse(err)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	if notModified(context, apiV2, paymentModel) {
+		return
+	}
+	res := api_response.BuildResponse(http.StatusOK, "", mapper.ToPaymentDetailsV2Res(paymentModel))
+	context.JSON(res.Code, res)
+	return
+}
+
+// GetPaymentEventsV2 godoc
+// @Summary List a payment's status transitions
+// @Tags payments v2
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param id path string true "Payment id"
+// @Success 200 {object} api_response.Response{data=[]res.PaymentEvent}
+// @Failure 400 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Router /api/v2/payments/{id}/events [get]
+func GetPaymentEventsV2(context *gin.Context) {
+	GetPaymentEvents(context)
+}
+
+// CapturePaymentV2 godoc
+// @Summary Capture an authorized payment
+// @Tags payments v2
+// @Accept json
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param id path string true "Payment id"
+// @Param request body req.CapturePaymentV2ReqModel true "Amount to capture, in the payment's currency"
+// @Param Idempotency-Key header string false "Key that makes retries of this request return the first response"
+// @Param If-Match header string false "ETag of the payment the capture was decided on, or *. Required"
+// @Success 200 {object} api_response.Response{data=res.PaymentDetailsV2}
+// @Header 200 {string} ETag "Version of the payment, for If-None-Match and If-Match"
+// @Failure 400 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Failure 409 {object} api_response.Response
+// @Failure 412 {object} api_response.Response
+// @Failure 422 {object} api_response.Response
+// @Failure 428 {object} api_response.Response
+// @Failure 500 {object} api_response.Response
+// @Router /api/v2/payments/{id}/captures [post]
+func CapturePaymentV2(context *gin.Context) {
+	body := &req.CapturePaymentV2ReqModel{}
+	if err := body.Validate(context); err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	if errRes, ok := checkPaymentCurrency(context, body.Amount.Currency); !ok {
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+
+	version, errRes, ok := ifMatchVersion(context, apiV2, true)
+	if !ok {
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+
+	paymentModel, err := paymentService.Capture(context.Param("id"), merchantIdFrom(context), body.Amount.Value, version)
+	if err != nil {
+		errRes := buildServiceErrorResponse(err)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	setPaymentETag(context, apiV2, paymentModel)
+	res := api_response.BuildResponse(http.StatusOK, "", mapper.ToPaymentDetailsV2Res(paymentModel))
+	context.JSON(res.Code, res)
+	return
+}
+
+// RefundPaymentV2 godoc
+// @Summary Refund a captured payment
+// @Tags payments v2
+// @Accept json
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param id path string true "Payment id"
+// @Param request body req.RefundPaymentV2ReqModel true "Amount to refund, in the payment's currency"
+// @Param Idempotency-Key header string false "Key that makes retries of this request return the first response"
+// @Param If-Match header string false "ETag of the payment the refund was decided on, or *. Required"
+// @Success 200 {object} api_response.Response{data=res.PaymentDetailsV2}
+// @Header 200 {string} ETag "Version of the payment, for If-None-Match and If-Match"
+// @Failure 400 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Failure 409 {object} api_response.Response
+// @Failure 412 {object} api_response.Response
+// @Failure 422 {object} api_response.Response
+// @Failure 428 {object} api_response.Response
+// @Failure 500 {object} api_response.Response
+// @Router /api/v2/payments/{id}/refunds [post]
+func RefundPaymentV2(context *gin.Context) {
+	body := &req.RefundPaymentV2ReqModel{}
+	if err := body.Validate(context); err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	if errRes, ok := checkPaymentCurrency(context, body.Amount.Currency); !ok {
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+
+	version, errRes, ok := ifMatchVersion(context, apiV2, true)
+	if !ok {
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+
+	paymentModel, err := paymentService.Refund(context.Param("id"), merchantIdFrom(context), body.Amount.Value, version)
+	if err != nil {
+		errRes := buildServiceErrorResponse(err)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	setPaymentETag(context, apiV2, paymentModel)
+	res := api_response.BuildResponse(http.StatusOK, "", mapper.ToPaymentDetailsV2Res(paymentModel))
+	context.JSON(res.Code, res)
+	return
+}
+
+// checkPaymentCurrency makes sure a v2 amount is in the currency the
+// payment was taken in, as the service only deals in minor units.
+func checkPaymentCurrency(context *gin.Context, currency string) (api_response.Response, bool) {
+	paymentModel, err := paymentService.GetPayment(context.Param("id"), merchantIdFrom(context))
+	if err != nil {
+		return buildServiceErrorResponse(err), false
+	}
+	if paymentModel.CurrencyCode != currency {
+		return api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", errCurrencyMismatch.Error(), nil), false
+	}
+	return api_response.Response{}, true
+}
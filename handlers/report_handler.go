@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/mapper"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/models"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ids"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/reports"
+	"github.com/gin-gonic/gin"
+)
+
+var settlementReports = reports.NewStore("")
+
+// reportLocation is where settlement days start and end.
+var reportLocation = time.UTC
+
+// SetReports replaces the store of settlement reports and the timezone of
+// their days.
+func SetReports(store *reports.Store, location *time.Location) {
+	settlementReports = store
+	reportLocation = location
+}
+
+// ListReports godoc
+// @Summary List settlement reports
+// @Tags reports
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Success 200 {object} api_response.Response{data=[]res.Report}
+// @Router /api/v1/reports [get]
+func ListReports(context *gin.Context) {
+	res := api_response.BuildResponse(http.StatusOK, "", mapper.ToReportsRes(settlementReports.List(merchantIdFrom(context))))
+	context.JSON(res.Code, res)
+	return
+}
+
+// GetReport returns a settlement report as JSON, or as a CSV file when
+// format=csv.
+//
+// @Summary Get a settlement report
+// @Tags reports
+// @Produce json,text/csv
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param id path string true "Report id"
+// @Param format query string false "Response format" Enums(json, csv) default(json)
+// @Success 200 {object} api_response.Response{data=res.Report}
+// @Failure 400 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Router /api/v1/reports/{id} [get]
+func GetReport(context *gin.Context) {
+	ID := context.Param("id")
+	if err := ids.Validate(ids.REPORT, ID); err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	report, ok := settlementReports.Get(ID)
+	if !ok || report.MerchantId != merchantIdFrom(context) {
+		errRes := api_response.BuildErrorResponse(http.StatusNotFound, "Not Found", "", nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+
+	switch context.DefaultQuery("format", "json") {
+	case "json":
+		res := api_response.BuildResponse(http.StatusOK, "", mapper.ToReportRes(report))
+		context.JSON(res.Code, res)
+	case "csv":
+		context.Header("Content-Disposition", `attachment; filename="settlement-`+report.Date+"-"+report.Id+`.csv"`)
+		context.Status(http.StatusOK)
+		context.Header("Content-Type", "text/csv")
+		if err := reports.WriteCSV(context.Writer, report); err != nil {
+			context.Error(err)
+		}
+	default:
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", "format must be json or csv", nil)
+		context.JSON(errRes.Code, errRes)
+	}
+	return
+}
+
+// ReconcileSettlement matches an acquirer settlement CSV against stored
+// payments. With date=YYYY-MM-DD, payments captured that day but absent
+// from the file are flagged too.
+//
+// @Summary Reconcile an acquirer settlement file
+// @Tags admin
+// @Accept text/csv
+// @Produce json
+// @Security AdminApiKey
+// @Param date query string false "Settlement day, formatted as YYYY-MM-DD"
+// @Param file body string true "CSV with the header payment_id,type,currency,amount"
+// @Success 200 {object} api_response.Response{data=res.Reconciliation}
+// @Failure 400 {object} api_response.Response
+// @Failure 401 {object} api_response.Response
+// @Failure 500 {object} api_response.Response
+// @Router /api/v1/admin/reconciliations [post]
+func ReconcileSettlement(context *gin.Context) {
+	var expected []models.Payment
+	if date := context.Query("date"); date != "" {
+		day, err := time.ParseInLocation(reports.DATE_LAYOUT, date, reportLocation)
+		if err != nil {
+			errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", "date must be formatted as YYYY-MM-DD", nil)
+			context.JSON(errRes.Code, errRes)
+			return
+		}
+		expected = paymentStore.List(func(payment models.Payment) bool {
+			return capturedBetween(payment, day, day.AddDate(0, 0, 1))
+		})
+	}
+
+	rows, err := reports.ParseSettlementFile(context.Request.Body)
+	if err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		if !errors.Is(err, reports.ErrInvalidSettlementFile) {
+			errRes = api_response.BuildErrorResponse(http.StatusInternalServerError, "Internal Server Error", err.Error(), nil)
+		}
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	reconciliation := reports.Reconcile(rows, paymentStore.Get, expected)
+	res := api_response.BuildResponse(http.StatusOK, "", mapper.ToReconciliationRes(reconciliation))
+	context.JSON(res.Code, res)
+	return
+}
+
+func capturedBetween(payment models.Payment, start time.Time, end time.Time) bool {
+	for _, transition := range payment.StatusHistory {
+		if transition.To == enums.CAPTURED {
+			return !transition.Timestamp.Before(start) && transition.Timestamp.Before(end)
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/mapper"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ids"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/subscriptions"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/services"
+	"github.com/gin-gonic/gin"
+)
+
+var paymentSubscriptions = subscriptions.NewStore()
+
+// SetSubscriptions replaces the store of subscription plans.
+func SetSubscriptions(s *subscriptions.Store) {
+	paymentSubscriptions = s
+}
+
+// ChargeSubscription is the scheduler's charge function: it creates a
+// merchant-initiated payment on the subscription's mandate.
+func ChargeSubscription(subscription subscriptions.Subscription) (string, bool, error) {
+	paymentModel, err := paymentService.ChargeMandate(services.MandatePaymentInput{
+		MandateId:  subscription.MandateId,
+		MerchantId: subscription.MerchantId,
+		Currency:   subscription.Currency,
+		Amount:     subscription.Amount,
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return paymentModel.Id, paymentModel.Status == enums.AUTHORIZED, nil
+}
+
+// CreateSubscription godoc
+// @Summary Create a subscription
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param request body req.CreateSubscriptionReqModel true "Subscription"
+// @Success 201 {object} api_response.Response{data=res.Subscription}
+// @Failure 400 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Failure 409 {object} api_response.Response
+// @Failure 500 {object} api_response.Response
+// @Router /api/v1/subscriptions [post]
+func CreateSubscription(context *gin.Context) {
+	body := &req.CreateSubscriptionReqModel{}
+	if err := body.Validate(context); err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	merchantId := merchantIdFrom(context)
+	if _, err := paymentMandates.Active(body.MandateId, merchantId); err != nil {
+		errRes := buildMandateErrorResponse(err)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+
+	subscription, err := paymentSubscriptions.Create(mapper.ToSubscription(merchantId, body, gatewayClock.Now()))
+	if err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusInternalServerError, "Internal Server Error", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	res := api_response.BuildResponse(http.StatusCreated, "", mapper.ToSubscriptionRes(subscription))
+	context.JSON(res.Code, res)
+	return
+}
+
+// GetSubscription godoc
+// @Summary Get a subscription
+// @Tags subscriptions
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param id path string true "Subscription id"
+// @Success 200 {object} api_response.Response{data=res.Subscription}
+// @Failure 400 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Router /api/v1/subscriptions/{id} [get]
+func GetSubscription(context *gin.Context) {
+	ID := context.Param("id")
+	if err := ids.Validate(ids.SUBSCRIPTION, ID); err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	subscription, ok := paymentSubscriptions.Get(ID)
+	if !ok || subscription.MerchantId != merchantIdFrom(context) {
+		errRes := api_response.BuildErrorResponse(http.StatusNotFound, "Not Found", "", nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	res := api_response.BuildResponse(http.StatusOK, "", mapper.ToSubscriptionRes(subscription))
+	context.JSON(res.Code, res)
+	return
+}
+
+// CancelSubscription godoc
+// @Summary Cancel a subscription
+// @Tags subscriptions
+// @Produce json
+// @Security MerchantId
+// @Security MerchantApiKey
+// @Param id path string true "Subscription id"
+// @Success 200 {object} api_response.Response{data=res.Subscription}
+// @Failure 400 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Failure 500 {object} api_response.Response
+// @Router /api/v1/subscriptions/{id} [delete]
+func CancelSubscription(context *gin.Context) {
+	ID := context.Param("id")
+	if err := ids.Validate(ids.SUBSCRIPTION, ID); err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	existing, ok := paymentSubscriptions.Get(ID)
+	if !ok || existing.MerchantId != merchantIdFrom(context) {
+		errRes := api_response.BuildErrorResponse(http.StatusNotFound, "Not Found", "", nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	subscription, err := paymentSubscriptions.Cancel(ID, gatewayClock.Now())
+	if errors.Is(err, subscriptions.ErrSubscriptionNotFound) {
+		errRes := api_response.BuildErrorResponse(http.StatusNotFound, "Not Found", "", nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	if err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusInternalServerError, "Internal Server Error", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	res := api_response.BuildResponse(http.StatusOK, "", mapper.ToSubscriptionRes(subscription))
+	context.JSON(res.Code, res)
+	return
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/mapper"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/clock"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/threeds"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+var directoryServer threeds.DirectoryServer = threeds.NewSimulator("", clock.Real{})
+
+// SetDirectoryServer replaces the 3-D Secure directory server.
+func SetDirectoryServer(ds threeds.DirectoryServer) {
+	directoryServer = ds
+	updatePaymentService()
+}
+
+// ExpireThreeDSChallenges declines the payments whose 3-D Secure challenge
+// expired unanswered and drops their card details.
+func ExpireThreeDSChallenges() error {
+	return paymentService.ExpireThreeDSChallenges()
+}
+
+// CompleteThreeDSChallenge godoc
+// @Summary Complete a 3-D Secure challenge
+// @Description Called once the cardholder has answered the challenge. The outcome is read from the directory server, so the callback only names the challenge; 409 means it has not been answered yet.
+// @Tags payments
+// @Accept json,x-www-form-urlencoded
+// @Produce json
+// @Param id path string true "Payment id"
+// @Param request body req.ThreeDSCallbackReqModel true "Answered challenge"
+// @Success 200 {object} api_response.Response{data=res.PaymentDetails}
+// @Header 200 {string} ETag "Version of the payment, for If-None-Match and If-Match"
+// @Failure 400 {object} api_response.Response
+// @Failure 404 {object} api_response.Response
+// @Failure 409 {object} api_response.Response
+// @Failure 500 {object} api_response.Response
+// @Failure 502 {object} api_response.Response
+// @Failure 504 {object} api_response.Response
+// @Router /api/v1/payments/{id}/3ds/callback [post]
+func CompleteThreeDSChallenge(context *gin.Context) {
+	body := &req.ThreeDSCallbackReqModel{}
+	if err := body.Validate(context); err != nil {
+		errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+
+	paymentModel, err := paymentService.CompleteThreeDSChallenge(context.Param("id"), body.ChallengeId)
+	if err != nil {
+		errRes := buildServiceErrorResponse(err)
+		context.JSON(errRes.Code, errRes)
+		return
+	}
+	setPaymentETag(context, apiV1, paymentModel)
+	res := api_response.BuildResponse(http.StatusOK, "", mapper.ToPaymentDetailsRes(paymentModel))
+	context.JSON(res.Code, res)
+	return
+}
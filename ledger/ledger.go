@@ -0,0 +1,193 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/fx"
+)
+
+const (
+	ENTRY_AUTHORIZATION string = "authorization"
+	ENTRY_CAPTURE              = "capture"
+	ENTRY_FEE                  = "fee"
+	ENTRY_REFUND               = "refund"
+)
+
+const (
+	ACQUIRER_AUTHORIZED = "acquirer:authorized"
+	ACQUIRER_RECEIVABLE = "acquirer:receivable"
+	GATEWAY_FEES        = "gateway:fees"
+)
+
+var ErrUnbalancedEntry = errors.New("journal entry postings do not sum to zero")
+
+// Posting moves Amount minor units of Currency on Account. Debits are
+// positive and credits negative.
+type Posting struct {
+	Account  string
+	Currency string
+	Amount   int
+}
+
+type JournalEntry struct {
+	Id         string
+	Type       string
+	MerchantId string
+	PaymentId  string
+	Postings   []Posting
+	CreatedAt  time.Time
+}
+
+type Balance struct {
+	Currency  string
+	Available int
+	Pending   int
+}
+
+type FeeSchedule struct {
+	BasisPoints int
+	Fixed       int
+}
+
+// Fee returns the fee charged on a capture of amount, never more than the
+// amount itself.
+func (schedule FeeSchedule) Fee(amount int) int {
+	fee := amount*schedule.BasisPoints/10000 + schedule.Fixed
+	if fee > amount {
+		return amount
+	}
+	if fee < 0 {
+		return 0
+	}
+	return fee
+}
+
+type Ledger struct {
+	mu       sync.RWMutex
+	fees     FeeSchedule
+	entries  []JournalEntry
+	balances map[string]map[string]int
+	payments map[string]paymentInfo
+}
+
+type paymentInfo struct {
+	merchantId string
+	currency   string
+	amount     int
+	// fx is set when the payment settles in another currency; amounts are
+	// then posted in the settlement currency at its rate.
+	fx *fx.Rate
+}
+
+// settle converts a presentment amount into the currency the merchant is
+// paid in.
+func (payment paymentInfo) settle(amount int) (string, int) {
+	if payment.fx == nil {
+		return payment.currency, amount
+	}
+	return payment.fx.Quote, fx.Convert(amount, *payment.fx)
+}
+
+func New(fees FeeSchedule) *Ledger {
+	return &Ledger{
+		fees:     fees,
+		balances: make(map[string]map[string]int),
+		payments: make(map[string]paymentInfo),
+	}
+}
+
+func MerchantPendingAccount(merchantId string) string {
+	return "merchant:" + merchantId + ":pending"
+}
+
+func MerchantAvailableAccount(merchantId string) string {
+	return "merchant:" + merchantId + ":available"
+}
+
+// Post records a journal entry after checking that its postings balance
+// per currency.
+func (ledger *Ledger) Post(entry JournalEntry) error {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+	return ledger.post(entry)
+}
+
+func (ledger *Ledger) post(entry JournalEntry) error {
+	if err := Validate(entry); err != nil {
+		return err
+	}
+	if entry.Id == "" {
+		entry.Id = fmt.Sprintf("je_%d", len(ledger.entries)+1)
+	}
+	for _, posting := range entry.Postings {
+		if ledger.balances[posting.Account] == nil {
+			ledger.balances[posting.Account] = make(map[string]int)
+		}
+		ledger.balances[posting.Account][posting.Currency] += posting.Amount
+	}
+	ledger.entries = append(ledger.entries, entry)
+	return nil
+}
+
+func Validate(entry JournalEntry) error {
+	if len(entry.Postings) < 2 {
+		return ErrUnbalancedEntry
+	}
+	sums := make(map[string]int)
+	for _, posting := range entry.Postings {
+		sums[posting.Currency] += posting.Amount
+	}
+	for _, sum := range sums {
+		if sum != 0 {
+			return ErrUnbalancedEntry
+		}
+	}
+	return nil
+}
+
+func (ledger *Ledger) Entries() []JournalEntry {
+	ledger.mu.RLock()
+	defer ledger.mu.RUnlock()
+	return append([]JournalEntry(nil), ledger.entries...)
+}
+
+// AccountBalance returns the debit minus credit balance of an account.
+func (ledger *Ledger) AccountBalance(account string, currency string) int {
+	ledger.mu.RLock()
+	defer ledger.mu.RUnlock()
+	return ledger.balances[account][currency]
+}
+
+// Balances returns what is owed to a merchant per currency. Merchant
+// accounts are liabilities, so their credit balance is reported as positive.
+func (ledger *Ledger) Balances(merchantId string) []Balance {
+	ledger.mu.RLock()
+	defer ledger.mu.RUnlock()
+
+	byCurrency := make(map[string]*Balance)
+	get := func(currency string) *Balance {
+		if byCurrency[currency] == nil {
+			byCurrency[currency] = &Balance{Currency: currency}
+		}
+		return byCurrency[currency]
+	}
+	for currency, amount := range ledger.balances[MerchantPendingAccount(merchantId)] {
+		get(currency).Pending = -amount
+	}
+	for currency, amount := range ledger.balances[MerchantAvailableAccount(merchantId)] {
+		get(currency).Available = -amount
+	}
+
+	balances := make([]Balance, 0, len(byCurrency))
+	for _, balance := range byCurrency {
+		balances = append(balances, *balance)
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		return balances[i].Currency < balances[j].Currency
+	})
+	return balances
+}
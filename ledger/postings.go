@@ -0,0 +1,131 @@
+package ledger
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/eventlog"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/fx"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/store"
+)
+
+func (ledger *Ledger) RecordAuthorization(merchantId string, paymentId string, currency string, amount int, at time.Time) error {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+	return ledger.post(JournalEntry{
+		Type:       ENTRY_AUTHORIZATION,
+		MerchantId: merchantId,
+		PaymentId:  paymentId,
+		CreatedAt:  at,
+		Postings: []Posting{
+			{Account: ACQUIRER_AUTHORIZED, Currency: currency, Amount: amount},
+			{Account: MerchantPendingAccount(merchantId), Currency: currency, Amount: -amount},
+		},
+	})
+}
+
+// RecordCapture releases the whole authorization from pending, makes the
+// captured amount available and charges the capture fee as a separate entry.
+// A payment is captured at most once, so any uncaptured remainder is dropped.
+func (ledger *Ledger) RecordCapture(merchantId string, paymentId string, currency string, authorizedAmount int, amount int, at time.Time) error {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+	err := ledger.post(JournalEntry{
+		Type:       ENTRY_CAPTURE,
+		MerchantId: merchantId,
+		PaymentId:  paymentId,
+		CreatedAt:  at,
+		Postings: []Posting{
+			{Account: MerchantPendingAccount(merchantId), Currency: currency, Amount: authorizedAmount},
+			{Account: ACQUIRER_AUTHORIZED, Currency: currency, Amount: -authorizedAmount},
+			{Account: ACQUIRER_RECEIVABLE, Currency: currency, Amount: amount},
+			{Account: MerchantAvailableAccount(merchantId), Currency: currency, Amount: -amount},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	fee := ledger.fees.Fee(amount)
+	if fee == 0 {
+		return nil
+	}
+	return ledger.post(JournalEntry{
+		Type:       ENTRY_FEE,
+		MerchantId: merchantId,
+		PaymentId:  paymentId,
+		CreatedAt:  at,
+		Postings: []Posting{
+			{Account: MerchantAvailableAccount(merchantId), Currency: currency, Amount: fee},
+			{Account: GATEWAY_FEES, Currency: currency, Amount: -fee},
+		},
+	})
+}
+
+func (ledger *Ledger) RecordRefund(merchantId string, paymentId string, currency string, amount int, at time.Time) error {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+	return ledger.post(JournalEntry{
+		Type:       ENTRY_REFUND,
+		MerchantId: merchantId,
+		PaymentId:  paymentId,
+		CreatedAt:  at,
+		Postings: []Posting{
+			{Account: MerchantAvailableAccount(merchantId), Currency: currency, Amount: amount},
+			{Account: ACQUIRER_RECEIVABLE, Currency: currency, Amount: -amount},
+		},
+	})
+}
+
+// Apply records the postings implied by a payment event. It is used both to
+// follow the payment store live and to rebuild the ledger from the log.
+func (ledger *Ledger) Apply(event eventlog.Event) error {
+	switch event.Type {
+	case store.PAYMENT_REQUESTED:
+		var data store.PaymentRequestedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		ledger.mu.Lock()
+		ledger.payments[event.AggregateId] = paymentInfo{merchantId: data.MerchantId, currency: data.CurrencyCode, amount: data.Amount}
+		ledger.mu.Unlock()
+	case store.FX_CONVERTED:
+		var data store.FXConvertedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		ledger.mu.Lock()
+		payment := ledger.payments[event.AggregateId]
+		payment.fx = &fx.Rate{Base: payment.currency, Quote: data.SettlementCurrency, Rate: data.Rate, Timestamp: data.RateTimestamp, Source: data.RateSource}
+		ledger.payments[event.AggregateId] = payment
+		ledger.mu.Unlock()
+	case store.BANK_AUTHORIZED:
+		payment := ledger.payment(event.AggregateId)
+		currency, amount := payment.settle(payment.amount)
+		return ledger.RecordAuthorization(payment.merchantId, event.AggregateId, currency, amount, event.Timestamp)
+	case store.CAPTURED:
+		var data store.CapturedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		payment := ledger.payment(event.AggregateId)
+		currency, authorized := payment.settle(payment.amount)
+		_, captured := payment.settle(data.Amount)
+		return ledger.RecordCapture(payment.merchantId, event.AggregateId, currency, authorized, captured, event.Timestamp)
+	case store.REFUNDED:
+		var data store.RefundedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		payment := ledger.payment(event.AggregateId)
+		currency, refunded := payment.settle(data.Amount)
+		return ledger.RecordRefund(payment.merchantId, event.AggregateId, currency, refunded, event.Timestamp)
+	}
+	return nil
+}
+
+func (ledger *Ledger) payment(paymentId string) paymentInfo {
+	ledger.mu.RLock()
+	defer ledger.mu.RUnlock()
+	return ledger.payments[paymentId]
+}
@@ -1,17 +1,54 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
 	"github.com/cko-recruitment/payment-gateway-challenge-go/docs"
 	"github.com/cko-recruitment/payment-gateway-challenge-go/handlers"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/ledger"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/middlewares"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/bins"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/blocklist"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/broadcast"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/cards"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/clock"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/customers"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/eventlog"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/fx"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/idempotency"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/mandates"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ratelimit"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/risk"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/routing"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/subscriptions"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/threeds"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/vault"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/proto/paymentpb"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/reports"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/store"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	sf "github.com/swaggo/files"
 	gs "github.com/swaggo/gin-swagger"
+	"google.golang.org/grpc"
+	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 )
 
+const SHUTDOWN_TIMEOUT = 10 * time.Second
+
 var (
 	version = "dev"
 	commit  = "none"
@@ -19,12 +56,29 @@ var (
 )
 
 //	@title			Payment Gateway Challenge Go
+//	@version		1.0
 //	@description	Interview challenge for building a Payment Gateway - Go version
 
-//	@host		localhost:8080
+//	@host		localhost:8081
 //	@BasePath	/
+//	@schemes	http
 
 // @securityDefinitions.basic	BasicAuth
+
+// @securityDefinitions.apikey	AdminApiKey
+// @in							header
+// @name						X-Admin-Api-Key
+// @description				Key for the admin API, set with ADMIN_API_KEY.
+
+// @securityDefinitions.apikey	MerchantApiKey
+// @in							header
+// @name						X-Api-Key
+// @description				Merchant API key, set with MERCHANT_API_KEYS. It decides the merchant the request is made for.
+
+// @securityDefinitions.apikey	MerchantId
+// @in							header
+// @name						X-Merchant-Id
+// @description				Merchant the request is made for. Required unless the request carries a merchant API key, set with MERCHANT_API_KEYS, and refused if it names another merchant than the key.
 func main() {
 	err := godotenv.Load()
 	if err != nil {
@@ -36,19 +90,537 @@ func main() {
 	fmt.Printf("version %s, commit %s, built at %s", version, commit, date)
 
 	var mode string
+	var randomFingerprintKey bool
 	flag.StringVar(&mode, "mode", "debug", "Set Gin mode")
+	flag.BoolVar(&randomFingerprintKey, "random-fingerprint-key", false, "Use a random card fingerprint key when CARD_FINGERPRINT_KEY is unset, for local development only")
 	flag.Parse()
 
 	gin.SetMode(mode)
 	docs.SwaggerInfo.Version = version
 
+	systemClock := clock.Real{}
+	handlers.SetClock(systemClock)
+
+	fingerprintKey, err := buildFingerprintKey(randomFingerprintKey)
+	if err != nil {
+		log.Fatalf("could not load card fingerprint key: %v", err)
+	}
+	cards.SetFingerprintKey(fingerprintKey)
+	cardVault, err := buildCardVault(systemClock)
+	if err != nil {
+		log.Fatalf("could not open card vault: %v", err)
+	}
+	handlers.SetCardVault(cardVault)
+
+	paymentLog, err := buildPaymentLog()
+	if err != nil {
+		log.Fatalf("could not open payment event log: %v", err)
+	}
+	paymentStore, err := buildPaymentStore(paymentLog)
+	if err != nil {
+		log.Fatalf("could not recover payment store: %v", err)
+	}
+	merchantLedger, err := buildLedger(paymentLog)
+	if err != nil {
+		log.Fatalf("could not rebuild ledger: %v", err)
+	}
+	paymentStore.Subscribe(func(event eventlog.Event) {
+		if err := merchantLedger.Apply(event); err != nil {
+			log.Printf("could not post event %d to the ledger: %v", event.Sequence, err)
+		}
+	})
+	streamBuffer, err := intFromEnv("PAYMENT_STREAM_BUFFER", handlers.DEFAULT_STREAM_BUFFER)
+	if err != nil {
+		log.Fatalf("could not parse payment stream buffer: %v", err)
+	}
+	paymentStreams := broadcast.NewBroker(streamBuffer, paymentLog.LastSequence())
+	handlers.SetPaymentStreams(paymentStreams)
+	paymentStore.SubscribeUpdates(handlers.PublishPaymentUpdate)
+	acquirerRouter, err := buildAcquirerRouter()
+	if err != nil {
+		log.Fatalf("could not load acquirer routing: %v", err)
+	}
+	handlers.SetPaymentStore(paymentStore)
+	handlers.SetLedger(merchantLedger)
+	handlers.SetAcquirerRouter(acquirerRouter)
+	riskEngine, err := buildRiskEngine()
+	if err != nil {
+		log.Fatalf("could not load risk rules: %v", err)
+	}
+	handlers.SetRiskEngine(riskEngine)
+	if binTablePath := os.Getenv("BIN_TABLE_PATH"); binTablePath != "" {
+		binTable, err := bins.LoadCSV(binTablePath)
+		if err != nil {
+			log.Fatalf("could not load BIN table: %v", err)
+		}
+		handlers.SetBinLookup(binTable)
+	}
+	if settlementCurrency := os.Getenv("SETTLEMENT_CURRENCY"); settlementCurrency != "" {
+		fxProvider, err := buildFXProvider(systemClock)
+		if err != nil {
+			log.Fatalf("could not load exchange rates: %v", err)
+		}
+		handlers.SetSettlement(settlementCurrency, fxProvider)
+	}
+	paymentBlocklist, err := buildBlocklist()
+	if err != nil {
+		log.Fatalf("could not recover blocklist: %v", err)
+	}
+	handlers.SetBlocklist(paymentBlocklist)
+	threeDSSimulator := threeds.NewSimulator("", systemClock)
+	handlers.SetDirectoryServer(threeDSSimulator)
+	if timezone := os.Getenv("CARD_EXPIRY_TIMEZONE"); timezone != "" {
+		expiryLocation, err := time.LoadLocation(timezone)
+		if err != nil {
+			log.Fatalf("could not load card expiry timezone: %v", err)
+		}
+		handlers.SetExpiryLocation(expiryLocation)
+	}
+
+	schedulerSeconds, err := intFromEnv("SUBSCRIPTION_SCHEDULER_INTERVAL_SECONDS", 60)
+	if err != nil {
+		log.Fatalf("could not parse subscription scheduler interval: %v", err)
+	}
+	customerStore, err := buildCustomers()
+	if err != nil {
+		log.Fatalf("could not recover customers: %v", err)
+	}
+	handlers.SetCustomers(customerStore)
+	mandateStore, err := buildMandates()
+	if err != nil {
+		log.Fatalf("could not recover mandates: %v", err)
+	}
+	handlers.SetMandates(mandateStore)
+	subscriptionStore, err := buildSubscriptions()
+	if err != nil {
+		log.Fatalf("could not recover subscriptions: %v", err)
+	}
+	handlers.SetSubscriptions(subscriptionStore)
+	// Closing stopSchedulers on shutdown stops new charges; a charge already
+	// running is waited for.
+	stopSchedulers := make(chan struct{})
+	var schedulers sync.WaitGroup
+	schedulers.Add(1)
+	go func() {
+		defer schedulers.Done()
+		subscriptions.NewScheduler(subscriptionStore, handlers.ChargeSubscription, systemClock).Start(time.Duration(schedulerSeconds)*time.Second, stopSchedulers)
+	}()
+
+	schedulers.Add(1)
+	go func() {
+		defer schedulers.Done()
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopSchedulers:
+				return
+			case <-ticker.C:
+				if err := handlers.ExpireThreeDSChallenges(); err != nil {
+					log.Printf("could not expire 3-D Secure challenges: %v", err)
+				}
+			}
+		}
+	}()
+
+	reportLocation := time.UTC
+	if timezone := os.Getenv("REPORT_TIMEZONE"); timezone != "" {
+		reportLocation, err = time.LoadLocation(timezone)
+		if err != nil {
+			log.Fatalf("could not load report timezone: %v", err)
+		}
+	}
+	reportStore := reports.NewStore(os.Getenv("REPORTS_DIR"))
+	if err := reportStore.Load(); err != nil {
+		log.Fatalf("could not load settlement reports: %v", err)
+	}
+	handlers.SetReports(reportStore, reportLocation)
+	go reports.NewScheduler(reportStore, merchantLedger.Entries, reportLocation, systemClock).Start(time.Hour, stopSchedulers)
+
+	spec, err := docs.OpenAPI3()
+	if err != nil {
+		log.Fatalf("could not load openapi spec: %v", err)
+	}
+	specValidation, err := middlewares.OpenAPIValidation(spec, middlewares.OpenAPIValidationConfig{ValidateResponses: gin.Mode() == gin.TestMode})
+	if err != nil {
+		log.Fatalf("could not build openapi validation: %v", err)
+	}
+
 	r := gin.Default()
+	r.Use(specValidation)
 	r.GET("/ping", Ping)
 	r.GET("/swagger/*any", gs.WrapHandler(sf.Handler))
-	paymentGroup := r.Group("api/v1/payments")
-	paymentGroup.POST("", handlers.CreatePayment)
+	r.GET("/openapi3.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, spec)
+	})
+	r.GET(threeds.CHALLENGE_PATH+":id", gin.WrapH(threeDSSimulator))
+	r.POST(threeds.CHALLENGE_PATH+":id", gin.WrapH(threeDSSimulator))
+	v1Deprecation, err := buildV1Deprecation()
+	if err != nil {
+		log.Fatalf("could not parse v1 deprecation dates: %v", err)
+	}
+	merchantAPIKeys, err := buildMerchantAPIKeys()
+	if err != nil {
+		log.Fatalf("could not parse merchant API keys: %v", err)
+	}
+	if len(merchantAPIKeys) == 0 {
+		log.Println("MERCHANT_API_KEYS is unset, trusting the X-Merchant-Id header: only run like this behind a proxy that authenticates merchants")
+	}
+	merchantAuth := middlewares.MerchantAuth(merchantAPIKeys)
+	paymentGroup := r.Group("api/v1/payments", merchantAuth)
+	if v1Deprecation != nil {
+		paymentGroup.Use(middlewares.Deprecation(*v1Deprecation))
+	}
+	rateLimitConfig, err := buildRateLimitConfig()
+	if err != nil {
+		log.Fatalf("could not parse rate limits: %v", err)
+	}
+	rateLimitConfig.Clock = systemClock
+	rateLimitStore := ratelimit.NewMemoryStore()
+	idempotencyStore := idempotency.NewStore(24 * time.Hour)
+	paymentGroup.POST("", middlewares.Idempotency(idempotencyStore, systemClock), middlewares.RateLimit(rateLimitStore, rateLimitConfig), handlers.CreatePayment)
+	paymentGroup.GET("", handlers.ListPayments)
 	paymentGroup.GET(":id", handlers.GetPaymentById)
-	r.Run(":8081")
+	paymentGroup.GET(":id/events", handlers.GetPaymentEvents)
+	paymentGroup.POST(":id/captures", middlewares.Idempotency(idempotencyStore, systemClock), handlers.CapturePayment)
+	paymentGroup.POST(":id/refunds", middlewares.Idempotency(idempotencyStore, systemClock), handlers.RefundPayment)
+	// The directory server is sent this URL and streams have no v2
+	// counterpart, so they are not deprecated.
+	r.POST("api/v1/payments/:id/3ds/callback", handlers.CompleteThreeDSChallenge)
+	r.GET("api/v1/payments/:id/stream", merchantAuth, handlers.StreamPayment)
+	paymentV2Group := r.Group("api/v2/payments", merchantAuth)
+	paymentV2Group.POST("", middlewares.Idempotency(idempotencyStore, systemClock), middlewares.RateLimit(rateLimitStore, rateLimitConfig), handlers.CreatePaymentV2)
+	paymentV2Group.GET("", handlers.ListPaymentsV2)
+	paymentV2Group.GET(":id", handlers.GetPaymentV2)
+	paymentV2Group.GET(":id/events", handlers.GetPaymentEventsV2)
+	paymentV2Group.POST(":id/captures", middlewares.Idempotency(idempotencyStore, systemClock), handlers.CapturePaymentV2)
+	paymentV2Group.POST(":id/refunds", middlewares.Idempotency(idempotencyStore, systemClock), handlers.RefundPaymentV2)
+	customerGroup := r.Group("api/v1/customers", merchantAuth)
+	customerGroup.POST("", handlers.CreateCustomer)
+	customerGroup.GET(":id", handlers.GetCustomer)
+	customerGroup.POST(":id/payment_methods", handlers.AttachCard)
+	customerGroup.GET(":id/payments", handlers.ListCustomerPayments)
+	mandateGroup := r.Group("api/v1/mandates", merchantAuth)
+	mandateGroup.GET(":id", handlers.GetMandate)
+	mandateGroup.DELETE(":id", handlers.RevokeMandate)
+	mandateGroup.POST(":id/payments", middlewares.Idempotency(idempotencyStore, systemClock), handlers.CreateMandatePayment)
+	subscriptionGroup := r.Group("api/v1/subscriptions", merchantAuth)
+	subscriptionGroup.POST("", handlers.CreateSubscription)
+	subscriptionGroup.GET(":id", handlers.GetSubscription)
+	subscriptionGroup.DELETE(":id", handlers.CancelSubscription)
+	r.GET("api/v1/events/stream", merchantAuth, handlers.StreamMerchantEvents)
+	r.GET("api/v1/balances", merchantAuth, handlers.GetBalances)
+	r.GET("api/v1/reports", merchantAuth, handlers.ListReports)
+	r.GET("api/v1/reports/:id", merchantAuth, handlers.GetReport)
+	adminAuth := middlewares.AdminAuth(os.Getenv("ADMIN_API_KEY"))
+	r.POST("api/v1/admin/reconciliations", adminAuth, handlers.ReconcileSettlement)
+	blocklistGroup := r.Group("api/v1/admin/blocklist", adminAuth)
+	blocklistGroup.POST("", handlers.AddBlocklistEntry)
+	blocklistGroup.GET("", handlers.ListBlocklistEntries)
+	blocklistGroup.DELETE(":id", handlers.RemoveBlocklistEntry)
+	grpcPort, err := intFromEnv("GRPC_PORT", 9090)
+	if err != nil {
+		log.Fatalf("could not parse gRPC port: %v", err)
+	}
+	grpcListener, err := net.Listen("tcp", ":"+strconv.Itoa(grpcPort))
+	if err != nil {
+		log.Fatalf("could not listen for gRPC: %v", err)
+	}
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		middlewares.MerchantAuthInterceptor(merchantAPIKeys),
+		middlewares.IdempotencyInterceptor(idempotencyStore, systemClock, paymentpb.PaymentService_CreatePayment_FullMethodName),
+		middlewares.RateLimitInterceptor(rateLimitStore, rateLimitConfig, paymentpb.PaymentService_CreatePayment_FullMethodName),
+	))
+	paymentpb.RegisterPaymentServiceServer(grpcServer, &handlers.PaymentServiceServer{})
+	go func() {
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Fatalf("gRPC server stopped: %v", err)
+		}
+	}()
+
+	// Closing the streams on shutdown lets their requests finish, so that
+	// Shutdown does not wait on them until the timeout.
+	server := &http.Server{Addr: ":8081", Handler: middlewares.NegotiateVersion(r, middlewares.VersionConfig{Default: "1", Supported: []string{"1", "2"}})}
+	server.RegisterOnShutdown(paymentStreams.Close)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("HTTP server stopped: %v", err)
+		}
+	}()
+
+	signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-signals.Done()
+	log.Println("shutting down")
+	close(stopSchedulers)
+	shutdownContext, cancel := context.WithTimeout(context.Background(), SHUTDOWN_TIMEOUT)
+	defer cancel()
+	if err := server.Shutdown(shutdownContext); err != nil {
+		log.Printf("could not shut down the HTTP server: %v", err)
+	}
+	grpcServer.GracefulStop()
+	schedulers.Wait()
+}
+
+// buildPaymentLog uses a file backed event log when PAYMENT_EVENT_LOG_PATH
+// is set and keeps events in memory otherwise.
+func buildPaymentLog() (eventlog.Log, error) {
+	logPath := os.Getenv("PAYMENT_EVENT_LOG_PATH")
+	if logPath == "" {
+		return eventlog.NewMemoryLog(), nil
+	}
+	return eventlog.OpenFileLog(logPath)
+}
+
+// buildPaymentStore replays the payment log, starting from the snapshot at
+// PAYMENT_SNAPSHOT_PATH if one exists.
+func buildPaymentStore(paymentLog eventlog.Log) (*store.PaymentStore, error) {
+	snapshotEvery, err := intFromEnv("PAYMENT_SNAPSHOT_EVERY", 100)
+	if err != nil {
+		return nil, err
+	}
+	paymentStore := store.NewPaymentStore(paymentLog, store.Options{
+		SnapshotPath:  os.Getenv("PAYMENT_SNAPSHOT_PATH"),
+		SnapshotEvery: snapshotEvery,
+	})
+	return paymentStore, paymentStore.Recover()
+}
+
+// buildLedger rebuilds merchant balances from the full payment log.
+func buildLedger(paymentLog eventlog.Log) (*ledger.Ledger, error) {
+	basisPoints, err := intFromEnv("LEDGER_FEE_BASIS_POINTS", 0)
+	if err != nil {
+		return nil, err
+	}
+	fixed, err := intFromEnv("LEDGER_FEE_FIXED", 0)
+	if err != nil {
+		return nil, err
+	}
+	merchantLedger := ledger.New(ledger.FeeSchedule{BasisPoints: basisPoints, Fixed: fixed})
+	return merchantLedger, paymentLog.Replay(0, merchantLedger.Apply)
+}
+
+// buildFingerprintKey reads the card fingerprint key from
+// CARD_FINGERPRINT_KEY. Persisted fingerprints, blocklist entries and risk
+// rules all depend on the key, so a random one is only used when random is
+// set, and fingerprints then change on every restart.
+func buildFingerprintKey(random bool) ([]byte, error) {
+	if key := os.Getenv("CARD_FINGERPRINT_KEY"); key != "" {
+		return []byte(key), nil
+	}
+	if !random {
+		return nil, errors.New("CARD_FINGERPRINT_KEY is not set, start with -random-fingerprint-key to use a random key for local development")
+	}
+	log.Println("CARD_FINGERPRINT_KEY is not set, card fingerprints will change on restart")
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// buildCardVault keeps card numbers in the log at CARD_VAULT_LOG_PATH,
+// encrypted with CARD_VAULT_KEY, or in memory when no path is set.
+func buildCardVault(clock clock.Clock) (*vault.Vault, error) {
+	logPath := os.Getenv("CARD_VAULT_LOG_PATH")
+	if logPath == "" {
+		return vault.New(), nil
+	}
+	key, err := hex.DecodeString(os.Getenv("CARD_VAULT_KEY"))
+	if err != nil {
+		return nil, fmt.Errorf("CARD_VAULT_KEY: %w", err)
+	}
+	vaultLog, err := eventlog.OpenFileLog(logPath)
+	if err != nil {
+		return nil, err
+	}
+	return vault.Open(vaultLog, key, clock)
+}
+
+// buildBlocklist keeps blocklist entries in the log at BLOCKLIST_LOG_PATH,
+// or in memory when no path is set.
+func buildBlocklist() (*blocklist.Blocklist, error) {
+	logPath := os.Getenv("BLOCKLIST_LOG_PATH")
+	if logPath == "" {
+		return blocklist.New(), nil
+	}
+	blocklistLog, err := eventlog.OpenFileLog(logPath)
+	if err != nil {
+		return nil, err
+	}
+	return blocklist.Open(blocklistLog)
+}
+
+// buildCustomers keeps customers and their saved cards in the log at
+// CUSTOMER_LOG_PATH, or in memory when no path is set.
+func buildCustomers() (*customers.Store, error) {
+	logPath := os.Getenv("CUSTOMER_LOG_PATH")
+	if logPath == "" {
+		return customers.New(), nil
+	}
+	customerLog, err := eventlog.OpenFileLog(logPath)
+	if err != nil {
+		return nil, err
+	}
+	return customers.Open(customerLog)
+}
+
+// buildMandates keeps mandates in the log at MANDATE_LOG_PATH, or in memory
+// when no path is set.
+func buildMandates() (*mandates.Store, error) {
+	logPath := os.Getenv("MANDATE_LOG_PATH")
+	if logPath == "" {
+		return mandates.New(), nil
+	}
+	mandateLog, err := eventlog.OpenFileLog(logPath)
+	if err != nil {
+		return nil, err
+	}
+	return mandates.Open(mandateLog)
+}
+
+// buildSubscriptions keeps subscriptions in the log at
+// SUBSCRIPTION_LOG_PATH, or in memory when no path is set.
+func buildSubscriptions() (*subscriptions.Store, error) {
+	logPath := os.Getenv("SUBSCRIPTION_LOG_PATH")
+	if logPath == "" {
+		return subscriptions.NewStore(), nil
+	}
+	subscriptionLog, err := eventlog.OpenFileLog(logPath)
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions.OpenStore(subscriptionLog)
+}
+
+// buildAcquirerRouter loads the acquirers and routing rules from
+// ACQUIRERS_CONFIG_PATH, or routes every payment to ACQUIRING_BANK_BASE_URL.
+func buildAcquirerRouter() (*routing.Router, error) {
+	configPath := os.Getenv("ACQUIRERS_CONFIG_PATH")
+	if configPath == "" {
+		return routing.NewSingleAcquirerRouter(), nil
+	}
+	config, err := routing.LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return routing.NewRouter(config), nil
+}
+
+// buildRiskEngine loads the rules at RISK_RULES_PATH and reloads them when
+// the file changes. Every payment is allowed when no rules are configured.
+func buildRiskEngine() (*risk.Engine, error) {
+	rulesPath := os.Getenv("RISK_RULES_PATH")
+	if rulesPath == "" {
+		return risk.NewEngine(risk.Config{}), nil
+	}
+	config, err := risk.LoadConfig(rulesPath)
+	if err != nil {
+		return nil, err
+	}
+	reloadSeconds, err := intFromEnv("RISK_RULES_RELOAD_SECONDS", 10)
+	if err != nil {
+		return nil, err
+	}
+	riskEngine := risk.NewEngine(config)
+	go riskEngine.WatchFile(rulesPath, time.Duration(reloadSeconds)*time.Second, nil)
+	return riskEngine, nil
+}
+
+// buildFXProvider reads exchange rates from the file at FX_RATES_PATH, or
+// from pairs such as "USD/GBP=0.79" in FX_STATIC_RATES.
+func buildFXProvider(clock clock.Clock) (fx.Provider, error) {
+	if ratesPath := os.Getenv("FX_RATES_PATH"); ratesPath != "" {
+		return fx.OpenFile(ratesPath)
+	}
+	rates, err := fx.ParseRates(os.Getenv("FX_STATIC_RATES"))
+	if err != nil {
+		return nil, err
+	}
+	return fx.NewStatic(rates, clock.Now(), "static"), nil
+}
+
+// buildRateLimitConfig reads limits such as "100/1m" from the environment.
+// Unset limits are not enforced.
+func buildRateLimitConfig() (middlewares.RateLimitConfig, error) {
+	var config middlewares.RateLimitConfig
+	limits := map[string]**ratelimit.Limit{
+		"RATE_LIMIT_PER_API_KEY": &config.PerAPIKey,
+		"RATE_LIMIT_PER_IP":      &config.PerIP,
+		"RATE_LIMIT_PER_CARD":    &config.PerCard,
+	}
+	for key, target := range limits {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		limit, err := ratelimit.ParseLimit(value)
+		if err != nil {
+			return config, err
+		}
+		*target = &limit
+	}
+	return config, nil
+}
+
+// buildMerchantAPIKeys reads MERCHANT_API_KEYS, a comma separated list of
+// key:merchant pairs, into a map from API key to merchant.
+func buildMerchantAPIKeys() (map[string]string, error) {
+	apiKeys := make(map[string]string)
+	value := os.Getenv("MERCHANT_API_KEYS")
+	if value == "" {
+		return apiKeys, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		key, merchantId, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || key == "" || merchantId == "" {
+			return nil, fmt.Errorf("%q is not a key:merchant pair", pair)
+		}
+		apiKeys[key] = merchantId
+	}
+	return apiKeys, nil
+}
+
+// buildV1Deprecation reads the dates the v1 payments API was deprecated on
+// and is sunset on from API_V1_DEPRECATED_AT and API_V1_SUNSET_AT. v1 is not
+// marked deprecated until API_V1_DEPRECATED_AT is set.
+func buildV1Deprecation() (*middlewares.DeprecationConfig, error) {
+	deprecatedAt, err := dateFromEnv("API_V1_DEPRECATED_AT")
+	if err != nil {
+		return nil, err
+	}
+	sunset, err := dateFromEnv("API_V1_SUNSET_AT")
+	if err != nil {
+		return nil, err
+	}
+	if deprecatedAt.IsZero() {
+		if !sunset.IsZero() {
+			return nil, errors.New("API_V1_SUNSET_AT is set without API_V1_DEPRECATED_AT")
+		}
+		return nil, nil
+	}
+	return &middlewares.DeprecationConfig{
+		DeprecatedAt: deprecatedAt,
+		Sunset:       sunset,
+		Successor:    "/api/v2/payments",
+	}, nil
+}
+
+func intFromEnv(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// dateFromEnv returns the zero time when key is unset.
+func dateFromEnv(key string) (time.Time, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.DateOnly, value)
 }
 
 // PingExample godoc
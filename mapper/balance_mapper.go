@@ -0,0 +1,18 @@
+package mapper
+
+import (
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/res"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/ledger"
+)
+
+func ToBalancesRes(balances []ledger.Balance) []res.Balance {
+	balancesRes := make([]res.Balance, 0, len(balances))
+	for _, balance := range balances {
+		balancesRes = append(balancesRes, res.Balance{
+			Currency:  balance.Currency,
+			Available: balance.Available,
+			Pending:   balance.Pending,
+		})
+	}
+	return balancesRes
+}
@@ -0,0 +1,37 @@
+package mapper
+
+import (
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/res"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/blocklist"
+	"time"
+)
+
+func ToBlocklistEntry(body *req.CreateBlocklistEntryReqModel, createdAt time.Time) blocklist.Entry {
+	return blocklist.Entry{
+		Type:      body.Type,
+		Value:     body.Value,
+		Reason:    body.Reason,
+		ExpiresAt: body.ExpiresAt,
+		CreatedAt: createdAt,
+	}
+}
+
+func ToBlocklistEntryRes(entry blocklist.Entry) res.BlocklistEntry {
+	return res.BlocklistEntry{
+		Id:        entry.Id,
+		Type:      entry.Type,
+		Value:     entry.Value,
+		Reason:    entry.Reason,
+		ExpiresAt: entry.ExpiresAt,
+		CreatedAt: entry.CreatedAt,
+	}
+}
+
+func ToBlocklistEntriesRes(entries []blocklist.Entry) []res.BlocklistEntry {
+	entriesRes := make([]res.BlocklistEntry, 0, len(entries))
+	for _, entry := range entries {
+		entriesRes = append(entriesRes, ToBlocklistEntryRes(entry))
+	}
+	return entriesRes
+}
@@ -0,0 +1,56 @@
+package mapper
+
+import (
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/res"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/cards"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/customers"
+)
+
+func ToCustomer(merchantId string, body *req.CreateCustomerReqModel, createdAt time.Time) customers.Customer {
+	return customers.Customer{
+		MerchantId: merchantId,
+		Email:      body.Email,
+		Name:       body.Name,
+		CreatedAt:  createdAt,
+	}
+}
+
+func ToPaymentMethod(body *req.AttachCardReqModel, cardToken string, createdAt time.Time) customers.PaymentMethod {
+	return customers.PaymentMethod{
+		CardToken:       cardToken,
+		CardLast4:       cards.Last4(body.CardNumber),
+		CardFingerprint: cards.Fingerprint(body.CardNumber),
+		ExpirationMonth: body.ExpirationMonth,
+		ExpirationYear:  body.ExpirationYear,
+		Brand:           cards.Brand(body.CardNumber),
+		CreatedAt:       createdAt,
+	}
+}
+
+func ToCustomerRes(customer customers.Customer) res.Customer {
+	methodsRes := make([]res.PaymentMethod, 0, len(customer.PaymentMethods))
+	for _, method := range customer.PaymentMethods {
+		methodsRes = append(methodsRes, ToPaymentMethodRes(method))
+	}
+	return res.Customer{
+		Id:             customer.Id,
+		Email:          customer.Email,
+		Name:           customer.Name,
+		PaymentMethods: methodsRes,
+		CreatedAt:      customer.CreatedAt,
+	}
+}
+
+func ToPaymentMethodRes(method customers.PaymentMethod) res.PaymentMethod {
+	return res.PaymentMethod{
+		Id:                method.Id,
+		Brand:             method.Brand,
+		LastFourCardDigit: method.CardLast4,
+		ExpiryMonth:       method.ExpirationMonth,
+		ExpiryYear:        method.ExpirationYear,
+		CreatedAt:         method.CreatedAt,
+	}
+}
@@ -0,0 +1,68 @@
+package mapper
+
+import (
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/res"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/mandates"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/subscriptions"
+)
+
+func ToMandateRes(mandate mandates.Mandate) res.Mandate {
+	return res.Mandate{
+		Id:                mandate.Id,
+		Status:            mandate.Status,
+		LastFourCardDigit: mandate.CardLast4,
+		ExpiryMonth:       mandate.ExpirationMonth,
+		ExpiryYear:        mandate.ExpirationYear,
+		InitialPaymentId:  mandate.InitialPaymentId,
+		CreatedAt:         mandate.CreatedAt,
+	}
+}
+
+// ToSubscription expects a validated body; the subscription starts now
+// unless the body asks for a later date.
+func ToSubscription(merchantId string, body *req.CreateSubscriptionReqModel, now time.Time) subscriptions.Subscription {
+	retrySchedule := make([]time.Duration, 0, len(body.RetrySchedule))
+	for _, delay := range body.RetrySchedule {
+		parsed, _ := time.ParseDuration(delay)
+		retrySchedule = append(retrySchedule, parsed)
+	}
+	startAt := now
+	if body.StartAt != nil && body.StartAt.After(now) {
+		startAt = *body.StartAt
+	}
+	return subscriptions.Subscription{
+		MerchantId:     merchantId,
+		MandateId:      body.MandateId,
+		Amount:         body.Amount,
+		Currency:       body.Currency,
+		IntervalMonths: body.IntervalMonths,
+		RetrySchedule:  retrySchedule,
+		StartAt:        startAt,
+		CreatedAt:      now,
+	}
+}
+
+func ToSubscriptionRes(subscription subscriptions.Subscription) res.Subscription {
+	retrySchedule := make([]string, 0, len(subscription.RetrySchedule))
+	for _, delay := range subscription.RetrySchedule {
+		retrySchedule = append(retrySchedule, delay.String())
+	}
+	paymentIds := make([]string, 0, len(subscription.PaymentIds))
+	paymentIds = append(paymentIds, subscription.PaymentIds...)
+	return res.Subscription{
+		Id:             subscription.Id,
+		MandateId:      subscription.MandateId,
+		Status:         subscription.Status,
+		Currency:       subscription.Currency,
+		Amount:         subscription.Amount,
+		IntervalMonths: subscription.IntervalMonths,
+		RetrySchedule:  retrySchedule,
+		NextChargeAt:   subscription.NextChargeAt,
+		FailedAttempts: subscription.Attempt,
+		PaymentIds:     paymentIds,
+		CreatedAt:      subscription.CreatedAt,
+	}
+}
@@ -3,28 +3,150 @@ package mapper
 import (
 	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/res"
 	"github.com/cko-recruitment/payment-gateway-challenge-go/models"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/bins"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/risk"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/store"
 )
 
 func ToPaymentDetailsRes(payment models.Payment) res.PaymentDetails {
 	return res.PaymentDetails{
 		Id:                payment.Id,
 		Status:            payment.Status,
-		LastFourCardDigit: payment.CardNumber[len(payment.CardNumber)-4:],
+		CustomerId:        payment.CustomerId,
+		ReasonCode:        payment.ReasonCode,
+		LastFourCardDigit: payment.CardLast4,
+		CardFingerprint:   payment.CardFingerprint,
+		Bin:               ToBinRes(payment.Bin),
 		ExpiryMonth:       payment.ExpirationMonth,
 		ExpiryYear:        payment.ExpirationYear,
 		CurrencyCode:      payment.CurrencyCode,
 		Amount:            payment.Amount,
+		Acquirer:          payment.Acquirer,
+		CapturedAmount:    payment.CapturedAmount,
+		RefundedAmount:    payment.RefundedAmount,
+		Refunds:           ToRefundsRes(payment.Refunds),
+		ThreeDS:           ToThreeDSRes(payment.ThreeDS),
+		Risk:              ToRiskRes(payment.Risk),
+		FX:                ToFXRes(payment.FX),
+		MandateId:         payment.MandateId,
+		MerchantInitiated: payment.MerchantInitiated,
+		CreatedAt:         payment.CreatedAt,
+		UpdatedAt:         payment.UpdatedAt,
 	}
 }
 
-func ToPaymentModel(id string, status string, cardNumber string, expiryMonth int, expiryYear int, currencyCode string, amount int) models.Payment {
-	return models.Payment{
-		Id:              id,
-		Status:          status,
-		CardNumber:      cardNumber,
-		ExpirationMonth: expiryMonth,
-		ExpirationYear:  expiryYear,
-		CurrencyCode:    currencyCode,
-		Amount:          amount,
+func ToPaymentsRes(payments []models.Payment) []res.PaymentDetails {
+	paymentsRes := make([]res.PaymentDetails, 0, len(payments))
+	for _, payment := range payments {
+		paymentsRes = append(paymentsRes, ToPaymentDetailsRes(payment))
+	}
+	return paymentsRes
+}
+
+func ToRefundsRes(refunds []models.Refund) []res.Refund {
+	refundsRes := make([]res.Refund, 0, len(refunds))
+	for _, refund := range refunds {
+		refundsRes = append(refundsRes, res.Refund{
+			Id:        refund.Id,
+			Amount:    refund.Amount,
+			CreatedAt: refund.CreatedAt,
+		})
+	}
+	return refundsRes
+}
+
+func ToBinRes(bin *models.BinDetails) *res.Bin {
+	if bin == nil {
+		return nil
+	}
+	return &res.Bin{
+		Scheme:     bin.Scheme,
+		Issuer:     bin.Issuer,
+		Country:    bin.Country,
+		Funding:    bin.Funding,
+		Commercial: bin.Commercial,
+	}
+}
+
+func ToRiskRes(assessment *models.RiskAssessment) *res.Risk {
+	if assessment == nil {
+		return nil
+	}
+	return &res.Risk{
+		Score:    assessment.Score,
+		Decision: assessment.Decision,
+		Rules:    assessment.Rules,
+	}
+}
+
+func ToFXRes(conversion *models.FXConversion) *res.FX {
+	if conversion == nil {
+		return nil
+	}
+	return &res.FX{
+		SettlementCurrency: conversion.SettlementCurrency,
+		SettlementAmount:   conversion.SettlementAmount,
+		Rate:               conversion.Rate,
+		RateTimestamp:      conversion.RateTimestamp,
+		RateSource:         conversion.RateSource,
+	}
+}
+
+func ToThreeDSRes(threeDS *models.ThreeDSecure) *res.ThreeDS {
+	if threeDS == nil {
+		return nil
+	}
+	threeDSRes := &res.ThreeDS{
+		Status:         threeDS.Status,
+		ECI:            threeDS.ECI,
+		LiabilityShift: threeDS.LiabilityShift,
+	}
+	if threeDS.Status == "" {
+		threeDSRes.ChallengeURL = threeDS.ChallengeURL
+	}
+	return threeDSRes
+}
+
+func ToPaymentEventsRes(payment models.Payment) []res.PaymentEvent {
+	events := make([]res.PaymentEvent, 0, len(payment.StatusHistory))
+	for _, transition := range payment.StatusHistory {
+		events = append(events, res.PaymentEvent{
+			From:      transition.From,
+			To:        transition.To,
+			Timestamp: transition.Timestamp,
+			Reason:    transition.Reason,
+			Actor:     transition.Actor,
+		})
+	}
+	return events
+}
+
+func ToPaymentStatusUpdateRes(payment models.Payment, transition models.StatusTransition) res.PaymentStatusUpdate {
+	return res.PaymentStatusUpdate{
+		PaymentId: payment.Id,
+		From:      transition.From,
+		To:        transition.To,
+		Timestamp: transition.Timestamp,
+		Reason:    transition.Reason,
+		Actor:     transition.Actor,
+		Version:   payment.Version,
+	}
+}
+
+func ToBinData(info bins.Info) *store.BinData {
+	return &store.BinData{
+		Scheme:     info.Scheme,
+		Issuer:     info.Issuer,
+		Country:    info.Country,
+		Funding:    info.Funding,
+		Commercial: info.Commercial,
+	}
+}
+
+func ToRiskAssessedData(assessment risk.Assessment) store.RiskAssessedData {
+	return store.RiskAssessedData{
+		Score:    assessment.Score,
+		Decision: assessment.Decision,
+		Rules:    assessment.Rules,
 	}
 }
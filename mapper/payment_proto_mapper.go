@@ -0,0 +1,98 @@
+package mapper
+
+import (
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/res"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/models"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/proto/paymentpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ToPaymentMessage maps a payment the same way as ToPaymentDetailsRes, for
+// the gRPC API.
+func ToPaymentMessage(payment models.Payment) *paymentpb.Payment {
+	details := ToPaymentDetailsRes(payment)
+	message := &paymentpb.Payment{
+		Id:                details.Id,
+		Status:            details.Status,
+		CustomerId:        details.CustomerId,
+		ReasonCode:        details.ReasonCode,
+		LastFourCardDigit: details.LastFourCardDigit,
+		CardFingerprint:   details.CardFingerprint,
+		ExpiryMonth:       int32(details.ExpiryMonth),
+		ExpiryYear:        int32(details.ExpiryYear),
+		CurrencyCode:      details.CurrencyCode,
+		Amount:            int64(details.Amount),
+		Acquirer:          details.Acquirer,
+		CapturedAmount:    int64(details.CapturedAmount),
+		RefundedAmount:    int64(details.RefundedAmount),
+		Refunds:           toRefundMessages(details.Refunds),
+		MandateId:         details.MandateId,
+		MerchantInitiated: details.MerchantInitiated,
+		CreatedAt:         timestamppb.New(details.CreatedAt),
+		UpdatedAt:         timestamppb.New(details.UpdatedAt),
+	}
+	if details.ThreeDS != nil {
+		message.ThreeDs = &paymentpb.ThreeDS{
+			ChallengeUrl:   details.ThreeDS.ChallengeURL,
+			Status:         details.ThreeDS.Status,
+			Eci:            details.ThreeDS.ECI,
+			LiabilityShift: details.ThreeDS.LiabilityShift,
+		}
+	}
+	if details.Bin != nil {
+		message.Bin = &paymentpb.Bin{
+			Scheme:     details.Bin.Scheme,
+			Issuer:     details.Bin.Issuer,
+			Country:    details.Bin.Country,
+			Funding:    details.Bin.Funding,
+			Commercial: details.Bin.Commercial,
+		}
+	}
+	if details.Risk != nil {
+		message.Risk = &paymentpb.Risk{
+			Score:    int32(details.Risk.Score),
+			Decision: details.Risk.Decision,
+			Rules:    details.Risk.Rules,
+		}
+	}
+	if details.FX != nil {
+		message.Fx = &paymentpb.FX{
+			SettlementCurrency: details.FX.SettlementCurrency,
+			SettlementAmount:   int64(details.FX.SettlementAmount),
+			Rate:               details.FX.Rate,
+			RateTimestamp:      timestamppb.New(details.FX.RateTimestamp),
+			RateSource:         details.FX.RateSource,
+		}
+	}
+	return message
+}
+
+func ToPaymentMessages(payments []models.Payment) []*paymentpb.Payment {
+	messages := make([]*paymentpb.Payment, 0, len(payments))
+	for _, payment := range payments {
+		messages = append(messages, ToPaymentMessage(payment))
+	}
+	return messages
+}
+
+func ToPaginationMessage(pagination *api_response.PaginationResponse) *paymentpb.Pagination {
+	return &paymentpb.Pagination{
+		TotalPage:    int32(pagination.TotalPage),
+		ItemsPerPage: int32(pagination.ItemsPerPage),
+		CurrentPage:  int32(pagination.CurrentPage),
+		TotalItems:   int32(pagination.TotalItems),
+	}
+}
+
+func toRefundMessages(refunds []res.Refund) []*paymentpb.Refund {
+	messages := make([]*paymentpb.Refund, 0, len(refunds))
+	for _, refund := range refunds {
+		messages = append(messages, &paymentpb.Refund{
+			Id:        refund.Id,
+			Amount:    int64(refund.Amount),
+			CreatedAt: timestamppb.New(refund.CreatedAt),
+		})
+	}
+	return messages
+}
@@ -0,0 +1,70 @@
+package mapper
+
+import (
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/res"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/models"
+)
+
+func ToPaymentDetailsV2Res(payment models.Payment) res.PaymentDetailsV2 {
+	return res.PaymentDetailsV2{
+		Id:         payment.Id,
+		Status:     payment.Status,
+		CustomerId: payment.CustomerId,
+		ReasonCode: payment.ReasonCode,
+		Card: res.CardV2{
+			Last4:       payment.CardLast4,
+			Fingerprint: payment.CardFingerprint,
+			Bin:         ToBinRes(payment.Bin),
+			ExpiryMonth: payment.ExpirationMonth,
+			ExpiryYear:  payment.ExpirationYear,
+		},
+		Amount:            toMoneyV2Res(payment.Amount, payment.CurrencyCode),
+		Captured:          toMoneyV2Res(payment.CapturedAmount, payment.CurrencyCode),
+		Refunded:          toMoneyV2Res(payment.RefundedAmount, payment.CurrencyCode),
+		Acquirer:          payment.Acquirer,
+		Refunds:           toRefundsV2Res(payment.Refunds, payment.CurrencyCode),
+		ThreeDS:           ToThreeDSRes(payment.ThreeDS),
+		Risk:              ToRiskRes(payment.Risk),
+		FX:                toFXV2Res(payment.FX),
+		MandateId:         payment.MandateId,
+		MerchantInitiated: payment.MerchantInitiated,
+		CreatedAt:         payment.CreatedAt,
+		UpdatedAt:         payment.UpdatedAt,
+	}
+}
+
+func ToPaymentsV2Res(payments []models.Payment) []res.PaymentDetailsV2 {
+	paymentsRes := make([]res.PaymentDetailsV2, 0, len(payments))
+	for _, payment := range payments {
+		paymentsRes = append(paymentsRes, ToPaymentDetailsV2Res(payment))
+	}
+	return paymentsRes
+}
+
+func toMoneyV2Res(value int, currency string) res.MoneyV2 {
+	return res.MoneyV2{Value: value, Currency: currency}
+}
+
+func toRefundsV2Res(refunds []models.Refund, currency string) []res.RefundV2 {
+	refundsRes := make([]res.RefundV2, 0, len(refunds))
+	for _, refund := range refunds {
+		refundsRes = append(refundsRes, res.RefundV2{
+			Id:        refund.Id,
+			Amount:    toMoneyV2Res(refund.Amount, currency),
+			CreatedAt: refund.CreatedAt,
+		})
+	}
+	return refundsRes
+}
+
+func toFXV2Res(conversion *models.FXConversion) *res.FXV2 {
+	if conversion == nil {
+		return nil
+	}
+	return &res.FXV2{
+		Settlement:    toMoneyV2Res(conversion.SettlementAmount, conversion.SettlementCurrency),
+		Rate:          conversion.Rate,
+		RateTimestamp: conversion.RateTimestamp,
+		RateSource:    conversion.RateSource,
+	}
+}
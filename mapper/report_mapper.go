@@ -0,0 +1,51 @@
+package mapper
+
+import (
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/res"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/reports"
+)
+
+func ToReportRes(report reports.Report) res.Report {
+	rows := make([]res.ReportRow, 0, len(report.Rows))
+	for _, row := range report.Rows {
+		rows = append(rows, res.ReportRow{
+			Currency:    row.Currency,
+			Gross:       row.Gross,
+			Fees:        row.Fees,
+			Refunds:     row.Refunds,
+			Net:         row.Net,
+			Captures:    row.Captures,
+			RefundCount: row.RefundCount,
+		})
+	}
+	return res.Report{
+		Id:          report.Id,
+		Date:        report.Date,
+		Rows:        rows,
+		GeneratedAt: report.GeneratedAt,
+	}
+}
+
+func ToReportsRes(generated []reports.Report) []res.Report {
+	reportsRes := make([]res.Report, 0, len(generated))
+	for _, report := range generated {
+		reportsRes = append(reportsRes, ToReportRes(report))
+	}
+	return reportsRes
+}
+
+func ToReconciliationRes(reconciliation reports.Reconciliation) res.Reconciliation {
+	discrepancies := make([]res.Discrepancy, 0, len(reconciliation.Discrepancies))
+	for _, discrepancy := range reconciliation.Discrepancies {
+		discrepancies = append(discrepancies, res.Discrepancy{
+			PaymentId: discrepancy.PaymentId,
+			Code:      discrepancy.Code,
+			Expected:  discrepancy.Expected,
+			Actual:    discrepancy.Actual,
+		})
+	}
+	return res.Reconciliation{
+		Matched:       reconciliation.Matched,
+		Discrepancies: discrepancies,
+	}
+}
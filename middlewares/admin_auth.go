@@ -0,0 +1,26 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"github.com/gin-gonic/gin"
+)
+
+const ADMIN_API_KEY_HEADER = "X-Admin-Api-Key"
+
+// AdminAuth admits requests whose X-Admin-Api-Key header matches apiKey.
+// Without an apiKey every request is refused, so the admin API is never
+// left open by a missing setting.
+func AdminAuth(apiKey string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		given := context.GetHeader(ADMIN_API_KEY_HEADER)
+		if apiKey == "" || subtle.ConstantTimeCompare([]byte(given), []byte(apiKey)) != 1 {
+			errRes := api_response.BuildErrorResponse(http.StatusUnauthorized, "Unauthorized", "a valid "+ADMIN_API_KEY_HEADER+" header is required", nil)
+			context.AbortWithStatusJSON(errRes.Code, errRes)
+			return
+		}
+		context.Next()
+	}
+}
@@ -0,0 +1,177 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/clock"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/idempotency"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const (
+	IDEMPOTENCY_KEY_HEADER     = "Idempotency-Key"
+	IDEMPOTENT_REPLAYED_HEADER = "Idempotent-Replayed"
+
+	IDEMPOTENCY_KEY_METADATA     = "idempotency-key"
+	IDEMPOTENT_REPLAYED_METADATA = "idempotent-replayed"
+)
+
+// Idempotency answers a request that repeats the Idempotency-Key of an
+// earlier one with the earlier response instead of processing it again.
+// Keys are scoped to the merchant MerchantAuth admitted and to the route,
+// and reusing one with a different body is rejected with 422.
+func Idempotency(store *idempotency.Store, clock clock.Clock) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		key := context.GetHeader(IDEMPOTENCY_KEY_HEADER)
+		if key == "" {
+			context.Next()
+			return
+		}
+		body, err := io.ReadAll(context.Request.Body)
+		if err != nil {
+			errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", err.Error(), nil)
+			context.AbortWithStatusJSON(errRes.Code, errRes)
+			return
+		}
+		context.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scopedKey := MerchantIdFrom(context) + " " + context.Request.Method + " " + context.Request.URL.Path + " " + key
+		digest := sha256.Sum256(body)
+		stored, err := store.Begin(scopedKey, hex.EncodeToString(digest[:]), clock.Now())
+		switch {
+		case errors.Is(err, idempotency.ErrKeyReused):
+			errRes := api_response.BuildErrorResponse(http.StatusUnprocessableEntity, "Unprocessable Entity", err.Error(), nil)
+			context.AbortWithStatusJSON(errRes.Code, errRes)
+			return
+		case errors.Is(err, idempotency.ErrInProgress):
+			errRes := api_response.BuildErrorResponse(http.StatusConflict, "Conflict", err.Error(), nil)
+			context.AbortWithStatusJSON(errRes.Code, errRes)
+			return
+		case stored != nil:
+			context.Header(IDEMPOTENT_REPLAYED_HEADER, "true")
+			context.Data(stored.Status, stored.ContentType, stored.Body)
+			context.Abort()
+			return
+		}
+
+		recorder := &teeWriter{ResponseWriter: context.Writer}
+		context.Writer = recorder
+		defer func() {
+			if recorder.Written() {
+				store.Complete(scopedKey, idempotency.Response{
+					Status:      recorder.Status(),
+					ContentType: recorder.Header().Get("Content-Type"),
+					Body:        recorder.body.Bytes(),
+				})
+			} else {
+				store.Release(scopedKey)
+			}
+		}()
+		context.Next()
+	}
+}
+
+// IdempotencyInterceptor is Idempotency for the gRPC methods given, reading
+// the key from idempotency-key metadata. A replayed call returns the first
+// call's message or error, with idempotent-replayed metadata.
+func IdempotencyInterceptor(store *idempotency.Store, clock clock.Clock, methods ...string) grpc.UnaryServerInterceptor {
+	idempotent := methodSet(methods)
+	return func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		key := firstMetadata(md, IDEMPOTENCY_KEY_METADATA)
+		message, ok := request.(proto.Message)
+		if !idempotent[info.FullMethod] || key == "" || !ok {
+			return handler(ctx, request)
+		}
+		body, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		scopedKey := MerchantIdFromContext(ctx) + " " + info.FullMethod + " " + key
+		digest := sha256.Sum256(body)
+		stored, err := store.Begin(scopedKey, hex.EncodeToString(digest[:]), clock.Now())
+		switch {
+		case errors.Is(err, idempotency.ErrKeyReused):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, idempotency.ErrInProgress):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		case stored != nil:
+			grpc.SetHeader(ctx, metadata.Pairs(IDEMPOTENT_REPLAYED_METADATA, "true"))
+			return replayGRPC(stored)
+		}
+
+		response, err := handler(ctx, request)
+		recorded, recordErr := recordGRPC(response, err)
+		if recordErr != nil {
+			store.Release(scopedKey)
+		} else {
+			store.Complete(scopedKey, recorded)
+		}
+		return response, err
+	}
+}
+
+// recordGRPC stores a call's outcome as a Response: its status code, and
+// either the response message with its name as the content type or the
+// error message.
+func recordGRPC(response interface{}, err error) (idempotency.Response, error) {
+	if err != nil {
+		failure := status.Convert(err)
+		return idempotency.Response{Status: int(failure.Code()), Body: []byte(failure.Message())}, nil
+	}
+	message, ok := response.(proto.Message)
+	if !ok {
+		return idempotency.Response{}, errors.New("response is not a protobuf message")
+	}
+	body, err := proto.Marshal(message)
+	if err != nil {
+		return idempotency.Response{}, err
+	}
+	return idempotency.Response{Status: int(codes.OK), ContentType: string(proto.MessageName(message)), Body: body}, nil
+}
+
+func replayGRPC(stored *idempotency.Response) (interface{}, error) {
+	if codes.Code(stored.Status) != codes.OK {
+		return nil, status.Error(codes.Code(stored.Status), string(stored.Body))
+	}
+	messageType, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(stored.ContentType))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	message := messageType.New().Interface()
+	if err := proto.Unmarshal(stored.Body, message); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return message, nil
+}
+
+// teeWriter keeps a copy of the response body while writing it.
+type teeWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *teeWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *teeWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,119 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	MERCHANT_ID_HEADER   = "X-Merchant-Id"
+	MERCHANT_ID_METADATA = "x-merchant-id"
+	API_KEY_METADATA     = "x-api-key"
+	merchantIdKey        = "merchantId"
+)
+
+var (
+	errMerchantIdMissing  = errors.New("a " + MERCHANT_ID_HEADER + " header is required")
+	errMerchantKeyInvalid = errors.New("a valid merchant " + API_KEY_HEADER + " header is required")
+	errMerchantMismatch   = errors.New(MERCHANT_ID_HEADER + " does not match the merchant of the API key")
+)
+
+type merchantContextKey struct{}
+
+// MerchantAuth works out which merchant a request acts for and refuses
+// requests it cannot tie to one. apiKeys maps each merchant API key to its
+// merchant: when it is set the X-Api-Key or bearer token decides, and an
+// X-Merchant-Id naming another merchant is refused. Without apiKeys the
+// X-Merchant-Id header is trusted as sent, which is only safe behind a
+// proxy that authenticates merchants and sets it.
+func MerchantAuth(apiKeys map[string]string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		merchantId, err := resolveMerchant(apiKeys, apiKeyFrom(context), context.GetHeader(MERCHANT_ID_HEADER))
+		if err != nil {
+			code, title := http.StatusUnauthorized, "Unauthorized"
+			if errors.Is(err, errMerchantMismatch) {
+				code, title = http.StatusForbidden, "Forbidden"
+			}
+			errRes := api_response.BuildErrorResponse(code, title, err.Error(), nil)
+			context.AbortWithStatusJSON(errRes.Code, errRes)
+			return
+		}
+		context.Set(merchantIdKey, merchantId)
+		context.Next()
+	}
+}
+
+// MerchantIdFrom returns the merchant MerchantAuth admitted the request for.
+func MerchantIdFrom(context *gin.Context) string {
+	return context.GetString(merchantIdKey)
+}
+
+// MerchantAuthInterceptor is MerchantAuth for gRPC calls, reading the
+// x-api-key (or authorization bearer) and x-merchant-id metadata.
+func MerchantAuthInterceptor(apiKeys map[string]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		merchantId, err := resolveMerchant(apiKeys, apiKeyFromMetadata(md), firstMetadata(md, MERCHANT_ID_METADATA))
+		if err != nil {
+			code := codes.Unauthenticated
+			if errors.Is(err, errMerchantMismatch) {
+				code = codes.PermissionDenied
+			}
+			return nil, status.Error(code, err.Error())
+		}
+		return handler(context.WithValue(ctx, merchantContextKey{}, merchantId), request)
+	}
+}
+
+// MerchantIdFromContext returns the merchant MerchantAuthInterceptor
+// admitted the call for.
+func MerchantIdFromContext(ctx context.Context) string {
+	merchantId, _ := ctx.Value(merchantContextKey{}).(string)
+	return merchantId
+}
+
+func resolveMerchant(apiKeys map[string]string, apiKey string, merchantId string) (string, error) {
+	if len(apiKeys) == 0 {
+		if merchantId == "" {
+			return "", errMerchantIdMissing
+		}
+		return merchantId, nil
+	}
+	keyMerchant := ""
+	for key, merchant := range apiKeys {
+		if subtle.ConstantTimeCompare([]byte(apiKey), []byte(key)) == 1 {
+			keyMerchant = merchant
+		}
+	}
+	if keyMerchant == "" {
+		return "", errMerchantKeyInvalid
+	}
+	if merchantId != "" && merchantId != keyMerchant {
+		return "", errMerchantMismatch
+	}
+	return keyMerchant, nil
+}
+
+func apiKeyFromMetadata(md metadata.MD) string {
+	if apiKey := firstMetadata(md, API_KEY_METADATA); apiKey != "" {
+		return apiKey
+	}
+	return strings.TrimPrefix(firstMetadata(md, "authorization"), "Bearer ")
+}
+
+func firstMetadata(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
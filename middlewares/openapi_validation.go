@@ -0,0 +1,173 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	openapi3filter.RegisterBodyDecoder("text/csv", openapi3filter.FileBodyDecoder)
+}
+
+// OpenAPIValidationConfig turns on checking responses as well as requests.
+// Responses are buffered to be checked, so it is meant for tests.
+type OpenAPIValidationConfig struct {
+	ValidateResponses bool
+}
+
+// OpenAPIValidation rejects requests that do not match the spec with 400.
+// Routes missing from the spec are not checked. With ValidateResponses, a
+// JSON response that does not match the spec is replaced by a 500.
+func OpenAPIValidation(spec *openapi3.T, config OpenAPIValidationConfig) (gin.HandlerFunc, error) {
+	// Match paths whatever host the gateway is served on.
+	routed := *spec
+	routed.Servers = openapi3.Servers{{URL: "/"}}
+	router, err := gorillamux.NewRouter(&routed)
+	if err != nil {
+		return nil, err
+	}
+	// Merchants are identified by the handlers, and requests are checked as
+	// sent rather than with the spec's defaults filled in.
+	options := &openapi3filter.Options{
+		AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+		SkipSettingDefaults: true,
+	}
+
+	return func(context *gin.Context) {
+		route, pathParams, err := router.FindRoute(context.Request)
+		if err != nil {
+			context.Next()
+			return
+		}
+		input := &openapi3filter.RequestValidationInput{
+			Request:    context.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    options,
+		}
+		if err := openapi3filter.ValidateRequest(context.Request.Context(), input); err != nil {
+			errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", validationMessage(err), nil)
+			context.AbortWithStatusJSON(errRes.Code, errRes)
+			return
+		}
+		if !config.ValidateResponses {
+			context.Next()
+			return
+		}
+
+		writer := context.Writer
+		recorder := &recordingWriter{ResponseWriter: writer, status: http.StatusOK}
+		context.Writer = recorder
+		context.Next()
+		context.Writer = writer
+		if recorder.passthrough {
+			return
+		}
+
+		err = openapi3filter.ValidateResponse(context.Request.Context(), &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 recorder.status,
+			Header:                 writer.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(recorder.body.Bytes())),
+			Options:                options,
+		})
+		if err != nil {
+			log.Printf("%s %s responded outside the API spec: %v", context.Request.Method, route.Path, err)
+			errRes := api_response.BuildErrorResponse(http.StatusInternalServerError, "Internal Server Error", "response does not match the API spec: "+validationMessage(err), nil)
+			content, _ := json.Marshal(errRes)
+			writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+			writer.WriteHeader(errRes.Code)
+			writer.Write(content)
+			return
+		}
+		writer.WriteHeader(recorder.status)
+		writer.Write(recorder.body.Bytes())
+	}, nil
+}
+
+// validationMessage names the offending field without dumping its schema.
+func validationMessage(err error) string {
+	message := err.Error()
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		// allOf and oneOf failures wrap the field that actually failed.
+		for inner := (*openapi3.SchemaError)(nil); errors.As(schemaErr.Origin, &inner); {
+			schemaErr = inner
+		}
+		message = schemaErr.Reason
+		if pointer := schemaErr.JSONPointer(); len(pointer) > 0 {
+			message = strings.Join(pointer, ".") + ": " + message
+		}
+	}
+	var requestErr *openapi3filter.RequestError
+	if errors.As(err, &requestErr) && requestErr.Parameter != nil {
+		message = "parameter " + requestErr.Parameter.Name + ": " + message
+	}
+	return message
+}
+
+// recordingWriter holds back a JSON response until it has been checked.
+// Other content, such as CSV downloads, is written straight through.
+type recordingWriter struct {
+	gin.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	passthrough bool
+}
+
+func (w *recordingWriter) WriteHeader(code int) {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+}
+
+func (w *recordingWriter) WriteHeaderNow() {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	if !w.passthrough && !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		w.passthrough = true
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if w.passthrough {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.body.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *recordingWriter) Status() int {
+	if w.passthrough {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *recordingWriter) Written() bool {
+	return w.ResponseWriter.Written() || w.body.Len() > 0
+}
+
+func (w *recordingWriter) Flush() {
+	if w.passthrough {
+		w.ResponseWriter.Flush()
+	}
+}
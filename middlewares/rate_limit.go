@@ -0,0 +1,232 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/cards"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/clock"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ratelimit"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const API_KEY_HEADER = "X-Api-Key"
+
+// MAX_RATE_LIMITED_BODY bounds the body read to find the card being paid
+// with.
+const MAX_RATE_LIMITED_BODY = 1 << 20
+
+// RateLimitConfig sets a limit per API key, client IP and card fingerprint.
+// A nil limit is not enforced. The API key is an unauthenticated header, so
+// a client can dodge PerAPIKey by sending a new key with every request;
+// PerIP is what bounds such a client.
+type RateLimitConfig struct {
+	PerAPIKey *ratelimit.Limit
+	PerIP     *ratelimit.Limit
+	PerCard   *ratelimit.Limit
+	// Clock defaults to the system clock.
+	Clock clock.Clock
+}
+
+type rateLimitKey struct {
+	name  string
+	value string
+	limit *ratelimit.Limit
+}
+
+// RateLimit rejects requests over any configured limit with 429. A request
+// only uses up its limits when it is within all of them. The RateLimit-*
+// headers describe the most restrictive limit checked.
+func RateLimit(store ratelimit.Store, config RateLimitConfig) gin.HandlerFunc {
+	if config.Clock == nil {
+		config.Clock = clock.Real{}
+	}
+	return func(context *gin.Context) {
+		keys := []rateLimitKey{
+			{name: "ip", value: context.ClientIP(), limit: config.PerIP},
+			{name: "api_key", value: apiKeyFrom(context), limit: config.PerAPIKey},
+		}
+		if config.PerCard != nil {
+			fingerprint, err := cardFingerprintFrom(context)
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				errRes := api_response.BuildErrorResponse(http.StatusRequestEntityTooLarge, "Request Entity Too Large", err.Error(), nil)
+				context.AbortWithStatusJSON(errRes.Code, errRes)
+				return
+			}
+			keys = append(keys, rateLimitKey{name: "card", value: fingerprint, limit: config.PerCard})
+		}
+
+		result, exceeded := takeRateLimits(store, config, keys)
+		if result == nil {
+			context.Next()
+			return
+		}
+		setRateLimitHeaders(context, *result)
+		if exceeded != "" {
+			context.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			errRes := api_response.BuildErrorResponse(http.StatusTooManyRequests, "Too Many Requests", "rate limit exceeded for "+exceeded, nil)
+			context.AbortWithStatusJSON(errRes.Code, errRes)
+			return
+		}
+		context.Next()
+	}
+}
+
+// takeRateLimits takes a token from each configured limit of keys, unless
+// one of them is exhausted. It returns the result to report, which is the
+// exhausted limit's or else the most restrictive one, and the name of the
+// exhausted limit. The result is nil when no limit was checked.
+func takeRateLimits(store ratelimit.Store, config RateLimitConfig, keys []rateLimitKey) (*ratelimit.Result, string) {
+	checked := make([]rateLimitKey, 0, len(keys))
+	buckets := make([]ratelimit.Bucket, 0, len(keys))
+	for _, key := range keys {
+		if key.limit == nil || key.value == "" {
+			continue
+		}
+		checked = append(checked, key)
+		buckets = append(buckets, ratelimit.Bucket{Key: key.name + ":" + key.value, Limit: *key.limit})
+	}
+	if len(buckets) == 0 {
+		return nil, ""
+	}
+	results, err := store.TakeAll(buckets, config.Clock.Now())
+	if err != nil {
+		log.Printf("rate limit store unavailable, allowing request: %v", err)
+		return nil, ""
+	}
+
+	reported := results[0]
+	for i, result := range results {
+		if !result.Allowed {
+			return &result, checked[i].name
+		}
+		if result.Remaining < reported.Remaining {
+			reported = result
+		}
+	}
+	return &reported, ""
+}
+
+// RateLimitInterceptor is RateLimit for the gRPC methods given, rejecting
+// calls over a limit with ResourceExhausted and a retry-after header. The
+// card is read from requests with a card number or saved payment method.
+func RateLimitInterceptor(store ratelimit.Store, config RateLimitConfig, methods ...string) grpc.UnaryServerInterceptor {
+	if config.Clock == nil {
+		config.Clock = clock.Real{}
+	}
+	limited := methodSet(methods)
+	return func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !limited[info.FullMethod] {
+			return handler(ctx, request)
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		keys := []rateLimitKey{
+			{name: "ip", value: PeerIP(ctx), limit: config.PerIP},
+			{name: "api_key", value: apiKeyFromMetadata(md), limit: config.PerAPIKey},
+		}
+		if payment, ok := request.(interface {
+			GetCardNumber() string
+			GetPaymentMethodId() string
+		}); ok {
+			keys = append(keys, rateLimitKey{name: "card", value: cardKey(payment.GetCardNumber(), payment.GetPaymentMethodId()), limit: config.PerCard})
+		}
+		result, exceeded := takeRateLimits(store, config, keys)
+		if exceeded != "" {
+			grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(ceilSeconds(result.RetryAfter))))
+			return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded for "+exceeded)
+		}
+		return handler(ctx, request)
+	}
+}
+
+func methodSet(methods []string) map[string]bool {
+	set := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		set[method] = true
+	}
+	return set
+}
+
+// PeerIP returns the IP address a gRPC call came from.
+func PeerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
+func setRateLimitHeaders(context *gin.Context, result ratelimit.Result) {
+	context.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+	context.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	context.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+}
+
+func apiKeyFrom(context *gin.Context) string {
+	if apiKey := context.GetHeader(API_KEY_HEADER); apiKey != "" {
+		return apiKey
+	}
+	return strings.TrimPrefix(context.GetHeader("Authorization"), "Bearer ")
+}
+
+// cardFingerprintFrom reads the card number from a JSON body of at most
+// MAX_RATE_LIMITED_BODY bytes and puts the body back for the handler. It
+// takes card_number from v1 bodies and card.number from v2 ones. Payments
+// with a saved card are keyed by its token.
+func cardFingerprintFrom(context *gin.Context) (string, error) {
+	if context.Request.Body == nil {
+		return "", nil
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(context.Writer, context.Request.Body, MAX_RATE_LIMITED_BODY))
+	context.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	var payload struct {
+		CardNumber string `json:"card_number"`
+		Card       *struct {
+			Number string `json:"number"`
+		} `json:"card"`
+		PaymentMethodId string `json:"payment_method_id"`
+	}
+	if json.Unmarshal(body, &payload) != nil {
+		return "", nil
+	}
+	cardNumber := payload.CardNumber
+	if cardNumber == "" && payload.Card != nil {
+		cardNumber = payload.Card.Number
+	}
+	return cardKey(cardNumber, payload.PaymentMethodId), nil
+}
+
+func cardKey(cardNumber string, paymentMethodId string) string {
+	if cardNumber == "" {
+		return paymentMethodId
+	}
+	return cards.Fingerprint(cardNumber)
+}
+
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
@@ -0,0 +1,92 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"github.com/gin-gonic/gin"
+)
+
+const API_VERSION_HEADER = "Api-Version"
+
+var versionedPath = regexp.MustCompile(`^/api/v\d+/`)
+
+// VersionConfig lists the API versions a client can ask for and the one
+// used when it does not.
+type VersionConfig struct {
+	Default   string
+	Supported []string
+}
+
+// NegotiateVersion serves unversioned paths such as /api/payments from the
+// version named in the Api-Version header, or the default version. It wraps
+// the router and rewrites the path before next routes the request, so the
+// request is routed, and goes through the global middlewares, only once.
+func NegotiateVersion(next http.Handler, config VersionConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if !strings.HasPrefix(path, "/api/") || versionedPath.MatchString(path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		version := r.Header.Get(API_VERSION_HEADER)
+		if version == "" {
+			version = config.Default
+		}
+		if !supportedVersion(config.Supported, version) {
+			errRes := api_response.BuildErrorResponse(http.StatusBadRequest, "Bad Request", "unsupported API version "+strconv.Quote(version)+", use one of "+strings.Join(config.Supported, ", "), nil)
+			body, err := json.Marshal(errRes)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(errRes.Code)
+			w.Write(body)
+			return
+		}
+		w.Header().Set(API_VERSION_HEADER, version)
+		w.Header().Add("Vary", API_VERSION_HEADER)
+		versioned := r.Clone(r.Context())
+		versioned.URL.Path = "/api/v" + version + strings.TrimPrefix(path, "/api")
+		versioned.URL.RawPath = ""
+		next.ServeHTTP(w, versioned)
+	})
+}
+
+func supportedVersion(supported []string, version string) bool {
+	for _, candidate := range supported {
+		if candidate == version {
+			return true
+		}
+	}
+	return false
+}
+
+// DeprecationConfig describes a deprecated route group. A zero Sunset or an
+// empty Successor is left out of the response.
+type DeprecationConfig struct {
+	DeprecatedAt time.Time
+	Sunset       time.Time
+	Successor    string
+}
+
+// Deprecation marks every response with the Deprecation (RFC 9745) and
+// Sunset (RFC 8594) headers, and links to the successor version.
+func Deprecation(config DeprecationConfig) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		context.Header("Deprecation", "@"+strconv.FormatInt(config.DeprecatedAt.Unix(), 10))
+		if !config.Sunset.IsZero() {
+			context.Header("Sunset", config.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if config.Successor != "" {
+			context.Header("Link", "<"+config.Successor+`>; rel="successor-version"`)
+		}
+		context.Next()
+	}
+}
@@ -1,12 +1,99 @@
 package models
 
+import "time"
+
 type Payment struct {
 	Id              string
+	MerchantId      string
+	CustomerId      string
 	Status          string
-	CardNumber      string
+	ReasonCode      string
+	CardToken       string
+	CardLast4       string
+	CardFingerprint string
 	ExpirationMonth int
 	ExpirationYear  int
 	CurrencyCode    string
 	Amount          int
-	cvv             string
+	Acquirer        string
+	CapturedAmount  int
+	RefundedAmount  int
+	Refunds         []Refund
+	ThreeDS         *ThreeDSecure
+	Risk            *RiskAssessment
+	FX              *FXConversion
+	// MandateId is the mandate set up by, or charged on, this payment.
+	MandateId         string
+	MerchantInitiated bool
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	StatusHistory     []StatusTransition
+	cvv               string
+	// Version counts the events applied to the payment, so it changes
+	// whenever the payment does.
+	Version int
+	// Bin describes the card's issuer, looked up from its BIN when the
+	// payment was requested. It is nil when the BIN is unknown.
+	Bin *BinDetails
+}
+
+type StatusTransition struct {
+	From      string
+	To        string
+	Timestamp time.Time
+	Reason    string
+	Actor     string
+}
+
+type BinDetails struct {
+	Scheme     string
+	Issuer     string
+	Country    string
+	Funding    string
+	Commercial bool
+}
+
+type RiskAssessment struct {
+	Score    int
+	Decision string
+	Rules    []string
+}
+
+// FXConversion converts the presentment amount into the merchant's
+// settlement currency at the rate quoted when the payment was authorized.
+type FXConversion struct {
+	SettlementCurrency string
+	SettlementAmount   int
+	Rate               float64
+	RateTimestamp      time.Time
+	RateSource         string
+}
+
+type ThreeDSecure struct {
+	ChallengeId    string
+	ChallengeURL   string
+	Status         string
+	ECI            string
+	CAVV           string
+	LiabilityShift bool
+}
+
+type Refund struct {
+	Id        string
+	Amount    int
+	CreatedAt time.Time
+}
+
+// TransitionTo moves the payment to status and appends the change to its
+// status history. Existing history entries are never modified.
+func (payment *Payment) TransitionTo(status string, reason string, actor string, at time.Time) {
+	payment.StatusHistory = append(payment.StatusHistory, StatusTransition{
+		From:      payment.Status,
+		To:        status,
+		Timestamp: at,
+		Reason:    reason,
+		Actor:     actor,
+	})
+	payment.Status = status
+	payment.UpdatedAt = at
 }
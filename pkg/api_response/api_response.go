@@ -16,17 +16,17 @@ type PaginationResponse struct {
 type Response struct {
 	Code    int         `json:"code"` // This is Name
 	Message string      `json:"message"`
-	Errors  []string    `json:"errors"`
-	Data    interface{} `json:"data"`
+	Errors  []string    `json:"errors" extensions:"x-nullable"`
+	Data    interface{} `json:"data" extensions:"x-nullable"`
 }
 
 // swagger:parameters ResponseWithPagination
 type ResponseWithPagination struct {
 	Code       int                 `json:"code"` // This is Name
 	Message    string              `json:"message"`
-	Errors     []string            `json:"errors"`
-	Data       interface{}         `json:"data"`
-	Pagination *PaginationResponse `json:"pagination"`
+	Errors     []string            `json:"errors" extensions:"x-nullable"`
+	Data       interface{}         `json:"data" extensions:"x-nullable"`
+	Pagination *PaginationResponse `json:"pagination" extensions:"x-nullable"`
 }
 type EmptyObj struct {
 }
@@ -0,0 +1,102 @@
+package banksim
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Config tunes the simulator. Latency delays every response and ErrorRate
+// is the fraction of requests, between 0 and 1, answered with a 500.
+type Config struct {
+	Latency   time.Duration
+	ErrorRate float64
+	// Random returns a number in [0, 1); it defaults to math/rand.
+	Random func() float64
+}
+
+type PaymentRequest struct {
+	CardNumber string      `json:"card_number"`
+	ExpiryDate string      `json:"expiry_date"`
+	Currency   string      `json:"currency"`
+	Amount     int         `json:"amount"`
+	CVV        interface{} `json:"cvv"`
+	// StoredCredential is "merchant_initiated" for payments on a stored
+	// card, which are accepted without a CVV.
+	StoredCredential string `json:"stored_credential"`
+}
+
+type PaymentResponse struct {
+	Authorized        bool   `json:"authorized"`
+	AuthorizationCode string `json:"authorization_code"`
+}
+
+type ErrorResponse struct {
+	ErrorMessage string `json:"errorMessage"`
+}
+
+// NewHandler implements the acquiring bank's POST /payments contract:
+// cards ending in an odd digit are authorized, cards ending in an even digit
+// are declined and cards ending in 0 get a 503.
+func NewHandler(config Config) http.Handler {
+	if config.Random == nil {
+		config.Random = rand.Float64
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/payments", func(w http.ResponseWriter, r *http.Request) {
+		if config.Latency > 0 {
+			time.Sleep(config.Latency)
+		}
+		if r.Method != http.MethodPost {
+			writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{ErrorMessage: "method not allowed"})
+			return
+		}
+		if config.ErrorRate > 0 && config.Random() < config.ErrorRate {
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{ErrorMessage: "injected error"})
+			return
+		}
+
+		var request PaymentRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || !request.valid() {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{ErrorMessage: "The request supplied is not supported by the simulator"})
+			return
+		}
+
+		lastDigit := request.CardNumber[len(request.CardNumber)-1]
+		switch {
+		case lastDigit == '0':
+			writeJSON(w, http.StatusServiceUnavailable, ErrorResponse{ErrorMessage: "acquiring bank unavailable"})
+		case (lastDigit-'0')%2 == 1:
+			writeJSON(w, http.StatusOK, PaymentResponse{Authorized: true, AuthorizationCode: uuid.NewString()})
+		default:
+			writeJSON(w, http.StatusOK, PaymentResponse{Authorized: false})
+		}
+	})
+	return mux
+}
+
+// NewServer starts the simulator on a local port. Callers must Close it.
+func NewServer(config Config) *httptest.Server {
+	return httptest.NewServer(NewHandler(config))
+}
+
+func (request PaymentRequest) valid() bool {
+	if request.CardNumber == "" || request.ExpiryDate == "" || request.Currency == "" || request.Amount <= 0 {
+		return false
+	}
+	if request.CVV == nil && request.StoredCredential != "merchant_initiated" {
+		return false
+	}
+	return strings.Trim(request.CardNumber, "0123456789") == ""
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
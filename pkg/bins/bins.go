@@ -0,0 +1,150 @@
+package bins
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	CREDIT  string = "credit"
+	DEBIT          = "debit"
+	PREPAID        = "prepaid"
+)
+
+var columns = []string{"bin", "scheme", "issuer", "country", "funding", "commercial"}
+
+// Info describes the cards issued under a BIN.
+type Info struct {
+	BIN        string
+	Scheme     string
+	Issuer     string
+	Country    string
+	Funding    string
+	Commercial bool
+}
+
+// Lookup resolves a card number to the BIN it was issued under.
+type Lookup interface {
+	Lookup(cardNumber string) (Info, bool)
+}
+
+// Table is a Lookup over a fixed set of BINs. The longest BIN matching a
+// card number wins, so 8-digit BINs can refine a 6-digit range.
+type Table struct {
+	entries map[string]Info
+	lengths []int
+}
+
+func NewTable(entries []Info) *Table {
+	table := &Table{entries: make(map[string]Info, len(entries))}
+	seen := make(map[int]bool)
+	for _, entry := range entries {
+		table.entries[entry.BIN] = entry
+		if !seen[len(entry.BIN)] {
+			seen[len(entry.BIN)] = true
+			table.lengths = append(table.lengths, len(entry.BIN))
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(table.lengths)))
+	return table
+}
+
+func (table *Table) Lookup(cardNumber string) (Info, bool) {
+	for _, length := range table.lengths {
+		if len(cardNumber) < length {
+			continue
+		}
+		if info, ok := table.entries[cardNumber[:length]]; ok {
+			return info, true
+		}
+	}
+	return Info{}, false
+}
+
+func LoadCSV(path string) (*Table, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseCSV(file)
+}
+
+// ParseCSV reads a table whose header names the bin, scheme, issuer,
+// country, funding and commercial columns, in any order.
+func ParseCSV(reader io.Reader) (*Table, error) {
+	records := csv.NewReader(reader)
+	records.TrimLeadingSpace = true
+	header, err := records.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("BIN table is empty")
+		}
+		return nil, err
+	}
+	index := make(map[string]int)
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, column := range columns {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("BIN table has no %s column", column)
+		}
+	}
+
+	var entries []Info
+	for {
+		record, err := records.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := records.FieldPos(0)
+		entry, err := parseEntry(record, index)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return NewTable(entries), nil
+}
+
+func parseEntry(record []string, index map[string]int) (Info, error) {
+	field := func(column string) string {
+		return strings.TrimSpace(record[index[column]])
+	}
+	entry := Info{
+		BIN:     field("bin"),
+		Scheme:  strings.ToLower(field("scheme")),
+		Issuer:  field("issuer"),
+		Country: strings.ToUpper(field("country")),
+		Funding: strings.ToLower(field("funding")),
+	}
+	if len(entry.BIN) < 6 || len(entry.BIN) > 8 || strings.Trim(entry.BIN, "0123456789") != "" {
+		return Info{}, fmt.Errorf("invalid BIN %q, expected 6 to 8 digits", entry.BIN)
+	}
+	if entry.Country != "" && len(entry.Country) != 2 {
+		return Info{}, fmt.Errorf("invalid country %q, expected an ISO 3166 alpha-2 code", entry.Country)
+	}
+	switch entry.Funding {
+	case "", CREDIT, DEBIT, PREPAID:
+	default:
+		return Info{}, fmt.Errorf("invalid funding %q, expected credit, debit or prepaid", entry.Funding)
+	}
+	if commercial := field("commercial"); commercial != "" {
+		parsed, err := strconv.ParseBool(commercial)
+		if err != nil {
+			return Info{}, fmt.Errorf("invalid commercial flag %q", commercial)
+		}
+		entry.Commercial = parsed
+	}
+	return entry, nil
+}
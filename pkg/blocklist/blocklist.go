@@ -0,0 +1,191 @@
+package blocklist
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/eventlog"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ids"
+)
+
+const (
+	CARD_FINGERPRINT string = "card_fingerprint"
+	BIN                     = "bin"
+	IP                      = "ip"
+	EMAIL                   = "email"
+)
+
+const (
+	ENTRY_ADDED   string = "blocklist_entry_added"
+	ENTRY_REMOVED        = "blocklist_entry_removed"
+)
+
+// Entry blocks payments matching Value. IP entries may be a single address
+// or a CIDR range and BIN entries match card numbers by prefix. An entry
+// without ExpiresAt never expires.
+type Entry struct {
+	Id        string
+	Type      string
+	Value     string
+	Reason    string
+	ExpiresAt *time.Time
+	CreatedAt time.Time
+}
+
+func (entry Entry) Active(now time.Time) bool {
+	return entry.ExpiresAt == nil || now.Before(*entry.ExpiresAt)
+}
+
+// ReasonCode is recorded on payments rejected by this entry.
+func (entry Entry) ReasonCode() string {
+	switch entry.Type {
+	case CARD_FINGERPRINT:
+		return "blocked_card"
+	case BIN:
+		return "blocked_bin"
+	case IP:
+		return "blocked_ip"
+	case EMAIL:
+		return "blocked_email"
+	}
+	return "blocked"
+}
+
+type Subject struct {
+	CardFingerprint string
+	CardNumber      string
+	IP              string
+	Email           string
+}
+
+// Blocklist writes every added and removed entry to its log, so that the
+// entries survive a restart.
+type Blocklist struct {
+	mu      sync.RWMutex
+	log     eventlog.Log
+	entries map[string]Entry
+}
+
+// New returns a blocklist that keeps its entries in memory only.
+func New() *Blocklist {
+	return &Blocklist{log: eventlog.NewMemoryLog(), entries: make(map[string]Entry)}
+}
+
+// Open returns a blocklist that writes to log and recovers the entries
+// already in it.
+func Open(log eventlog.Log) (*Blocklist, error) {
+	blocklist := &Blocklist{log: log, entries: make(map[string]Entry)}
+	err := log.Replay(0, func(event eventlog.Event) error {
+		switch event.Type {
+		case ENTRY_ADDED:
+			var entry Entry
+			if err := json.Unmarshal(event.Data, &entry); err != nil {
+				return fmt.Errorf("could not decode blocklist entry %s: %w", event.AggregateId, err)
+			}
+			blocklist.entries[entry.Id] = entry
+		case ENTRY_REMOVED:
+			delete(blocklist.entries, event.AggregateId)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return blocklist, nil
+}
+
+func (blocklist *Blocklist) Add(entry Entry) (Entry, error) {
+	id, err := ids.New(ids.BLOCKLIST_ENTRY)
+	if err != nil {
+		return Entry{}, err
+	}
+	entry.Id = id
+	entry.Value = normalize(entry.Type, entry.Value)
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return Entry{}, err
+	}
+	blocklist.mu.Lock()
+	defer blocklist.mu.Unlock()
+	_, err = blocklist.log.Append(eventlog.Event{AggregateId: id, Type: ENTRY_ADDED, Timestamp: entry.CreatedAt, Data: data})
+	if err != nil {
+		return Entry{}, err
+	}
+	blocklist.entries[id] = entry
+	return entry, nil
+}
+
+// Remove deletes the entry with id at now. It reports false when there is
+// no such entry.
+func (blocklist *Blocklist) Remove(id string, now time.Time) (bool, error) {
+	blocklist.mu.Lock()
+	defer blocklist.mu.Unlock()
+	if _, ok := blocklist.entries[id]; !ok {
+		return false, nil
+	}
+	_, err := blocklist.log.Append(eventlog.Event{AggregateId: id, Type: ENTRY_REMOVED, Timestamp: now})
+	if err != nil {
+		return false, err
+	}
+	delete(blocklist.entries, id)
+	return true, nil
+}
+
+// List returns the entries still active at now, oldest first.
+func (blocklist *Blocklist) List(now time.Time) []Entry {
+	blocklist.mu.RLock()
+	defer blocklist.mu.RUnlock()
+	entries := make([]Entry, 0, len(blocklist.entries))
+	for _, entry := range blocklist.entries {
+		if entry.Active(now) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Id < entries[j].Id })
+	return entries
+}
+
+// Match returns the first active entry blocking subject.
+func (blocklist *Blocklist) Match(subject Subject, now time.Time) (Entry, bool) {
+	for _, entry := range blocklist.List(now) {
+		if entry.matches(subject) {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+func (entry Entry) matches(subject Subject) bool {
+	switch entry.Type {
+	case CARD_FINGERPRINT:
+		return subject.CardFingerprint != "" && entry.Value == subject.CardFingerprint
+	case BIN:
+		return subject.CardNumber != "" && strings.HasPrefix(subject.CardNumber, entry.Value)
+	case EMAIL:
+		return subject.Email != "" && entry.Value == normalize(EMAIL, subject.Email)
+	case IP:
+		ip := net.ParseIP(subject.IP)
+		if ip == nil {
+			return false
+		}
+		if _, network, err := net.ParseCIDR(entry.Value); err == nil {
+			return network.Contains(ip)
+		}
+		blocked := net.ParseIP(entry.Value)
+		return blocked != nil && blocked.Equal(ip)
+	}
+	return false
+}
+
+func normalize(entryType string, value string) string {
+	value = strings.TrimSpace(value)
+	if entryType == EMAIL || entryType == CARD_FINGERPRINT {
+		return strings.ToLower(value)
+	}
+	return value
+}
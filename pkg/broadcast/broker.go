@@ -0,0 +1,134 @@
+package broadcast
+
+import (
+	"sync"
+)
+
+// SUBSCRIBER_BUFFER is how many messages a subscriber may fall behind by
+// before it is dropped.
+const SUBSCRIBER_BUFFER = 64
+
+// Message is published to every subscriber whose filter matches it. Ids
+// must increase with every message published.
+type Message struct {
+	Id         int64
+	MerchantId string
+	PaymentId  string
+	Event      string
+	Data       interface{}
+}
+
+// Broker fans messages out to subscribers and keeps the most recent ones so
+// that a subscriber can resume after the last message it received.
+type Broker struct {
+	mu          sync.Mutex
+	buffer      []Message
+	next        int
+	evicted     int64
+	lastId      int64
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+type Subscription struct {
+	messages chan Message
+	match    func(Message) bool
+}
+
+// Messages is closed when the subscriber is dropped for falling behind, or
+// when the broker is closed.
+func (subscription *Subscription) Messages() <-chan Message {
+	return subscription.messages
+}
+
+// NewBroker keeps the last size messages. from is the id of the last
+// message published before the broker started, so resuming from before it
+// is reported as incomplete.
+func NewBroker(size int, from int64) *Broker {
+	return &Broker{
+		buffer:      make([]Message, 0, size),
+		evicted:     from,
+		lastId:      from,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+func (broker *Broker) Publish(message Message) {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	if broker.closed {
+		return
+	}
+	if cap(broker.buffer) > 0 {
+		if len(broker.buffer) < cap(broker.buffer) {
+			broker.buffer = append(broker.buffer, message)
+		} else {
+			broker.evicted = broker.buffer[broker.next].Id
+			broker.buffer[broker.next] = message
+			broker.next = (broker.next + 1) % cap(broker.buffer)
+		}
+	}
+	broker.lastId = message.Id
+	for subscription := range broker.subscribers {
+		if !subscription.match(message) {
+			continue
+		}
+		select {
+		case subscription.messages <- message:
+		default:
+			broker.drop(subscription)
+		}
+	}
+}
+
+// Subscribe returns the buffered messages after lastId that match, followed
+// by new ones on the subscription. complete is false when messages after
+// lastId have already been evicted, so the backlog may have gaps.
+func (broker *Broker) Subscribe(lastId int64, match func(Message) bool) (subscription *Subscription, backlog []Message, complete bool) {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	subscription = &Subscription{messages: make(chan Message, SUBSCRIBER_BUFFER), match: match}
+	if broker.closed {
+		close(subscription.messages)
+		return subscription, nil, true
+	}
+	for i := range broker.buffer {
+		message := broker.buffer[(broker.next+i)%len(broker.buffer)]
+		if message.Id > lastId && match(message) {
+			backlog = append(backlog, message)
+		}
+	}
+	broker.subscribers[subscription] = struct{}{}
+	return subscription, backlog, lastId >= broker.evicted
+}
+
+func (broker *Broker) Unsubscribe(subscription *Subscription) {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	broker.drop(subscription)
+}
+
+// LastId is the id of the last message published, to subscribe from now.
+func (broker *Broker) LastId() int64 {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	return broker.lastId
+}
+
+// Close ends every subscription and ignores later messages.
+func (broker *Broker) Close() {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	broker.closed = true
+	for subscription := range broker.subscribers {
+		broker.drop(subscription)
+	}
+}
+
+func (broker *Broker) drop(subscription *Subscription) {
+	if _, ok := broker.subscribers[subscription]; !ok {
+		return
+	}
+	delete(broker.subscribers, subscription)
+	close(subscription.messages)
+}
@@ -0,0 +1,37 @@
+package cards
+
+import "strconv"
+
+const (
+	VISA       string = "visa"
+	MASTERCARD        = "mastercard"
+	AMEX              = "amex"
+	DISCOVER          = "discover"
+	UNKNOWN           = "unknown"
+)
+
+// Brand infers the card scheme from the leading digits of the card number.
+func Brand(cardNumber string) string {
+	switch {
+	case hasPrefixInRange(cardNumber, 4, 4, 1):
+		return VISA
+	case hasPrefixInRange(cardNumber, 51, 55, 2), hasPrefixInRange(cardNumber, 2221, 2720, 4):
+		return MASTERCARD
+	case hasPrefixInRange(cardNumber, 34, 34, 2), hasPrefixInRange(cardNumber, 37, 37, 2):
+		return AMEX
+	case hasPrefixInRange(cardNumber, 6011, 6011, 4), hasPrefixInRange(cardNumber, 65, 65, 2):
+		return DISCOVER
+	}
+	return UNKNOWN
+}
+
+func hasPrefixInRange(cardNumber string, from int, to int, length int) bool {
+	if len(cardNumber) < length {
+		return false
+	}
+	prefix, err := strconv.Atoi(cardNumber[:length])
+	if err != nil {
+		return false
+	}
+	return prefix >= from && prefix <= to
+}
@@ -0,0 +1,16 @@
+package cards
+
+import "time"
+
+// ExpiresAt returns the first instant after which a card with the given
+// expiry month and year is no longer valid: midnight at the start of the
+// following month in loc.
+func ExpiresAt(expiryMonth int, expiryYear int, loc *time.Location) time.Time {
+	return time.Date(expiryYear, time.Month(expiryMonth)+1, 1, 0, 0, 0, 0, loc)
+}
+
+// IsExpired reports whether the card is expired at now. A card stays valid
+// through the last day of its expiry month.
+func IsExpired(expiryMonth int, expiryYear int, now time.Time, loc *time.Location) bool {
+	return !now.Before(ExpiresAt(expiryMonth, expiryYear, loc))
+}
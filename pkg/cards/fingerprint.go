@@ -0,0 +1,35 @@
+package cards
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+var (
+	mu             sync.RWMutex
+	fingerprintKey []byte
+)
+
+// Fingerprint returns a stable identifier for a card number that does not
+// reveal the number itself. It is an HMAC keyed with the key installed with
+// SetFingerprintKey, so it cannot be brute-forced from the short space of
+// card numbers without the key.
+func Fingerprint(cardNumber string) string {
+	mu.RLock()
+	mac := hmac.New(sha256.New, fingerprintKey)
+	mu.RUnlock()
+	mac.Write([]byte(cardNumber))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SetFingerprintKey installs the key fingerprints are computed with and
+// returns the previous one. Changing it changes every fingerprint.
+func SetFingerprintKey(key []byte) []byte {
+	mu.Lock()
+	defer mu.Unlock()
+	previous := fingerprintKey
+	fingerprintKey = key
+	return previous
+}
@@ -0,0 +1,10 @@
+package cards
+
+// Last4 returns the last four digits of the card number, the only part of
+// it that may be stored or shown alongside a token.
+func Last4(cardNumber string) string {
+	if len(cardNumber) < 4 {
+		return cardNumber
+	}
+	return cardNumber[len(cardNumber)-4:]
+}
@@ -0,0 +1,44 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+type Clock interface {
+	Now() time.Time
+}
+
+type Real struct{}
+
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// Fake is a Clock that only moves when told to.
+type Fake struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+func (fake *Fake) Now() time.Time {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	return fake.now
+}
+
+func (fake *Fake) Set(now time.Time) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.now = now
+}
+
+func (fake *Fake) Advance(duration time.Duration) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.now = fake.now.Add(duration)
+}
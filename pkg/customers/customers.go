@@ -0,0 +1,160 @@
+package customers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/eventlog"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ids"
+)
+
+const (
+	CUSTOMER_CREATED string = "customer_created"
+	CARD_ATTACHED           = "card_attached"
+)
+
+var (
+	ErrCustomerNotFound      = errors.New("customer not found")
+	ErrPaymentMethodNotFound = errors.New("payment method not found")
+)
+
+type Customer struct {
+	Id             string
+	MerchantId     string
+	Email          string
+	Name           string
+	PaymentMethods []PaymentMethod
+	CreatedAt      time.Time
+}
+
+// PaymentMethod is a card saved on a customer. Its token id is what the
+// shopper pays with; the card number itself stays in the card vault behind
+// CardToken.
+type PaymentMethod struct {
+	Id              string
+	CardToken       string
+	CardLast4       string
+	CardFingerprint string
+	ExpirationMonth int
+	ExpirationYear  int
+	Brand           string
+	CreatedAt       time.Time
+}
+
+// Store writes every created customer and saved card to its log, so that
+// they survive a restart.
+type Store struct {
+	mu        sync.RWMutex
+	log       eventlog.Log
+	customers map[string]Customer
+}
+
+// New returns a store that keeps its customers in memory only.
+func New() *Store {
+	return &Store{log: eventlog.NewMemoryLog(), customers: make(map[string]Customer)}
+}
+
+// Open returns a store that writes to log and recovers the customers
+// already in it.
+func Open(log eventlog.Log) (*Store, error) {
+	store := &Store{log: log, customers: make(map[string]Customer)}
+	err := log.Replay(0, func(event eventlog.Event) error {
+		switch event.Type {
+		case CUSTOMER_CREATED:
+			var customer Customer
+			if err := json.Unmarshal(event.Data, &customer); err != nil {
+				return fmt.Errorf("could not decode customer %s: %w", event.AggregateId, err)
+			}
+			store.customers[customer.Id] = customer
+		case CARD_ATTACHED:
+			var method PaymentMethod
+			if err := json.Unmarshal(event.Data, &method); err != nil {
+				return fmt.Errorf("could not decode card of customer %s: %w", event.AggregateId, err)
+			}
+			if customer, ok := store.customers[event.AggregateId]; ok {
+				customer.PaymentMethods = append(customer.PaymentMethods, method)
+				store.customers[event.AggregateId] = customer
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (store *Store) Create(customer Customer) (Customer, error) {
+	id, err := ids.New(ids.CUSTOMER)
+	if err != nil {
+		return Customer{}, err
+	}
+	customer.Id = id
+	customer.PaymentMethods = nil
+	data, err := json.Marshal(customer)
+	if err != nil {
+		return Customer{}, err
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	_, err = store.log.Append(eventlog.Event{AggregateId: id, Type: CUSTOMER_CREATED, Timestamp: customer.CreatedAt, Data: data})
+	if err != nil {
+		return Customer{}, err
+	}
+	store.customers[id] = customer
+	return customer, nil
+}
+
+// Get returns the customer if it exists and belongs to merchantId.
+func (store *Store) Get(id string, merchantId string) (Customer, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	customer, ok := store.customers[id]
+	if !ok || customer.MerchantId != merchantId {
+		return Customer{}, ErrCustomerNotFound
+	}
+	customer.PaymentMethods = append([]PaymentMethod(nil), customer.PaymentMethods...)
+	return customer, nil
+}
+
+// AttachCard saves a card on the customer under a new token id.
+func (store *Store) AttachCard(customerId string, merchantId string, method PaymentMethod) (PaymentMethod, error) {
+	id, err := ids.NewTokenId()
+	if err != nil {
+		return PaymentMethod{}, err
+	}
+	method.Id = id
+	data, err := json.Marshal(method)
+	if err != nil {
+		return PaymentMethod{}, err
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	customer, ok := store.customers[customerId]
+	if !ok || customer.MerchantId != merchantId {
+		return PaymentMethod{}, ErrCustomerNotFound
+	}
+	_, err = store.log.Append(eventlog.Event{AggregateId: customerId, Type: CARD_ATTACHED, Timestamp: method.CreatedAt, Data: data})
+	if err != nil {
+		return PaymentMethod{}, err
+	}
+	customer.PaymentMethods = append(customer.PaymentMethods, method)
+	store.customers[customerId] = customer
+	return method, nil
+}
+
+func (store *Store) PaymentMethod(customerId string, merchantId string, methodId string) (PaymentMethod, error) {
+	customer, err := store.Get(customerId, merchantId)
+	if err != nil {
+		return PaymentMethod{}, err
+	}
+	for _, method := range customer.PaymentMethods {
+		if method.Id == methodId {
+			return method, nil
+		}
+	}
+	return PaymentMethod{}, ErrPaymentMethodNotFound
+}
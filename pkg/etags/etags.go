@@ -0,0 +1,44 @@
+package etags
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Strong returns the strong entity tag of a resource's representation at
+// version. Representations of the same resource served at the same URL,
+// such as two API versions, need different tags.
+func Strong(representation string, version int) string {
+	return `"` + representation + "-" + strconv.Itoa(version) + `"`
+}
+
+// MatchWeak reports whether an If-None-Match header lists tag, ignoring
+// weak markers as RFC 9110 asks for If-None-Match.
+func MatchWeak(header string, tag string) bool {
+	return match(header, tag, true)
+}
+
+// MatchStrong reports whether an If-Match header lists tag. Weak tags in
+// the header never match.
+func MatchStrong(header string, tag string) bool {
+	return match(header, tag, false)
+}
+
+func match(header string, tag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == strings.TrimPrefix(tag, "W/") {
+			return true
+		}
+	}
+	return false
+}
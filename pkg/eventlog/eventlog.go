@@ -0,0 +1,25 @@
+package eventlog
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Event struct {
+	Sequence    int64           `json:"sequence"`
+	AggregateId string          `json:"aggregate_id"`
+	Type        string          `json:"type"`
+	Timestamp   time.Time       `json:"timestamp"`
+	Actor       string          `json:"actor"`
+	Reason      string          `json:"reason"`
+	Data        json.RawMessage `json:"data,omitempty"`
+}
+
+// Log is an append-only sequence of events. Append assigns the next
+// sequence numbers and writes the events all together or not at all;
+// events are never modified once written.
+type Log interface {
+	Append(events ...Event) ([]Event, error)
+	Replay(afterSequence int64, apply func(Event) error) error
+	LastSequence() int64
+}
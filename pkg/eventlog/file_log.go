@@ -0,0 +1,109 @@
+package eventlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// FileLog stores one JSON encoded event per line. Every append is written
+// in one go and synced to disk before it is acknowledged; one that fails is
+// truncated away so no part of it is replayed.
+type FileLog struct {
+	mu           sync.Mutex
+	path         string
+	file         *os.File
+	lastSequence int64
+}
+
+func OpenFileLog(path string) (*FileLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	log := &FileLog{path: path, file: file}
+	err = log.Replay(0, func(event Event) error {
+		log.lastSequence = event.Sequence
+		return nil
+	})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return log, nil
+}
+
+func (log *FileLog) Append(events ...Event) ([]Event, error) {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+
+	written := make([]Event, 0, len(events))
+	var lines []byte
+	for _, event := range events {
+		event.Sequence = log.lastSequence + int64(len(written)) + 1
+		line, err := json.Marshal(event)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(append(lines, line...), '\n')
+		written = append(written, event)
+	}
+	info, err := log.file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if _, err = log.file.Write(lines); err == nil {
+		err = log.file.Sync()
+	}
+	if err != nil {
+		if truncateErr := log.file.Truncate(info.Size()); truncateErr != nil {
+			return nil, fmt.Errorf("%w, and could not remove the partial write: %v", err, truncateErr)
+		}
+		return nil, err
+	}
+	if len(written) > 0 {
+		log.lastSequence = written[len(written)-1].Sequence
+	}
+	return written, nil
+}
+
+func (log *FileLog) Replay(afterSequence int64, apply func(Event) error) error {
+	file, err := os.Open(log.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return fmt.Errorf("%s:%d: %w", log.path, line, err)
+		}
+		if event.Sequence <= afterSequence {
+			continue
+		}
+		if err := apply(event); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (log *FileLog) LastSequence() int64 {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	return log.lastSequence
+}
+
+func (log *FileLog) Close() error {
+	return log.file.Close()
+}
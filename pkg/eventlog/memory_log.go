@@ -0,0 +1,45 @@
+package eventlog
+
+import "sync"
+
+type MemoryLog struct {
+	mu     sync.RWMutex
+	events []Event
+}
+
+func NewMemoryLog() *MemoryLog {
+	return &MemoryLog{}
+}
+
+func (log *MemoryLog) Append(events ...Event) ([]Event, error) {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	written := make([]Event, 0, len(events))
+	for _, event := range events {
+		event.Sequence = int64(len(log.events)+len(written)) + 1
+		written = append(written, event)
+	}
+	log.events = append(log.events, written...)
+	return written, nil
+}
+
+func (log *MemoryLog) Replay(afterSequence int64, apply func(Event) error) error {
+	log.mu.RLock()
+	events := log.events
+	log.mu.RUnlock()
+	for _, event := range events {
+		if event.Sequence <= afterSequence {
+			continue
+		}
+		if err := apply(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (log *MemoryLog) LastSequence() int64 {
+	log.mu.RLock()
+	defer log.mu.RUnlock()
+	return int64(len(log.events))
+}
@@ -0,0 +1,76 @@
+package fx
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// RatesFile is the format read by File:
+//
+//	{"timestamp": "2024-06-15T16:00:00Z", "source": "ecb", "rates": {"USD/GBP": 0.79}}
+type RatesFile struct {
+	Timestamp time.Time          `json:"timestamp"`
+	Source    string             `json:"source"`
+	Rates     map[string]float64 `json:"rates"`
+}
+
+// File serves the rates in a JSON file and reads it again whenever it
+// changes, so rates can be refreshed without a restart. When a new version
+// cannot be parsed the previous rates are kept.
+type File struct {
+	path     string
+	mu       sync.Mutex
+	modified time.Time
+	rates    *Static
+}
+
+func OpenFile(path string) (*File, error) {
+	file := &File{path: path}
+	if _, err := file.current(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func (file *File) Rate(base string, quote string) (Rate, error) {
+	rates, err := file.current()
+	if err != nil {
+		return Rate{}, err
+	}
+	return rates.Rate(base, quote)
+}
+
+func (file *File) current() (*Static, error) {
+	file.mu.Lock()
+	defer file.mu.Unlock()
+	info, err := os.Stat(file.path)
+	if err != nil {
+		if file.rates != nil {
+			return file.rates, nil
+		}
+		return nil, err
+	}
+	if file.rates != nil && info.ModTime().Equal(file.modified) {
+		return file.rates, nil
+	}
+	content, err := os.ReadFile(file.path)
+	if err != nil {
+		return file.fallback(err)
+	}
+	var ratesFile RatesFile
+	if err := json.Unmarshal(content, &ratesFile); err != nil {
+		return file.fallback(err)
+	}
+	file.rates = NewStatic(ratesFile.Rates, ratesFile.Timestamp, ratesFile.Source)
+	file.modified = info.ModTime()
+	return file.rates, nil
+}
+
+func (file *File) fallback(err error) (*Static, error) {
+	if file.rates != nil {
+		return file.rates, nil
+	}
+	return nil, err
+}
@@ -0,0 +1,102 @@
+package fx
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrRateNotFound = errors.New("no exchange rate available")
+
+// Rate converts one unit of Base into Rate units of Quote, as published by
+// Source at Timestamp.
+type Rate struct {
+	Base      string
+	Quote     string
+	Rate      float64
+	Timestamp time.Time
+	Source    string
+}
+
+// Provider looks up the rate to convert from base to quote.
+type Provider interface {
+	Rate(base string, quote string) (Rate, error)
+}
+
+// minorUnits lists the currencies whose minor unit is not a hundredth.
+var minorUnits = map[string]int{
+	"BHD": 3, "CLP": 0, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0,
+	"KWD": 3, "OMR": 3, "TND": 3, "UGX": 0, "VND": 0, "XAF": 0, "XOF": 0,
+}
+
+// MinorUnits returns the number of decimals in the currency's minor unit.
+func MinorUnits(currency string) int {
+	if units, ok := minorUnits[currency]; ok {
+		return units
+	}
+	return 2
+}
+
+// Convert converts an amount in minor units of rate.Base into minor units
+// of rate.Quote, rounding half away from zero.
+func Convert(amount int, rate Rate) int {
+	scale := math.Pow10(MinorUnits(rate.Quote) - MinorUnits(rate.Base))
+	return int(math.Round(float64(amount) * rate.Rate * scale))
+}
+
+// Static serves a fixed set of rates. The reverse of a configured pair is
+// derived when only one direction is known.
+type Static struct {
+	rates     map[string]float64
+	timestamp time.Time
+	source    string
+}
+
+func NewStatic(rates map[string]float64, timestamp time.Time, source string) *Static {
+	normalized := make(map[string]float64, len(rates))
+	for pair, rate := range rates {
+		normalized[strings.ToUpper(pair)] = rate
+	}
+	return &Static{rates: normalized, timestamp: timestamp, source: source}
+}
+
+func (static *Static) Rate(base string, quote string) (Rate, error) {
+	found := Rate{Base: base, Quote: quote, Timestamp: static.timestamp, Source: static.source}
+	if base == quote {
+		found.Rate = 1
+		return found, nil
+	}
+	if rate, ok := static.rates[base+"/"+quote]; ok && rate > 0 {
+		found.Rate = rate
+		return found, nil
+	}
+	if rate, ok := static.rates[quote+"/"+base]; ok && rate > 0 {
+		found.Rate = 1 / rate
+		return found, nil
+	}
+	return Rate{}, fmt.Errorf("%w for %s to %s", ErrRateNotFound, base, quote)
+}
+
+// ParseRates reads pairs such as "USD/GBP=0.79,EUR/GBP=0.85".
+func ParseRates(value string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pair, rateValue, ok := strings.Cut(entry, "=")
+		if !ok || len(strings.Split(pair, "/")) != 2 {
+			return nil, fmt.Errorf("invalid exchange rate %q, expected BASE/QUOTE=RATE", entry)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(rateValue), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid exchange rate %q", entry)
+		}
+		rates[strings.ToUpper(strings.TrimSpace(pair))] = rate
+	}
+	return rates, nil
+}
@@ -1,11 +1,22 @@
 package http_clients
 
 import (
+	"errors"
+	"fmt"
 	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
 	"github.com/go-resty/resty/v2"
+	"net"
+	"net/http"
 	"os"
+	"time"
 )
 
+const STORED_CREDENTIAL_MERCHANT_INITIATED = "merchant_initiated"
+
+// DEFAULT_TIMEOUT bounds a request to an acquirer configured without a
+// timeout.
+const DEFAULT_TIMEOUT = 10 * time.Second
+
 type AcquiringBankResponse struct {
 	Authorized        bool   `json:"authorized"`
 	AuthorizationCode string `json:"authorization_code"`
@@ -21,26 +32,105 @@ type AuthorizedPaymentResponse struct {
 	Amount              int    `json:"amount"`
 }
 
-func getBankHttpRequest() *resty.Request {
-	return resty.New().SetBaseURL(os.Getenv("ACQUIRING_BANK_BASE_URL")).R().
-		SetHeader("Content-Type", "application/json")
+// ErrOutcomeUnknown is a request the bank may have acted on without the
+// gateway learning its decision, such as one that timed out after being
+// sent. Retrying it could authorize the payment twice.
+var ErrOutcomeUnknown = errors.New("acquiring bank did not return a decision, the payment may have been authorized")
+
+// TransientError reports a failure worth retrying on another acquirer
+// because the bank never accepted the request: it could not be connected to,
+// or answered with a 5xx status and no authorization.
+type TransientError struct {
+	StatusCode int
+	Err        error
+}
+
+func (err *TransientError) Error() string {
+	if err.Err != nil {
+		return err.Err.Error()
+	}
+	return fmt.Sprintf("acquiring bank responded with status %d", err.StatusCode)
+}
 
+func (err *TransientError) Unwrap() error {
+	return err.Err
 }
 
-func AuthorizePayment(cardNumber string, expiryDate string, currency string, amount int, cvv string) (string, error) {
-	var apiResponse *AcquiringBankResponse
+func IsTransient(err error) bool {
+	var transientErr *TransientError
+	return errors.As(err, &transientErr)
+}
 
-	resp, err := getBankHttpRequest().
-		SetBody(map[string]interface{}{
-			"card_number": cardNumber,
-			"expiry_date": expiryDate,
-			"currency":    currency,
-			"amount":      amount,
-			"cvv":         cvv,
-		}).SetResult(&apiResponse).Post("/payments")
+type AcquiringBankClient struct {
+	Name string
+	// BaseURL defaults to ACQUIRING_BANK_BASE_URL when empty.
+	BaseURL string
+	http    *resty.Client
+}
 
+// NewAcquiringBankClient returns a client whose requests are abandoned after
+// timeout, or DEFAULT_TIMEOUT when it is not positive.
+func NewAcquiringBankClient(name string, baseURL string, timeout time.Duration) *AcquiringBankClient {
+	if timeout <= 0 {
+		timeout = DEFAULT_TIMEOUT
+	}
+	return &AcquiringBankClient{
+		Name:    name,
+		BaseURL: baseURL,
+		http:    resty.New().SetTimeout(timeout).SetHeader("Content-Type", "application/json"),
+	}
+}
+
+func (client *AcquiringBankClient) paymentsURL() string {
+	baseURL := client.BaseURL
+	if baseURL == "" {
+		baseURL = os.Getenv("ACQUIRING_BANK_BASE_URL")
+	}
+	return baseURL + "/payments"
+}
+
+func (client *AcquiringBankClient) AuthorizePayment(cardNumber string, expiryDate string, currency string, amount int, cvv string) (string, error) {
+	return client.authorize(map[string]interface{}{
+		"card_number": cardNumber,
+		"expiry_date": expiryDate,
+		"currency":    currency,
+		"amount":      amount,
+		"cvv":         cvv,
+	})
+}
+
+// AuthorizeMerchantInitiated charges a stored card without a CVV, flagging
+// the request as a merchant-initiated stored credential transaction.
+func (client *AcquiringBankClient) AuthorizeMerchantInitiated(cardNumber string, expiryDate string, currency string, amount int) (string, error) {
+	return client.authorize(map[string]interface{}{
+		"card_number":       cardNumber,
+		"expiry_date":       expiryDate,
+		"currency":          currency,
+		"amount":            amount,
+		"stored_credential": STORED_CREDENTIAL_MERCHANT_INITIATED,
+	})
+}
+
+func (client *AcquiringBankClient) authorize(body map[string]interface{}) (string, error) {
+	var apiResponse, failedResponse *AcquiringBankResponse
+
+	resp, err := client.http.R().
+		SetBody(body).SetResult(&apiResponse).SetError(&failedResponse).Post(client.paymentsURL())
+
+	// Only a failed dial proves the request was never sent; a timeout or a
+	// connection dropped later may have reached the bank.
 	if err != nil {
-		return "", err
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return "", &TransientError{Err: err}
+		}
+		return "", fmt.Errorf("%w: %w", ErrOutcomeUnknown, err)
+	}
+	if resp.StatusCode() >= http.StatusInternalServerError {
+		if failedResponse != nil && (failedResponse.Authorized || failedResponse.AuthorizationCode != "") {
+			return "", fmt.Errorf("%w: status %d with an authorization", ErrOutcomeUnknown, resp.StatusCode())
+		}
+		return "", &TransientError{StatusCode: resp.StatusCode()}
 	}
 	if resp.StatusCode() != 200 {
 
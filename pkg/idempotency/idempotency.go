@@ -0,0 +1,84 @@
+package idempotency
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInProgress = errors.New("a request with this idempotency key is in progress")
+	ErrKeyReused  = errors.New("idempotency key was already used with a different request")
+)
+
+// Response is what the first request with a key was answered with.
+type Response struct {
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+type entry struct {
+	fingerprint string
+	response    *Response
+	expiresAt   time.Time
+}
+
+// Store remembers the response to each idempotency key for ttl so that a
+// retried request is answered without being processed again.
+type Store struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*entry
+}
+
+func NewStore(ttl time.Duration) *Store {
+	return &Store{ttl: ttl, entries: make(map[string]*entry)}
+}
+
+// Begin claims key for a request identified by fingerprint. It returns the
+// stored response when a request with the key already completed.
+func (store *Store) Begin(key string, fingerprint string, now time.Time) (*Response, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.evict(now)
+	existing, ok := store.entries[key]
+	if !ok {
+		store.entries[key] = &entry{fingerprint: fingerprint, expiresAt: now.Add(store.ttl)}
+		return nil, nil
+	}
+	if existing.fingerprint != fingerprint {
+		return nil, ErrKeyReused
+	}
+	if existing.response == nil {
+		return nil, ErrInProgress
+	}
+	return existing.response, nil
+}
+
+// Complete stores the response to the request that claimed key.
+func (store *Store) Complete(key string, response Response) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if existing, ok := store.entries[key]; ok {
+		existing.response = &response
+	}
+}
+
+// Release forgets a key whose request was never answered so it can be
+// retried.
+func (store *Store) Release(key string) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if existing, ok := store.entries[key]; ok && existing.response == nil {
+		delete(store.entries, key)
+	}
+}
+
+func (store *Store) evict(now time.Time) {
+	for key, existing := range store.entries {
+		if !now.Before(existing.expiresAt) {
+			delete(store.entries, key)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package ids
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type Prefix string
+
+const (
+	PAYMENT Prefix = "pay_"
+	REFUND  Prefix = "ref_"
+	TOKEN   Prefix = "tok_"
+
+	BLOCKLIST_ENTRY Prefix = "blk_"
+	MANDATE         Prefix = "mdt_"
+	SUBSCRIPTION    Prefix = "sub_"
+	CUSTOMER        Prefix = "cus_"
+	REPORT          Prefix = "rpt_"
+	CARD            Prefix = "crd_"
+)
+
+var ErrInvalidId = errors.New("invalid id format")
+
+// New returns a prefixed, time-ordered identifier. The body is a UUIDv7
+// without hyphens, so ids of the same type sort by creation time.
+func New(prefix Prefix) (string, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", err
+	}
+	return string(prefix) + hex.EncodeToString(id[:]), nil
+}
+
+func NewPaymentId() (string, error) {
+	return New(PAYMENT)
+}
+
+func NewRefundId() (string, error) {
+	return New(REFUND)
+}
+
+func NewTokenId() (string, error) {
+	return New(TOKEN)
+}
+
+// Validate checks that id carries the given prefix followed by a hex
+// encoded UUIDv7.
+func Validate(prefix Prefix, id string) error {
+	if !strings.HasPrefix(id, string(prefix)) {
+		return ErrInvalidId
+	}
+	body := id[len(prefix):]
+	if len(body) != 32 {
+		return ErrInvalidId
+	}
+	parsed, err := uuid.Parse(body)
+	if err != nil || parsed.Version() != 7 || strings.ToLower(body) != body {
+		return ErrInvalidId
+	}
+	return nil
+}
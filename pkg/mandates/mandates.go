@@ -0,0 +1,139 @@
+package mandates
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/eventlog"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ids"
+)
+
+const (
+	ACTIVE  string = "active"
+	REVOKED        = "revoked"
+)
+
+const (
+	MANDATE_CREATED string = "mandate_created"
+	MANDATE_REVOKED        = "mandate_revoked"
+)
+
+var (
+	ErrMandateNotFound = errors.New("mandate not found")
+	ErrMandateRevoked  = errors.New("mandate has been revoked")
+)
+
+// Mandate is the cardholder's consent, given on a customer-initiated
+// payment, for the merchant to charge the same card later without a CVV.
+// The card is referred to by its token in the card vault.
+type Mandate struct {
+	Id               string
+	MerchantId       string
+	CardToken        string
+	CardLast4        string
+	CardFingerprint  string
+	ExpirationMonth  int
+	ExpirationYear   int
+	InitialPaymentId string
+	Status           string
+	CreatedAt        time.Time
+}
+
+// Store writes every created and revoked mandate to its log, so that
+// mandates survive a restart along with the payments made on them.
+type Store struct {
+	mu       sync.RWMutex
+	log      eventlog.Log
+	mandates map[string]Mandate
+}
+
+// New returns a store that keeps its mandates in memory only.
+func New() *Store {
+	return &Store{log: eventlog.NewMemoryLog(), mandates: make(map[string]Mandate)}
+}
+
+// Open returns a store that writes to log and recovers the mandates
+// already in it.
+func Open(log eventlog.Log) (*Store, error) {
+	store := &Store{log: log, mandates: make(map[string]Mandate)}
+	err := log.Replay(0, func(event eventlog.Event) error {
+		switch event.Type {
+		case MANDATE_CREATED:
+			var mandate Mandate
+			if err := json.Unmarshal(event.Data, &mandate); err != nil {
+				return fmt.Errorf("could not decode mandate %s: %w", event.AggregateId, err)
+			}
+			store.mandates[mandate.Id] = mandate
+		case MANDATE_REVOKED:
+			if mandate, ok := store.mandates[event.AggregateId]; ok {
+				mandate.Status = REVOKED
+				store.mandates[event.AggregateId] = mandate
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (store *Store) Create(mandate Mandate) (Mandate, error) {
+	id, err := ids.New(ids.MANDATE)
+	if err != nil {
+		return Mandate{}, err
+	}
+	mandate.Id = id
+	mandate.Status = ACTIVE
+	data, err := json.Marshal(mandate)
+	if err != nil {
+		return Mandate{}, err
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	_, err = store.log.Append(eventlog.Event{AggregateId: id, Type: MANDATE_CREATED, Timestamp: mandate.CreatedAt, Data: data})
+	if err != nil {
+		return Mandate{}, err
+	}
+	store.mandates[id] = mandate
+	return mandate, nil
+}
+
+func (store *Store) Get(id string) (Mandate, bool) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	mandate, ok := store.mandates[id]
+	return mandate, ok
+}
+
+// Active returns the mandate if it exists, belongs to merchantId and has
+// not been revoked.
+func (store *Store) Active(id string, merchantId string) (Mandate, error) {
+	mandate, ok := store.Get(id)
+	if !ok || mandate.MerchantId != merchantId {
+		return Mandate{}, ErrMandateNotFound
+	}
+	if mandate.Status != ACTIVE {
+		return Mandate{}, ErrMandateRevoked
+	}
+	return mandate, nil
+}
+
+func (store *Store) Revoke(id string, now time.Time) (Mandate, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	mandate, ok := store.mandates[id]
+	if !ok {
+		return Mandate{}, ErrMandateNotFound
+	}
+	_, err := store.log.Append(eventlog.Event{AggregateId: id, Type: MANDATE_REVOKED, Timestamp: now})
+	if err != nil {
+		return Mandate{}, err
+	}
+	mandate.Status = REVOKED
+	store.mandates[id] = mandate
+	return mandate, nil
+}
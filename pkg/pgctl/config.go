@@ -0,0 +1,58 @@
+package pgctl
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+const DEFAULT_BASE_URL = "http://localhost:8081"
+
+// Config holds the gateway to talk to and the credentials to use. It is
+// read from the config file, then overridden by PGCTL_* variables and
+// finally by flags.
+type Config struct {
+	BaseURL    string `json:"base_url"`
+	MerchantId string `json:"merchant_id"`
+	APIKey     string `json:"api_key"`
+}
+
+// DefaultConfigPath is $PGCTL_CONFIG, or ~/.config/pgctl/config.json.
+func DefaultConfigPath() string {
+	if path := os.Getenv("PGCTL_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pgctl", "config.json")
+}
+
+// LoadConfig reads the config file at path. A missing file is not an error.
+func LoadConfig(path string) (Config, error) {
+	config := Config{BaseURL: DEFAULT_BASE_URL}
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return config, err
+		}
+		if err == nil {
+			if err := json.Unmarshal(content, &config); err != nil {
+				return config, err
+			}
+		}
+	}
+	overrides := map[string]*string{
+		"PGCTL_BASE_URL":    &config.BaseURL,
+		"PGCTL_MERCHANT_ID": &config.MerchantId,
+		"PGCTL_API_KEY":     &config.APIKey,
+	}
+	for key, target := range overrides {
+		if value := os.Getenv(key); value != "" {
+			*target = value
+		}
+	}
+	return config, nil
+}
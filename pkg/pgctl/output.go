@@ -0,0 +1,70 @@
+package pgctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/res"
+)
+
+const (
+	OUTPUT_TABLE string = "table"
+	OUTPUT_JSON         = "json"
+)
+
+func writeJSON(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func writeTable(w io.Writer, header []string, rows [][]string) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(table, strings.Join(row, "\t"))
+	}
+	return table.Flush()
+}
+
+func writePayments(w io.Writer, payments []res.PaymentDetails) error {
+	rows := make([][]string, 0, len(payments))
+	for _, payment := range payments {
+		rows = append(rows, []string{
+			payment.Id,
+			payment.Status,
+			strconv.Itoa(payment.Amount),
+			payment.CurrencyCode,
+			strconv.Itoa(payment.CapturedAmount),
+			strconv.Itoa(payment.RefundedAmount),
+			"**** " + payment.LastFourCardDigit,
+			payment.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return writeTable(w, []string{"ID", "STATUS", "AMOUNT", "CURRENCY", "CAPTURED", "REFUNDED", "CARD", "CREATED"}, rows)
+}
+
+func writeEvents(w io.Writer, events []res.PaymentEvent) error {
+	rows := make([][]string, 0, len(events))
+	for _, event := range events {
+		rows = append(rows, []string{event.Timestamp.Format(time.RFC3339), event.From, event.To, event.Actor, event.Reason})
+	}
+	return writeTable(w, []string{"TIME", "FROM", "TO", "ACTOR", "REASON"}, rows)
+}
+
+func writeReports(w io.Writer, reports []res.Report) error {
+	rows := make([][]string, 0, len(reports))
+	for _, report := range reports {
+		currencies := make([]string, 0, len(report.Rows))
+		for _, row := range report.Rows {
+			currencies = append(currencies, row.Currency+" "+strconv.Itoa(row.Net))
+		}
+		rows = append(rows, []string{report.Id, report.Date, strings.Join(currencies, ", ")})
+	}
+	return writeTable(w, []string{"ID", "DATE", "NET"}, rows)
+}
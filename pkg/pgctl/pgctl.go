@@ -0,0 +1,251 @@
+// Package pgctl implements the pgctl operator command line.
+package pgctl
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/res"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/client"
+)
+
+const usage = `Usage: pgctl [-config path] [-output table|json] [-base-url url] [-merchant-id id] [-api-key key] <command>
+
+Commands:
+  payments create [-amount n] [-currency GBP] [-card number] [-cvv 123]   create a test payment
+  payments get <id>                                                        show a payment
+  payments list [-status s] [-page n] [-limit n]                           list payments
+  payments refund <id> -amount n                                           refund a captured payment
+  payments events <id>                                                     show a payment's status history
+  reports list                                                             list settlement reports
+  reports export <id> [-format csv|json] [-out file]                       download a settlement report
+`
+
+// TEST_CARD is authorized by the bank simulator.
+const TEST_CARD = "2222405343248877"
+
+var errUsage = errors.New("invalid usage")
+
+type app struct {
+	gateway *client.Client
+	output  string
+	stdout  io.Writer
+}
+
+// Run executes the command line args and returns the process exit code.
+func Run(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("pgctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { fmt.Fprint(stderr, usage) }
+	configPath := flags.String("config", DefaultConfigPath(), "Config file with base_url, merchant_id and api_key")
+	output := flags.String("output", OUTPUT_TABLE, "Output format, table or json")
+	baseURL := flags.String("base-url", "", "Gateway URL, overrides the config")
+	merchantId := flags.String("merchant-id", "", "Merchant to act for, overrides the config")
+	apiKey := flags.String("api-key", "", "API key, overrides the config")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *output != OUTPUT_TABLE && *output != OUTPUT_JSON {
+		fmt.Fprintln(stderr, "output must be table or json")
+		return 2
+	}
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "could not read config: %v\n", err)
+		return 1
+	}
+	if *baseURL != "" {
+		config.BaseURL = *baseURL
+	}
+	if *merchantId != "" {
+		config.MerchantId = *merchantId
+	}
+	if *apiKey != "" {
+		config.APIKey = *apiKey
+	}
+
+	cli := &app{
+		gateway: client.New(client.Config{BaseURL: config.BaseURL, MerchantId: config.MerchantId, APIKey: config.APIKey}),
+		output:  *output,
+		stdout:  stdout,
+	}
+	err = cli.run(context.Background(), flags.Args(), stderr)
+	switch {
+	case errors.Is(err, errUsage):
+		fmt.Fprint(stderr, usage)
+		return 2
+	case err != nil:
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func (cli *app) run(ctx context.Context, args []string, stderr io.Writer) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+	command := args[0] + " " + args[1]
+	flags := flag.NewFlagSet("pgctl "+command, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	args = args[2:]
+
+	switch command {
+	case "payments create":
+		now := time.Now()
+		body := req.CreatePaymentReqModel{}
+		flags.IntVar(&body.Amount, "amount", 1000, "Amount in minor units")
+		flags.StringVar(&body.Currency, "currency", "GBP", "ISO 4217 currency code")
+		flags.StringVar(&body.CardNumber, "card", TEST_CARD, "Card number")
+		flags.IntVar(&body.ExpirationMonth, "expiry-month", int(now.Month()), "Card expiry month")
+		flags.IntVar(&body.ExpirationYear, "expiry-year", now.Year()+1, "Card expiry year")
+		flags.StringVar(&body.CVV, "cvv", "123", "Card verification value")
+		flags.BoolVar(&body.ThreeDS, "three-ds", false, "Authenticate the cardholder with 3-D Secure")
+		if _, err := parse(flags, args); err != nil {
+			return errUsage
+		}
+		payment, err := cli.gateway.CreatePayment(ctx, body)
+		if err != nil {
+			return err
+		}
+		return cli.payment(payment)
+
+	case "payments get":
+		id, err := parse(flags, args)
+		if err != nil || id == "" {
+			return errUsage
+		}
+		payment, err := cli.gateway.GetPayment(ctx, id)
+		if err != nil {
+			return err
+		}
+		return cli.payment(payment)
+
+	case "payments list":
+		var params client.ListPaymentsParams
+		flags.StringVar(&params.Status, "status", "", "Only list payments with this status")
+		flags.IntVar(&params.Page, "page", 1, "Page number")
+		flags.IntVar(&params.Limit, "limit", 20, "Payments per page")
+		if _, err := parse(flags, args); err != nil {
+			return errUsage
+		}
+		page, err := cli.gateway.ListPayments(ctx, params)
+		if err != nil {
+			return err
+		}
+		if cli.output == OUTPUT_JSON {
+			return writeJSON(cli.stdout, page)
+		}
+		if err := writePayments(cli.stdout, page.Payments); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(cli.stdout, "page %d of %d, %d payments\n", page.Pagination.CurrentPage, page.Pagination.TotalPage, page.Pagination.TotalItems)
+		return err
+
+	case "payments refund":
+		amount := flags.Int("amount", 0, "Amount to refund in minor units")
+		id, err := parse(flags, args)
+		if err != nil || id == "" || *amount <= 0 {
+			return errUsage
+		}
+		payment, err := cli.gateway.Refund(ctx, id, *amount)
+		if err != nil {
+			return err
+		}
+		return cli.payment(payment)
+
+	case "payments events":
+		id, err := parse(flags, args)
+		if err != nil || id == "" {
+			return errUsage
+		}
+		events, err := cli.gateway.GetPaymentEvents(ctx, id)
+		if err != nil {
+			return err
+		}
+		if cli.output == OUTPUT_JSON {
+			return writeJSON(cli.stdout, events)
+		}
+		return writeEvents(cli.stdout, events)
+
+	case "reports list":
+		if _, err := parse(flags, args); err != nil {
+			return errUsage
+		}
+		reports, err := cli.gateway.ListReports(ctx)
+		if err != nil {
+			return err
+		}
+		if cli.output == OUTPUT_JSON {
+			return writeJSON(cli.stdout, reports)
+		}
+		return writeReports(cli.stdout, reports)
+
+	case "reports export":
+		format := flags.String("format", "csv", "Report format, csv or json")
+		out := flags.String("out", "", "File to write the report to instead of stdout")
+		id, err := parse(flags, args)
+		if err != nil || id == "" || (*format != "csv" && *format != "json") {
+			return errUsage
+		}
+		return cli.exportReport(ctx, id, *format, *out)
+	}
+	return errUsage
+}
+
+// parse reads the command's flags and returns its id argument, which may be
+// given before the flags, as in "payments get pay_1", or after them.
+func parse(flags *flag.FlagSet, args []string) (string, error) {
+	id := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		id, args = args[0], args[1:]
+	}
+	if err := flags.Parse(args); err != nil {
+		return "", err
+	}
+	if id == "" {
+		id = flags.Arg(0)
+	}
+	return id, nil
+}
+
+func (cli *app) payment(payment res.PaymentDetails) error {
+	if cli.output == OUTPUT_JSON {
+		return writeJSON(cli.stdout, payment)
+	}
+	return writePayments(cli.stdout, []res.PaymentDetails{payment})
+}
+
+func (cli *app) exportReport(ctx context.Context, id string, format string, out string) error {
+	var content []byte
+	if format == "csv" {
+		csv, err := cli.gateway.ReportCSV(ctx, id)
+		if err != nil {
+			return err
+		}
+		content = csv
+	} else {
+		report, err := cli.gateway.GetReport(ctx, id)
+		if err != nil {
+			return err
+		}
+		var buffer strings.Builder
+		if err := writeJSON(&buffer, report); err != nil {
+			return err
+		}
+		content = []byte(buffer.String())
+	}
+	if out == "" {
+		_, err := cli.stdout.Write(content)
+		return err
+	}
+	return os.WriteFile(out, content, 0o644)
+}
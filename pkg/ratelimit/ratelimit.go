@@ -0,0 +1,134 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit allows Burst requests at once, refilled evenly over Period.
+type Limit struct {
+	Burst  int
+	Period time.Duration
+}
+
+func (limit Limit) refillRate() float64 {
+	return float64(limit.Burst) / limit.Period.Seconds()
+}
+
+// ParseLimit reads limits written as "<requests>/<period>", e.g. "100/1m".
+func ParseLimit(value string) (Limit, error) {
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 {
+		return Limit{}, fmt.Errorf("rate limit %q must look like 100/1m", value)
+	}
+	burst, err := strconv.Atoi(parts[0])
+	if err != nil || burst <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q must allow a positive number of requests", value)
+	}
+	period, err := time.ParseDuration(parts[1])
+	if err != nil || period <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q must have a positive period", value)
+	}
+	return Limit{Burst: burst, Period: period}, nil
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAfter is the time until the bucket is full again and RetryAfter
+	// the time until the next request would be allowed.
+	ResetAfter time.Duration
+	RetryAfter time.Duration
+}
+
+// Bucket names a token bucket and the limit it refills at.
+type Bucket struct {
+	Key   string
+	Limit Limit
+}
+
+// Store keeps token buckets. TakeAll takes a token from every bucket only
+// when all of them have one, so a request denied by one limit does not use
+// up the others, and returns a result per bucket. Implementations shared
+// between instances, e.g. backed by Redis, must take tokens atomically.
+type Store interface {
+	TakeAll(buckets []Bucket, now time.Time) ([]Result, error)
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	period  time.Duration
+}
+
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	takes   int
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket)}
+}
+
+func (store *MemoryStore) TakeAll(buckets []Bucket, now time.Time) ([]Result, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.takes++
+	if store.takes%1000 == 0 {
+		store.sweep(now)
+	}
+
+	refilled := make([]*bucket, len(buckets))
+	allowed := true
+	for i, requested := range buckets {
+		current, ok := store.buckets[requested.Key]
+		if !ok {
+			current = &bucket{tokens: float64(requested.Limit.Burst), updated: now}
+			store.buckets[requested.Key] = current
+		}
+		current.tokens = math.Min(float64(requested.Limit.Burst), current.tokens+now.Sub(current.updated).Seconds()*requested.Limit.refillRate())
+		current.updated = now
+		current.period = requested.Limit.Period
+		refilled[i] = current
+		allowed = allowed && current.tokens >= 1
+	}
+
+	results := make([]Result, len(buckets))
+	for i, requested := range buckets {
+		current := refilled[i]
+		rate := requested.Limit.refillRate()
+		result := Result{Limit: requested.Limit.Burst}
+		if current.tokens >= 1 {
+			if allowed {
+				current.tokens--
+			}
+			result.Allowed = true
+		} else {
+			result.RetryAfter = seconds((1 - current.tokens) / rate)
+		}
+		result.Remaining = int(current.tokens)
+		result.ResetAfter = seconds((float64(requested.Limit.Burst) - current.tokens) / rate)
+		results[i] = result
+	}
+	return results, nil
+}
+
+// sweep drops buckets that have refilled completely.
+func (store *MemoryStore) sweep(now time.Time) {
+	for key, current := range store.buckets {
+		if now.Sub(current.updated) >= current.period {
+			delete(store.buckets, key)
+		}
+	}
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
@@ -0,0 +1,86 @@
+package risk
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	KEY_CARD_FINGERPRINT string = "card_fingerprint"
+	KEY_IP                      = "ip"
+)
+
+// Config is the rule set loaded from YAML. Each triggered rule adds its
+// score; the total is compared against ReviewScore and BlockScore. Funding
+// scores cards by the funding type of their BIN, such as prepaid.
+type Config struct {
+	ReviewScore      int                 `yaml:"review_score"`
+	BlockScore       int                 `yaml:"block_score"`
+	Velocity         []VelocityRule      `yaml:"velocity"`
+	AmountThresholds []AmountThreshold   `yaml:"amount_thresholds"`
+	CountryMismatch  CountryMismatchRule `yaml:"country_mismatch"`
+	BlockedCards     BlockedCardsRule    `yaml:"blocked_cards"`
+	Funding          map[string]int      `yaml:"funding"`
+	// BinCountries maps card number prefixes to ISO country codes and
+	// IPCountries maps CIDR ranges to ISO country codes.
+	BinCountries map[string]string `yaml:"bin_countries"`
+	IPCountries  map[string]string `yaml:"ip_countries"`
+}
+
+// VelocityRule triggers when more than Max payments share the same Key
+// within Window.
+type VelocityRule struct {
+	Name   string        `yaml:"name"`
+	Key    string        `yaml:"key"`
+	Window time.Duration `yaml:"window"`
+	Max    int           `yaml:"max"`
+	Score  int           `yaml:"score"`
+}
+
+type AmountThreshold struct {
+	Currency  string `yaml:"currency"`
+	MaxAmount int    `yaml:"max_amount"`
+	Score     int    `yaml:"score"`
+}
+
+type CountryMismatchRule struct {
+	Score int `yaml:"score"`
+}
+
+type BlockedCardsRule struct {
+	Fingerprints []string `yaml:"fingerprints"`
+	Score        int      `yaml:"score"`
+}
+
+func LoadConfig(path string) (Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	var config Config
+	if err = yaml.Unmarshal(content, &config); err != nil {
+		return Config{}, err
+	}
+	return config, config.Validate()
+}
+
+func (config Config) Validate() error {
+	for _, rule := range config.Velocity {
+		if rule.Key != KEY_CARD_FINGERPRINT && rule.Key != KEY_IP {
+			return fmt.Errorf("velocity rule %q has unknown key %q", rule.Name, rule.Key)
+		}
+		if rule.Window <= 0 {
+			return fmt.Errorf("velocity rule %q must have a positive window", rule.Name)
+		}
+	}
+	for cidr := range config.IPCountries {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
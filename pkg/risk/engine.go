@@ -0,0 +1,244 @@
+package risk
+
+import (
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/bins"
+)
+
+const (
+	ALLOW  string = "allow"
+	REVIEW        = "review"
+	BLOCK         = "block"
+)
+
+type Input struct {
+	CardNumber      string
+	CardFingerprint string
+	IP              string
+	Currency        string
+	Amount          int
+	// Bin is the card's issuer, when the gateway knows its BIN.
+	Bin *bins.Info
+}
+
+type Assessment struct {
+	Score    int
+	Decision string
+	Rules    []string
+}
+
+// CountryLookup resolves a card number or an IP address to an ISO country
+// code.
+type CountryLookup interface {
+	Country(value string) (string, bool)
+}
+
+type Engine struct {
+	mu         sync.Mutex
+	config     Config
+	binTable   CountryLookup
+	ipLookup   CountryLookup
+	velocities map[string][]time.Time
+	// nextPrune is when keys with no attempt inside the longest window are
+	// next dropped.
+	nextPrune time.Time
+}
+
+func NewEngine(config Config) *Engine {
+	engine := &Engine{velocities: make(map[string][]time.Time)}
+	engine.SetConfig(config)
+	return engine
+}
+
+// SetConfig swaps the rule set. Velocity history is kept across reloads,
+// unless the new rule set has no velocity rules.
+func (engine *Engine) SetConfig(config Config) {
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+	engine.config = config
+	if engine.longestWindow() == 0 {
+		engine.velocities = make(map[string][]time.Time)
+	}
+	engine.binTable = prefixTable(config.BinCountries)
+	engine.ipLookup = newCIDRTable(config.IPCountries)
+}
+
+// Evaluate scores a payment attempt and records it for velocity checks.
+func (engine *Engine) Evaluate(input Input, now time.Time) Assessment {
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+
+	assessment := Assessment{Rules: make([]string, 0)}
+	trigger := func(name string, score int) {
+		assessment.Score += score
+		assessment.Rules = append(assessment.Rules, name)
+	}
+
+	for _, rule := range engine.config.Velocity {
+		value := input.CardFingerprint
+		if rule.Key == KEY_IP {
+			value = input.IP
+		}
+		if value == "" {
+			continue
+		}
+		if engine.count(rule.Key+":"+value, rule.Window, now) >= rule.Max {
+			trigger(ruleName(rule.Name, "velocity_"+rule.Key), rule.Score)
+		}
+	}
+	if longest := engine.longestWindow(); longest > 0 {
+		engine.prune(longest, now)
+		engine.record(KEY_CARD_FINGERPRINT+":"+input.CardFingerprint, longest, now)
+		if input.IP != "" {
+			engine.record(KEY_IP+":"+input.IP, longest, now)
+		}
+	}
+
+	for _, threshold := range engine.config.AmountThresholds {
+		if strings.EqualFold(threshold.Currency, input.Currency) && input.Amount > threshold.MaxAmount {
+			trigger("amount_threshold_"+strings.ToLower(threshold.Currency), threshold.Score)
+		}
+	}
+
+	if input.Bin != nil && input.Bin.Funding != "" {
+		if score := engine.config.Funding[input.Bin.Funding]; score > 0 {
+			trigger("funding_"+input.Bin.Funding, score)
+		}
+	}
+
+	if engine.config.CountryMismatch.Score > 0 {
+		binCountry, binOk := engine.binTable.Country(input.CardNumber)
+		if input.Bin != nil && input.Bin.Country != "" {
+			binCountry, binOk = input.Bin.Country, true
+		}
+		ipCountry, ipOk := engine.ipLookup.Country(input.IP)
+		if binOk && ipOk && !strings.EqualFold(binCountry, ipCountry) {
+			trigger("country_mismatch", engine.config.CountryMismatch.Score)
+		}
+	}
+
+	for _, fingerprint := range engine.config.BlockedCards.Fingerprints {
+		if fingerprint == input.CardFingerprint {
+			trigger("blocked_card", engine.config.BlockedCards.Score)
+			break
+		}
+	}
+
+	assessment.Decision = ALLOW
+	if engine.config.BlockScore > 0 && assessment.Score >= engine.config.BlockScore {
+		assessment.Decision = BLOCK
+	} else if engine.config.ReviewScore > 0 && assessment.Score >= engine.config.ReviewScore {
+		assessment.Decision = REVIEW
+	}
+	return assessment
+}
+
+// count returns how many attempts were recorded for key within window.
+func (engine *Engine) count(key string, window time.Duration, now time.Time) int {
+	attempts := engine.velocities[key]
+	count := 0
+	for _, at := range attempts {
+		if now.Sub(at) < window {
+			count++
+		}
+	}
+	return count
+}
+
+// longestWindow returns the widest velocity window, or zero when there are
+// no velocity rules and attempts need not be recorded.
+func (engine *Engine) longestWindow() time.Duration {
+	longest := time.Duration(0)
+	for _, rule := range engine.config.Velocity {
+		if rule.Window > longest {
+			longest = rule.Window
+		}
+	}
+	return longest
+}
+
+// prune drops the keys whose last attempt is older than longest, at most
+// once per window, so cards and IPs that are not seen again are forgotten.
+func (engine *Engine) prune(longest time.Duration, now time.Time) {
+	if now.Before(engine.nextPrune) {
+		return
+	}
+	for key, attempts := range engine.velocities {
+		if len(attempts) == 0 || now.Sub(attempts[len(attempts)-1]) >= longest {
+			delete(engine.velocities, key)
+		}
+	}
+	engine.nextPrune = now.Add(longest)
+}
+
+// record adds an attempt for key and drops those older than longest.
+func (engine *Engine) record(key string, longest time.Duration, now time.Time) {
+	attempts := engine.velocities[key]
+	kept := attempts[:0]
+	for _, at := range attempts {
+		if now.Sub(at) < longest {
+			kept = append(kept, at)
+		}
+	}
+	engine.velocities[key] = append(kept, now)
+}
+
+func ruleName(name string, fallback string) string {
+	if name == "" {
+		return fallback
+	}
+	return name
+}
+
+type prefixTable map[string]string
+
+// Country returns the country of the longest matching prefix.
+func (table prefixTable) Country(cardNumber string) (string, bool) {
+	prefixes := make([]string, 0, len(table))
+	for prefix := range table {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(cardNumber, prefix) {
+			return table[prefix], true
+		}
+	}
+	return "", false
+}
+
+type cidrTable struct {
+	networks  []*net.IPNet
+	countries []string
+}
+
+func newCIDRTable(cidrs map[string]string) *cidrTable {
+	table := &cidrTable{}
+	for cidr, country := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		table.networks = append(table.networks, network)
+		table.countries = append(table.countries, country)
+	}
+	return table
+}
+
+func (table *cidrTable) Country(ip string) (string, bool) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return "", false
+	}
+	for i, network := range table.networks {
+		if network.Contains(parsed) {
+			return table.countries[i], true
+		}
+	}
+	return "", false
+}
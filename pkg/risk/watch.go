@@ -0,0 +1,43 @@
+package risk
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+// WatchFile reloads the engine's rules whenever the file at path changes,
+// checking every interval until stop is closed. A file that fails to parse
+// is logged and the previous rules stay in place.
+func (engine *Engine) WatchFile(path string, interval time.Duration, stop <-chan struct{}) {
+	lastModified := modTime(path)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			modified := modTime(path)
+			if modified.Equal(lastModified) {
+				continue
+			}
+			lastModified = modified
+			config, err := LoadConfig(path)
+			if err != nil {
+				log.Printf("could not reload risk rules from %s: %v", path, err)
+				continue
+			}
+			engine.SetConfig(config)
+			log.Printf("reloaded risk rules from %s", path)
+		}
+	}
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,108 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+type Config struct {
+	Acquirers []AcquirerConfig `json:"acquirers"`
+	Rules     []Rule           `json:"rules"`
+	// Default is tried after the candidates of the matching rule, or on its
+	// own when no rule matches.
+	Default string `json:"default"`
+}
+
+type AcquirerConfig struct {
+	Name    string `json:"name"`
+	BaseURL string `json:"base_url"`
+	// TimeoutMs bounds each request to the acquirer. A request that times
+	// out fails over like an unreachable acquirer.
+	TimeoutMs int `json:"timeout_ms"`
+}
+
+// Rule selects acquirers for payments matching every criterion it sets.
+// Empty criteria match any payment. The primary acquirer is Acquirer or,
+// when Weights is set, a weighted random pick; Fallback lists the acquirers
+// tried in order after a transient failure.
+type Rule struct {
+	Currencies []string       `json:"currencies"`
+	CardBrands []string       `json:"card_brands"`
+	BinRanges  []BinRange     `json:"bin_ranges"`
+	Acquirer   string         `json:"acquirer"`
+	Weights    map[string]int `json:"weights"`
+	Fallback   []string       `json:"fallback"`
+	// IssuerCountries and Funding match the card's BIN details, so a
+	// payment whose BIN is unknown never matches a rule setting them.
+	IssuerCountries []string `json:"issuer_countries"`
+	Funding         []string `json:"funding"`
+}
+
+// BinRange matches card numbers whose leading digits, taken to the length
+// of From, fall between From and To inclusive.
+type BinRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func LoadConfig(path string) (Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	var config Config
+	if err = json.Unmarshal(content, &config); err != nil {
+		return Config{}, err
+	}
+	return config, config.Validate()
+}
+
+func (config Config) Validate() error {
+	known := make(map[string]bool)
+	for _, acquirer := range config.Acquirers {
+		if acquirer.Name == "" {
+			return fmt.Errorf("acquirer name is required")
+		}
+		if acquirer.TimeoutMs < 0 {
+			return fmt.Errorf("timeout of %q must not be negative", acquirer.Name)
+		}
+		known[acquirer.Name] = true
+	}
+	check := func(name string) error {
+		if name != "" && !known[name] {
+			return fmt.Errorf("unknown acquirer %q", name)
+		}
+		return nil
+	}
+	if err := check(config.Default); err != nil {
+		return err
+	}
+	for _, rule := range config.Rules {
+		if rule.Acquirer == "" && len(rule.Weights) == 0 {
+			return fmt.Errorf("rule must set acquirer or weights")
+		}
+		if err := check(rule.Acquirer); err != nil {
+			return err
+		}
+		for name, weight := range rule.Weights {
+			if err := check(name); err != nil {
+				return err
+			}
+			if weight < 0 {
+				return fmt.Errorf("weight of %q must not be negative", name)
+			}
+		}
+		for _, name := range rule.Fallback {
+			if err := check(name); err != nil {
+				return err
+			}
+		}
+		for _, binRange := range rule.BinRanges {
+			if len(binRange.From) == 0 || len(binRange.From) != len(binRange.To) {
+				return fmt.Errorf("bin range %s-%s must have bounds of equal length", binRange.From, binRange.To)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package routing
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/bins"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/cards"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/http_clients"
+)
+
+const DEFAULT_ACQUIRER = "default"
+
+var ErrNoAcquirer = errors.New("no acquirer available for payment")
+
+// Acquirer authorizes payments with a single acquiring bank.
+type Acquirer interface {
+	AuthorizePayment(cardNumber string, expiryDate string, currency string, amount int, cvv string) (string, error)
+}
+
+// StoredCredentialAcquirer authorizes merchant-initiated payments on a
+// stored card, which carry no CVV. Acquirers without it are skipped for
+// those payments.
+type StoredCredentialAcquirer interface {
+	AuthorizeMerchantInitiated(cardNumber string, expiryDate string, currency string, amount int) (string, error)
+}
+
+type Payment struct {
+	CardNumber string
+	ExpiryDate string
+	Currency   string
+	Amount     int
+	CVV        string
+	// MerchantInitiated payments are charged on a mandate without a CVV.
+	MerchantInitiated bool
+	// Bin is the card's issuer, when the gateway knows its BIN.
+	Bin *bins.Info
+}
+
+type Result struct {
+	Status   string
+	Acquirer string
+}
+
+type Router struct {
+	acquirers map[string]Acquirer
+	rules     []Rule
+	fallback  string
+	intn      func(n int) int
+}
+
+func NewRouter(config Config) *Router {
+	acquirers := make(map[string]Acquirer)
+	for _, acquirer := range config.Acquirers {
+		acquirers[acquirer.Name] = http_clients.NewAcquiringBankClient(acquirer.Name, acquirer.BaseURL, time.Duration(acquirer.TimeoutMs)*time.Millisecond)
+	}
+	return &Router{acquirers: acquirers, rules: config.Rules, fallback: config.Default, intn: rand.Intn}
+}
+
+// NewSingleAcquirerRouter sends every payment to the bank at
+// ACQUIRING_BANK_BASE_URL.
+func NewSingleAcquirerRouter() *Router {
+	return NewRouter(Config{
+		Acquirers: []AcquirerConfig{{Name: DEFAULT_ACQUIRER}},
+		Default:   DEFAULT_ACQUIRER,
+	})
+}
+
+// SetAcquirer replaces the client used for a named acquirer.
+func (router *Router) SetAcquirer(name string, acquirer Acquirer) {
+	router.acquirers[name] = acquirer
+}
+
+// SetRandom replaces the source used for weighted splits.
+func (router *Router) SetRandom(intn func(n int) int) {
+	router.intn = intn
+}
+
+// Candidates returns the acquirers to try for a payment, in order.
+func (router *Router) Candidates(payment Payment) []string {
+	candidates := make([]string, 0)
+	add := func(name string) {
+		if name == "" {
+			return
+		}
+		for _, candidate := range candidates {
+			if candidate == name {
+				return
+			}
+		}
+		candidates = append(candidates, name)
+	}
+
+	for _, rule := range router.rules {
+		if !rule.matches(payment) {
+			continue
+		}
+		add(rule.Acquirer)
+		if rule.Acquirer == "" {
+			add(router.pickWeighted(rule.Weights))
+		}
+		for _, name := range rule.Fallback {
+			add(name)
+		}
+		break
+	}
+	add(router.fallback)
+	return candidates
+}
+
+// Authorize tries each candidate acquirer until one gives a decision. It
+// only moves on after a failure that proves the acquirer never accepted the
+// request; any other error, such as a timeout that may have left the
+// payment authorized, is returned with that acquirer so the card is not
+// authorized twice. When every acquirer fails with a 5xx status the payment
+// is declined; when the last failure was a refused connection it is
+// returned.
+func (router *Router) Authorize(payment Payment) (Result, error) {
+	var lastErr error
+	lastAcquirer := ""
+	for _, name := range router.Candidates(payment) {
+		acquirer, ok := router.acquirers[name]
+		if !ok {
+			continue
+		}
+		var status string
+		var err error
+		if payment.MerchantInitiated {
+			storedCredentialAcquirer, ok := acquirer.(StoredCredentialAcquirer)
+			if !ok {
+				continue
+			}
+			status, err = storedCredentialAcquirer.AuthorizeMerchantInitiated(payment.CardNumber, payment.ExpiryDate, payment.Currency, payment.Amount)
+		} else {
+			status, err = acquirer.AuthorizePayment(payment.CardNumber, payment.ExpiryDate, payment.Currency, payment.Amount, payment.CVV)
+		}
+		if err == nil {
+			return Result{Status: status, Acquirer: name}, nil
+		}
+		if !http_clients.IsTransient(err) {
+			return Result{Acquirer: name}, err
+		}
+		lastErr = err
+		lastAcquirer = name
+	}
+
+	if lastErr == nil {
+		return Result{}, ErrNoAcquirer
+	}
+	var transientErr *http_clients.TransientError
+	if errors.As(lastErr, &transientErr) && transientErr.StatusCode != 0 {
+		return Result{Status: enums.DECLIEND, Acquirer: lastAcquirer}, nil
+	}
+	return Result{Acquirer: lastAcquirer}, lastErr
+}
+
+func (router *Router) pickWeighted(weights map[string]int) string {
+	total := 0
+	names := make([]string, 0, len(weights))
+	for name, weight := range weights {
+		total += weight
+		names = append(names, name)
+	}
+	if total == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	pick := router.intn(total)
+	for _, name := range names {
+		pick -= weights[name]
+		if pick < 0 {
+			return name
+		}
+	}
+	return ""
+}
+
+func (rule Rule) matches(payment Payment) bool {
+	if len(rule.Currencies) > 0 && !containsFold(rule.Currencies, payment.Currency) {
+		return false
+	}
+	if len(rule.CardBrands) > 0 && !containsFold(rule.CardBrands, payment.brand()) {
+		return false
+	}
+	if len(rule.IssuerCountries) > 0 && (payment.Bin == nil || !containsFold(rule.IssuerCountries, payment.Bin.Country)) {
+		return false
+	}
+	if len(rule.Funding) > 0 && (payment.Bin == nil || !containsFold(rule.Funding, payment.Bin.Funding)) {
+		return false
+	}
+	if len(rule.BinRanges) > 0 {
+		inRange := false
+		for _, binRange := range rule.BinRanges {
+			if binRange.contains(payment.CardNumber) {
+				inRange = true
+				break
+			}
+		}
+		if !inRange {
+			return false
+		}
+	}
+	return true
+}
+
+// brand is the scheme of the card's BIN, or the one inferred from its
+// leading digits when the BIN is unknown.
+func (payment Payment) brand() string {
+	if payment.Bin != nil && payment.Bin.Scheme != "" {
+		return payment.Bin.Scheme
+	}
+	return cards.Brand(payment.CardNumber)
+}
+
+func (binRange BinRange) contains(cardNumber string) bool {
+	if len(cardNumber) < len(binRange.From) {
+		return false
+	}
+	prefix := cardNumber[:len(binRange.From)]
+	return prefix >= binRange.From && prefix <= binRange.To
+}
+
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package subscriptions
+
+import (
+	"log"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/clock"
+)
+
+// ChargeFunc creates a merchant-initiated payment for the subscription and
+// returns its id and whether it was authorized.
+type ChargeFunc func(subscription Subscription) (paymentId string, authorized bool, err error)
+
+type Scheduler struct {
+	store  *Store
+	charge ChargeFunc
+	clock  clock.Clock
+}
+
+func NewScheduler(store *Store, charge ChargeFunc, clock clock.Clock) *Scheduler {
+	return &Scheduler{store: store, charge: charge, clock: clock}
+}
+
+// RunDue charges every subscription due at now and returns how many were
+// charged.
+func (scheduler *Scheduler) RunDue(now time.Time) int {
+	due := scheduler.store.Due(now)
+	for _, subscription := range due {
+		paymentId, authorized, err := scheduler.charge(subscription)
+		if err != nil {
+			log.Printf("could not charge subscription %s: %v", subscription.Id, err)
+		}
+		if _, err := scheduler.store.RecordCharge(subscription.Id, paymentId, authorized && err == nil, now); err != nil {
+			log.Printf("could not record charge of subscription %s: %v", subscription.Id, err)
+		}
+	}
+	return len(due)
+}
+
+// Start runs due subscriptions every interval until stop is closed.
+func (scheduler *Scheduler) Start(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			scheduler.RunDue(scheduler.clock.Now())
+		}
+	}
+}
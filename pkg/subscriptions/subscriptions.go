@@ -0,0 +1,244 @@
+package subscriptions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/eventlog"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ids"
+)
+
+const (
+	ACTIVE    string = "active"
+	PAST_DUE         = "past_due"
+	UNPAID           = "unpaid"
+	CANCELLED        = "cancelled"
+)
+
+const (
+	SUBSCRIPTION_CREATED   string = "subscription_created"
+	SUBSCRIPTION_CHARGED          = "subscription_charged"
+	SUBSCRIPTION_CANCELLED        = "subscription_cancelled"
+)
+
+// chargedData is the event written for a charge attempt. Replaying it moves
+// the subscription on exactly as the attempt did.
+type chargedData struct {
+	PaymentId  string `json:"payment_id,omitempty"`
+	Authorized bool   `json:"authorized"`
+}
+
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+// Subscription charges Amount on a mandate every IntervalMonths, starting at
+// StartAt. A declined charge is retried after each delay in RetrySchedule;
+// once the schedule is exhausted the subscription becomes unpaid.
+type Subscription struct {
+	Id             string
+	MerchantId     string
+	MandateId      string
+	Amount         int
+	Currency       string
+	IntervalMonths int
+	RetrySchedule  []time.Duration
+	StartAt        time.Time
+	// Cycle counts the billing periods paid so far.
+	Cycle int
+	// Attempt counts the failed charges in the current period.
+	Attempt      int
+	NextChargeAt time.Time
+	Status       string
+	PaymentIds   []string
+	CreatedAt    time.Time
+}
+
+// DueAt returns when the given billing period starts. Periods are counted
+// from StartAt so that a short month does not shift later charges.
+func (subscription Subscription) DueAt(cycle int) time.Time {
+	return AddMonths(subscription.StartAt, cycle*subscription.IntervalMonths)
+}
+
+// AddMonths adds months to t, clamping the day to the end of the target
+// month, so Jan 31 plus one month is Feb 28 or 29.
+func AddMonths(t time.Time, months int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
+
+func (subscription *Subscription) recordCharge(paymentId string, authorized bool, now time.Time) {
+	if paymentId != "" {
+		subscription.PaymentIds = append(subscription.PaymentIds, paymentId)
+	}
+	switch {
+	case authorized:
+		subscription.Cycle++
+		subscription.Attempt = 0
+		subscription.Status = ACTIVE
+		subscription.NextChargeAt = subscription.DueAt(subscription.Cycle)
+	case subscription.Attempt < len(subscription.RetrySchedule):
+		subscription.NextChargeAt = now.Add(subscription.RetrySchedule[subscription.Attempt])
+		subscription.Attempt++
+		subscription.Status = PAST_DUE
+	default:
+		subscription.Status = UNPAID
+	}
+}
+
+// Store writes every created, charged and cancelled subscription to its
+// log, so that billing carries on after a restart.
+type Store struct {
+	mu            sync.RWMutex
+	log           eventlog.Log
+	subscriptions map[string]Subscription
+}
+
+// NewStore returns a store that keeps its subscriptions in memory only.
+func NewStore() *Store {
+	return &Store{log: eventlog.NewMemoryLog(), subscriptions: make(map[string]Subscription)}
+}
+
+// OpenStore returns a store that writes to log and recovers the
+// subscriptions already in it.
+func OpenStore(log eventlog.Log) (*Store, error) {
+	store := &Store{log: log, subscriptions: make(map[string]Subscription)}
+	err := log.Replay(0, func(event eventlog.Event) error {
+		if event.Type == SUBSCRIPTION_CREATED {
+			var subscription Subscription
+			if err := json.Unmarshal(event.Data, &subscription); err != nil {
+				return fmt.Errorf("could not decode subscription %s: %w", event.AggregateId, err)
+			}
+			store.subscriptions[subscription.Id] = subscription
+			return nil
+		}
+		subscription, ok := store.subscriptions[event.AggregateId]
+		if !ok {
+			return nil
+		}
+		switch event.Type {
+		case SUBSCRIPTION_CHARGED:
+			var data chargedData
+			if err := json.Unmarshal(event.Data, &data); err != nil {
+				return fmt.Errorf("could not decode charge of subscription %s: %w", event.AggregateId, err)
+			}
+			subscription.applyCharge(data, event.Timestamp)
+		case SUBSCRIPTION_CANCELLED:
+			subscription.Status = CANCELLED
+		}
+		store.subscriptions[event.AggregateId] = subscription
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (store *Store) Create(subscription Subscription) (Subscription, error) {
+	id, err := ids.New(ids.SUBSCRIPTION)
+	if err != nil {
+		return Subscription{}, err
+	}
+	subscription.Id = id
+	subscription.Status = ACTIVE
+	subscription.NextChargeAt = subscription.StartAt
+	data, err := json.Marshal(subscription)
+	if err != nil {
+		return Subscription{}, err
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	_, err = store.log.Append(eventlog.Event{AggregateId: id, Type: SUBSCRIPTION_CREATED, Timestamp: subscription.CreatedAt, Data: data})
+	if err != nil {
+		return Subscription{}, err
+	}
+	store.subscriptions[id] = subscription
+	return subscription, nil
+}
+
+func (store *Store) Get(id string) (Subscription, bool) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	subscription, ok := store.subscriptions[id]
+	return subscription, ok
+}
+
+func (store *Store) Cancel(id string, now time.Time) (Subscription, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	subscription, ok := store.subscriptions[id]
+	if !ok {
+		return Subscription{}, ErrSubscriptionNotFound
+	}
+	_, err := store.log.Append(eventlog.Event{AggregateId: id, Type: SUBSCRIPTION_CANCELLED, Timestamp: now})
+	if err != nil {
+		return Subscription{}, err
+	}
+	subscription.Status = CANCELLED
+	store.subscriptions[id] = subscription
+	return subscription, nil
+}
+
+// Due returns the active and past due subscriptions whose next charge is at
+// or before now, oldest first.
+func (store *Store) Due(now time.Time) []Subscription {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	due := make([]Subscription, 0)
+	for _, subscription := range store.subscriptions {
+		if subscription.Status != ACTIVE && subscription.Status != PAST_DUE {
+			continue
+		}
+		if !subscription.NextChargeAt.After(now) {
+			due = append(due, subscription)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].Id < due[j].Id })
+	return due
+}
+
+// RecordCharge moves the subscription on after a charge attempt. An empty
+// paymentId means no payment could be created, which counts as a decline.
+func (store *Store) RecordCharge(id string, paymentId string, authorized bool, now time.Time) (Subscription, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	subscription, ok := store.subscriptions[id]
+	if !ok {
+		return Subscription{}, ErrSubscriptionNotFound
+	}
+	charged := chargedData{PaymentId: paymentId, Authorized: authorized}
+	if subscription.Status == CANCELLED && paymentId == "" {
+		return subscription, nil
+	}
+	data, err := json.Marshal(charged)
+	if err != nil {
+		return Subscription{}, err
+	}
+	_, err = store.log.Append(eventlog.Event{AggregateId: id, Type: SUBSCRIPTION_CHARGED, Timestamp: now, Data: data})
+	if err != nil {
+		return Subscription{}, err
+	}
+	subscription.applyCharge(charged, now)
+	store.subscriptions[id] = subscription
+	return subscription, nil
+}
+
+// applyCharge moves the subscription on after a charge attempt. A charge
+// that completes after the subscription was cancelled is only listed.
+func (subscription *Subscription) applyCharge(charged chargedData, now time.Time) {
+	if subscription.Status == CANCELLED {
+		if charged.PaymentId != "" {
+			subscription.PaymentIds = append(subscription.PaymentIds, charged.PaymentId)
+		}
+		return
+	}
+	subscription.recordCharge(charged.PaymentId, charged.Authorized, now)
+}
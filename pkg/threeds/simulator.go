@@ -0,0 +1,170 @@
+package threeds
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/clock"
+	"github.com/google/uuid"
+)
+
+const CHALLENGE_PATH = "/3ds/challenges/"
+
+// CHALLENGE_TTL is how long a challenge stays open after it is issued.
+const CHALLENGE_TTL = 15 * time.Minute
+
+type challenge struct {
+	request   ChallengeRequest
+	result    *Result
+	expiresAt time.Time
+}
+
+// Simulator is a local DirectoryServer. Its challenge page lets the
+// cardholder pick the outcome, which the simulator records before posting
+// the challenge id to the notification URL.
+type Simulator struct {
+	mu         sync.Mutex
+	baseURL    string
+	clock      clock.Clock
+	challenges map[string]*challenge
+}
+
+// NewSimulator serves challenges under baseURL + CHALLENGE_PATH. An empty
+// baseURL produces relative challenge URLs.
+func NewSimulator(baseURL string, clock clock.Clock) *Simulator {
+	return &Simulator{baseURL: strings.TrimSuffix(baseURL, "/"), clock: clock, challenges: make(map[string]*challenge)}
+}
+
+func (simulator *Simulator) InitiateChallenge(request ChallengeRequest) (Challenge, error) {
+	id := uuid.NewString()
+	now := simulator.clock.Now()
+	simulator.mu.Lock()
+	for challengeId, open := range simulator.challenges {
+		if !now.Before(open.expiresAt) {
+			delete(simulator.challenges, challengeId)
+		}
+	}
+	simulator.challenges[id] = &challenge{request: request, expiresAt: now.Add(CHALLENGE_TTL)}
+	simulator.mu.Unlock()
+	return Challenge{Id: id, URL: simulator.baseURL + CHALLENGE_PATH + id}, nil
+}
+
+// Respond records the cardholder's answer, one of Y, A and N. The first
+// answer decides the result.
+func (simulator *Simulator) Respond(challengeId string, response string) error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+	open, ok := simulator.open(challengeId)
+	if !ok {
+		return ErrChallengeNotFound
+	}
+	if open.result == nil {
+		result := newResult(response)
+		open.result = &result
+	}
+	return nil
+}
+
+// ChallengeResult returns the answered challenge's result, as many times as
+// it is asked for before the challenge expires, so the gateway can retry
+// after a failure.
+func (simulator *Simulator) ChallengeResult(challengeId string) (Result, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+	open, ok := simulator.open(challengeId)
+	if !ok {
+		return Result{}, ErrChallengeNotFound
+	}
+	if open.result == nil {
+		return Result{}, ErrChallengePending
+	}
+	return *open.result, nil
+}
+
+// open returns the challenge if it has not expired. The caller holds mu.
+func (simulator *Simulator) open(challengeId string) (*challenge, bool) {
+	open, ok := simulator.challenges[challengeId]
+	if !ok {
+		return nil, false
+	}
+	if !simulator.clock.Now().Before(open.expiresAt) {
+		delete(simulator.challenges, challengeId)
+		return nil, false
+	}
+	return open, true
+}
+
+func newResult(response string) Result {
+	switch response {
+	case AUTHENTICATED:
+		return Result{Status: AUTHENTICATED, ECI: "05", CAVV: newCAVV(), LiabilityShift: true}
+	case ATTEMPTED:
+		return Result{Status: ATTEMPTED, ECI: "06", CAVV: newCAVV(), LiabilityShift: true}
+	default:
+		return Result{Status: FAILED, ECI: "07"}
+	}
+}
+
+var challengePage = template.Must(template.New("challenge").Parse(`<!DOCTYPE html>
+<html>
+<body>
+<h1>3-D Secure simulator</h1>
+<p>Payment of {{.Amount}} {{.Currency}}</p>
+<form method="post">
+<button name="response" value="Y">Authenticate</button>
+<button name="response" value="A">Attempt</button>
+<button name="response" value="N">Fail</button>
+</form>
+</body>
+</html>
+`))
+
+// notificationPage posts the challenge id, and nothing the cardholder could
+// change the outcome with, to the notification URL.
+var notificationPage = template.Must(template.New("notification").Parse(`<!DOCTYPE html>
+<html>
+<body onload="document.forms[0].submit()">
+<form method="post" action="{{.NotificationURL}}">
+<input type="hidden" name="challenge_id" value="{{.ChallengeId}}">
+<noscript><button>Continue</button></noscript>
+</form>
+</body>
+</html>
+`))
+
+// ServeHTTP shows the challenge page on GET and records the answer posted
+// from it.
+func (simulator *Simulator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	challengeId := strings.TrimPrefix(r.URL.Path, CHALLENGE_PATH)
+	simulator.mu.Lock()
+	open, ok := simulator.open(challengeId)
+	simulator.mu.Unlock()
+	if !ok || open.result != nil {
+		http.NotFound(w, r)
+		return
+	}
+	page := challengePage
+	if r.Method == http.MethodPost {
+		if err := simulator.Respond(challengeId, r.PostFormValue("response")); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		page = notificationPage
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	page.Execute(w, struct {
+		ChallengeRequest
+		ChallengeId string
+	}{open.request, challengeId})
+}
+
+func newCAVV() string {
+	cavv := make([]byte, 20)
+	rand.Read(cavv)
+	return base64.StdEncoding.EncodeToString(cavv)
+}
@@ -0,0 +1,51 @@
+package threeds
+
+import "errors"
+
+const (
+	AUTHENTICATED string = "Y"
+	ATTEMPTED            = "A"
+	FAILED               = "N"
+)
+
+var (
+	ErrChallengeNotFound = errors.New("3-D Secure challenge not found")
+	ErrChallengePending  = errors.New("3-D Secure challenge has not been answered by the cardholder")
+)
+
+type ChallengeRequest struct {
+	PaymentId  string
+	CardNumber string
+	Currency   string
+	Amount     int
+	// NotificationURL is told the challenge id once the cardholder has
+	// answered the challenge. The outcome itself is only ever read back from
+	// the directory server.
+	NotificationURL string
+}
+
+type Challenge struct {
+	Id  string
+	URL string
+}
+
+// Result is the outcome of a challenge. LiabilityShift reports whether
+// fraud chargebacks move from the merchant to the issuer.
+type Result struct {
+	Status         string
+	ECI            string
+	CAVV           string
+	LiabilityShift bool
+}
+
+func (result Result) Authenticated() bool {
+	return result.Status == AUTHENTICATED || result.Status == ATTEMPTED
+}
+
+// DirectoryServer issues cardholder challenges and reports their outcome.
+// ChallengeResult returns ErrChallengePending until the cardholder has
+// answered.
+type DirectoryServer interface {
+	InitiateChallenge(request ChallengeRequest) (Challenge, error)
+	ChallengeResult(challengeId string) (Result, error)
+}
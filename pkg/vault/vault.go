@@ -0,0 +1,142 @@
+// Package vault exchanges card numbers for opaque tokens, so that payments,
+// mandates and saved cards can refer to a card without holding its number.
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/cards"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/clock"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/eventlog"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ids"
+)
+
+const CARD_TOKENIZED string = "card_tokenized"
+
+var ErrTokenNotFound = errors.New("card token not found")
+
+// tokenizedData is the event written for a new token. The card number is
+// only ever written encrypted, with the token as additional data so that a
+// ciphertext cannot be moved to another token.
+type tokenizedData struct {
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Vault keeps card numbers behind tokens. A card tokenized twice gets the
+// same token back.
+type Vault struct {
+	mu      sync.RWMutex
+	log     eventlog.Log
+	clock   clock.Clock
+	aead    cipher.AEAD
+	numbers map[string]string
+	tokens  map[string]string
+}
+
+// New returns a vault that keeps card numbers in memory only.
+func New() *Vault {
+	return &Vault{
+		numbers: make(map[string]string),
+		tokens:  make(map[string]string),
+	}
+}
+
+// Open returns a vault that writes card numbers to log, encrypted with the
+// 32 byte AES key, and recovers the tokens already in it. Events are
+// timestamped with clock.
+func Open(log eventlog.Log, key []byte, clock clock.Clock) (*Vault, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("vault key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	vault := New()
+	vault.log = log
+	vault.clock = clock
+	vault.aead = aead
+	err = log.Replay(0, func(event eventlog.Event) error {
+		if event.Type != CARD_TOKENIZED {
+			return nil
+		}
+		var data tokenizedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		number, err := aead.Open(nil, data.Nonce, data.Ciphertext, []byte(event.AggregateId))
+		if err != nil {
+			return fmt.Errorf("could not decrypt card token %s: %w", event.AggregateId, err)
+		}
+		vault.numbers[event.AggregateId] = string(number)
+		vault.tokens[cards.Fingerprint(string(number))] = event.AggregateId
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vault, nil
+}
+
+// Tokenize returns the token for cardNumber, creating one the first time the
+// card is seen.
+func (vault *Vault) Tokenize(cardNumber string) (string, error) {
+	fingerprint := cards.Fingerprint(cardNumber)
+	vault.mu.Lock()
+	defer vault.mu.Unlock()
+	if token, ok := vault.tokens[fingerprint]; ok {
+		return token, nil
+	}
+
+	token, err := ids.New(ids.CARD)
+	if err != nil {
+		return "", err
+	}
+	if vault.log != nil {
+		nonce := make([]byte, vault.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+		data, err := json.Marshal(tokenizedData{
+			Nonce:      nonce,
+			Ciphertext: vault.aead.Seal(nil, nonce, []byte(cardNumber), []byte(token)),
+		})
+		if err != nil {
+			return "", err
+		}
+		_, err = vault.log.Append(eventlog.Event{
+			AggregateId: token,
+			Type:        CARD_TOKENIZED,
+			Timestamp:   vault.clock.Now(),
+			Data:        data,
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	vault.numbers[token] = cardNumber
+	vault.tokens[fingerprint] = token
+	return token, nil
+}
+
+// Detokenize returns the card number behind token.
+func (vault *Vault) Detokenize(token string) (string, error) {
+	vault.mu.RLock()
+	defer vault.mu.RUnlock()
+	number, ok := vault.numbers[token]
+	if !ok {
+		return "", ErrTokenNotFound
+	}
+	return number, nil
+}
@@ -0,0 +1,133 @@
+package reports
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/models"
+)
+
+const (
+	ROW_CAPTURE string = "capture"
+	ROW_REFUND         = "refund"
+)
+
+const (
+	UNKNOWN_PAYMENT         string = "unknown_payment"
+	CURRENCY_MISMATCH              = "currency_mismatch"
+	CAPTURE_AMOUNT_MISMATCH        = "capture_amount_mismatch"
+	REFUND_AMOUNT_MISMATCH         = "refund_amount_mismatch"
+	MISSING_FROM_FILE              = "missing_from_file"
+)
+
+var ErrInvalidSettlementFile = errors.New("invalid settlement file")
+
+// SettlementRow is a line of an acquirer settlement file, in the currency
+// the payment was presented in.
+type SettlementRow struct {
+	PaymentId string
+	Type      string
+	Currency  string
+	Amount    int
+}
+
+type Discrepancy struct {
+	PaymentId string
+	Code      string
+	Expected  string
+	Actual    string
+}
+
+type Reconciliation struct {
+	Matched       int
+	Discrepancies []Discrepancy
+}
+
+// ParseSettlementFile reads a CSV file with the header
+// payment_id,type,currency,amount where type is capture or refund.
+func ParseSettlementFile(r io.Reader) ([]SettlementRow, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSettlementFile, err)
+	}
+	if len(records) == 0 || strings.Join(records[0], ",") != "payment_id,type,currency,amount" {
+		return nil, fmt.Errorf("%w: expected header payment_id,type,currency,amount", ErrInvalidSettlementFile)
+	}
+	rows := make([]SettlementRow, 0, len(records)-1)
+	for line, record := range records[1:] {
+		amount, err := strconv.Atoi(record[3])
+		if err != nil || (record[1] != ROW_CAPTURE && record[1] != ROW_REFUND) {
+			return nil, fmt.Errorf("%w: line %d", ErrInvalidSettlementFile, line+2)
+		}
+		rows = append(rows, SettlementRow{PaymentId: record[0], Type: record[1], Currency: strings.ToUpper(record[2]), Amount: amount})
+	}
+	return rows, nil
+}
+
+// Reconcile matches the acquirer's rows against the stored payments. The
+// captures and refunds of each payment are summed before comparing, and
+// every payment in expected that the file does not mention is flagged as
+// missing.
+func Reconcile(rows []SettlementRow, lookup func(paymentId string) (models.Payment, bool), expected []models.Payment) Reconciliation {
+	type totals struct {
+		currency string
+		captured int
+		refunded int
+	}
+	byPayment := make(map[string]*totals)
+	order := make([]string, 0)
+	for _, row := range rows {
+		total := byPayment[row.PaymentId]
+		if total == nil {
+			total = &totals{currency: row.Currency}
+			byPayment[row.PaymentId] = total
+			order = append(order, row.PaymentId)
+		}
+		if row.Type == ROW_CAPTURE {
+			total.captured += row.Amount
+		} else {
+			total.refunded += row.Amount
+		}
+	}
+
+	reconciliation := Reconciliation{Discrepancies: make([]Discrepancy, 0)}
+	for _, paymentId := range order {
+		total := byPayment[paymentId]
+		payment, ok := lookup(paymentId)
+		if !ok {
+			reconciliation.Discrepancies = append(reconciliation.Discrepancies, Discrepancy{PaymentId: paymentId, Code: UNKNOWN_PAYMENT})
+			continue
+		}
+		found := make([]Discrepancy, 0)
+		if payment.CurrencyCode != total.currency {
+			found = append(found, Discrepancy{PaymentId: paymentId, Code: CURRENCY_MISMATCH, Expected: payment.CurrencyCode, Actual: total.currency})
+		}
+		if payment.CapturedAmount != total.captured {
+			found = append(found, Discrepancy{PaymentId: paymentId, Code: CAPTURE_AMOUNT_MISMATCH, Expected: strconv.Itoa(payment.CapturedAmount), Actual: strconv.Itoa(total.captured)})
+		}
+		if payment.RefundedAmount != total.refunded {
+			found = append(found, Discrepancy{PaymentId: paymentId, Code: REFUND_AMOUNT_MISMATCH, Expected: strconv.Itoa(payment.RefundedAmount), Actual: strconv.Itoa(total.refunded)})
+		}
+		if len(found) == 0 {
+			reconciliation.Matched++
+		}
+		reconciliation.Discrepancies = append(reconciliation.Discrepancies, found...)
+	}
+
+	missing := make([]string, 0)
+	for _, payment := range expected {
+		if _, ok := byPayment[payment.Id]; !ok {
+			missing = append(missing, payment.Id)
+		}
+	}
+	sort.Strings(missing)
+	for _, paymentId := range missing {
+		reconciliation.Discrepancies = append(reconciliation.Discrepancies, Discrepancy{PaymentId: paymentId, Code: MISSING_FROM_FILE})
+	}
+	return reconciliation
+}
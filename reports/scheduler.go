@@ -0,0 +1,99 @@
+package reports
+
+import (
+	"log"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/ledger"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/clock"
+)
+
+// Scheduler produces each day's settlement reports once the day is over.
+type Scheduler struct {
+	store    *Store
+	entries  func() []ledger.JournalEntry
+	location *time.Location
+	// lastDate is the last day checked for reports.
+	lastDate string
+	clock    clock.Clock
+}
+
+func NewScheduler(store *Store, entries func() []ledger.JournalEntry, location *time.Location, clock clock.Clock) *Scheduler {
+	return &Scheduler{store: store, entries: entries, location: location, clock: clock}
+}
+
+// RunDaily generates the reports for every finished day up to the day
+// before now that has none yet. It carries on from the last day with a
+// report, or the first day in the ledger, so days missed while the gateway
+// was down are caught up.
+func (scheduler *Scheduler) RunDaily(now time.Time) ([]Report, error) {
+	local := now.In(scheduler.location)
+	yesterday := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, scheduler.location).AddDate(0, 0, -1)
+	entries := scheduler.entries()
+	day, ok := scheduler.firstUnchecked(entries)
+	if !ok {
+		return nil, nil
+	}
+	generated := make([]Report, 0)
+	for ; !day.After(yesterday); day = day.AddDate(0, 0, 1) {
+		date := day.Format(DATE_LAYOUT)
+		if !scheduler.store.Generated(date) {
+			reports, err := Generate(entries, day, now)
+			if err != nil {
+				return generated, err
+			}
+			for _, report := range reports {
+				if err := scheduler.store.Save(report); err != nil {
+					return generated, err
+				}
+			}
+			generated = append(generated, reports...)
+		}
+		scheduler.lastDate = date
+	}
+	return generated, nil
+}
+
+// firstUnchecked returns the day after the last one checked or reported,
+// or the day of the first ledger entry. It reports false when there is
+// nothing to report on yet.
+func (scheduler *Scheduler) firstUnchecked(entries []ledger.JournalEntry) (time.Time, bool) {
+	last := scheduler.lastDate
+	if last == "" {
+		last = scheduler.store.LastDate()
+	}
+	if last != "" {
+		day, err := time.ParseInLocation(DATE_LAYOUT, last, scheduler.location)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return day.AddDate(0, 0, 1), true
+	}
+	if len(entries) == 0 {
+		return time.Time{}, false
+	}
+	first := entries[0].CreatedAt
+	for _, entry := range entries[1:] {
+		if entry.CreatedAt.Before(first) {
+			first = entry.CreatedAt
+		}
+	}
+	first = first.In(scheduler.location)
+	return time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, scheduler.location), true
+}
+
+// Start checks for a finished day every interval until stop is closed.
+func (scheduler *Scheduler) Start(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := scheduler.RunDaily(scheduler.clock.Now()); err != nil {
+			log.Printf("could not generate settlement reports: %v", err)
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package reports
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/ledger"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ids"
+)
+
+const DATE_LAYOUT = "2006-01-02"
+
+// Report is a merchant's settlement for one day, with a row per currency.
+type Report struct {
+	Id          string    `json:"id"`
+	MerchantId  string    `json:"merchant_id"`
+	Date        string    `json:"date"`
+	Rows        []Row     `json:"rows"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// Row totals the captures, fees and refunds posted to the merchant in one
+// currency. Net is what the merchant is owed for the day.
+type Row struct {
+	Currency    string `json:"currency"`
+	Gross       int    `json:"gross"`
+	Fees        int    `json:"fees"`
+	Refunds     int    `json:"refunds"`
+	Net         int    `json:"net"`
+	Captures    int    `json:"captures"`
+	RefundCount int    `json:"refund_count"`
+}
+
+// Generate builds a report for every merchant with captures, fees or
+// refunds posted on day, which starts at midnight in day's location.
+func Generate(entries []ledger.JournalEntry, day time.Time, generatedAt time.Time) ([]Report, error) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	end := start.AddDate(0, 0, 1)
+
+	rows := make(map[string]map[string]*Row)
+	for _, entry := range entries {
+		if entry.CreatedAt.Before(start) || !entry.CreatedAt.Before(end) {
+			continue
+		}
+		if entry.Type != ledger.ENTRY_CAPTURE && entry.Type != ledger.ENTRY_FEE && entry.Type != ledger.ENTRY_REFUND {
+			continue
+		}
+		for _, posting := range entry.Postings {
+			if posting.Account != ledger.MerchantAvailableAccount(entry.MerchantId) {
+				continue
+			}
+			if rows[entry.MerchantId] == nil {
+				rows[entry.MerchantId] = make(map[string]*Row)
+			}
+			row := rows[entry.MerchantId][posting.Currency]
+			if row == nil {
+				row = &Row{Currency: posting.Currency}
+				rows[entry.MerchantId][posting.Currency] = row
+			}
+			switch entry.Type {
+			case ledger.ENTRY_CAPTURE:
+				row.Gross -= posting.Amount
+				row.Captures++
+			case ledger.ENTRY_FEE:
+				row.Fees += posting.Amount
+			case ledger.ENTRY_REFUND:
+				row.Refunds += posting.Amount
+				row.RefundCount++
+			}
+		}
+	}
+
+	merchantIds := make([]string, 0, len(rows))
+	for merchantId := range rows {
+		merchantIds = append(merchantIds, merchantId)
+	}
+	sort.Strings(merchantIds)
+
+	reports := make([]Report, 0, len(merchantIds))
+	for _, merchantId := range merchantIds {
+		id, err := ids.New(ids.REPORT)
+		if err != nil {
+			return nil, err
+		}
+		report := Report{Id: id, MerchantId: merchantId, Date: start.Format(DATE_LAYOUT), GeneratedAt: generatedAt}
+		for _, row := range rows[merchantId] {
+			row.Net = row.Gross - row.Fees - row.Refunds
+			report.Rows = append(report.Rows, *row)
+		}
+		sort.Slice(report.Rows, func(i, j int) bool { return report.Rows[i].Currency < report.Rows[j].Currency })
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+var csvHeader = []string{"date", "merchant_id", "currency", "gross", "fees", "refunds", "net", "captures", "refund_count"}
+
+func WriteCSV(w io.Writer, report Report) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, row := range report.Rows {
+		record := []string{
+			report.Date,
+			report.MerchantId,
+			row.Currency,
+			strconv.Itoa(row.Gross),
+			strconv.Itoa(row.Fees),
+			strconv.Itoa(row.Refunds),
+			strconv.Itoa(row.Net),
+			strconv.Itoa(row.Captures),
+			strconv.Itoa(row.RefundCount),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
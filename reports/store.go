@@ -0,0 +1,127 @@
+package reports
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Store keeps generated reports. When dir is set every report is also
+// written there as <id>.json and <id>.csv, and Load reads them back.
+type Store struct {
+	mu      sync.RWMutex
+	dir     string
+	reports map[string]Report
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir, reports: make(map[string]Report)}
+}
+
+func (store *Store) Load() error {
+	if store.dir == "" {
+		return nil
+	}
+	paths, err := filepath.Glob(filepath.Join(store.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var report Report
+		if err := json.Unmarshal(content, &report); err != nil {
+			return err
+		}
+		store.reports[report.Id] = report
+	}
+	return nil
+}
+
+func (store *Store) Save(report Report) error {
+	if store.dir != "" {
+		if err := store.write(report); err != nil {
+			return err
+		}
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.reports[report.Id] = report
+	return nil
+}
+
+func (store *Store) write(report Report) error {
+	if err := os.MkdirAll(store.dir, 0o755); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(store.dir, report.Id+".json"), content, 0o644); err != nil {
+		return err
+	}
+	file, err := os.Create(filepath.Join(store.dir, report.Id+".csv"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return WriteCSV(file, report)
+}
+
+func (store *Store) Get(id string) (Report, bool) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	report, ok := store.reports[id]
+	return report, ok
+}
+
+// List returns the merchant's reports, newest day first.
+func (store *Store) List(merchantId string) []Report {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	reports := make([]Report, 0)
+	for _, report := range store.reports {
+		if report.MerchantId == merchantId {
+			reports = append(reports, report)
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Date != reports[j].Date {
+			return reports[i].Date > reports[j].Date
+		}
+		return reports[i].Id > reports[j].Id
+	})
+	return reports
+}
+
+// Generated reports whether the reports for date were already produced.
+func (store *Store) Generated(date string) bool {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	for _, report := range store.reports {
+		if report.Date == date {
+			return true
+		}
+	}
+	return false
+}
+
+// LastDate returns the latest day with a report, or "" when there is none.
+func (store *Store) LastDate() string {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	last := ""
+	for _, report := range store.reports {
+		if report.Date > last {
+			last = report.Date
+		}
+	}
+	return last
+}
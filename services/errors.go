@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/customers"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/fx"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/http_clients"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/mandates"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/routing"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/threeds"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/store"
+)
+
+// The kinds of error returned by the service, to be matched with errors.Is.
+// Errors of any other kind are internal failures.
+var (
+	// ErrRejected is a payment that cannot be attempted as requested, such
+	// as one with missing card details or an expired card.
+	ErrRejected = errors.New("payment rejected")
+	// ErrInvalid is an operation with an invalid id or amount.
+	ErrInvalid  = errors.New("invalid request")
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is an operation the payment or mandate no longer allows.
+	ErrConflict = errors.New("conflict")
+	// ErrPreconditionFailed is an update made on a payment version that is
+	// no longer current.
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrUnavailable is an acquiring bank or 3-D Secure directory server
+	// that could not be reached.
+	ErrUnavailable = errors.New("unavailable")
+	// ErrOutcomeUnknown is an acquiring bank that did not return a decision
+	// after the payment may have reached it.
+	ErrOutcomeUnknown = errors.New("outcome unknown")
+)
+
+var (
+	ErrCardExpired       = errors.New("expiry date must be in future date")
+	errAmountNotPositive = errors.New("amount must be greater than zero")
+	errPendingExpired    = errors.New("card details for this challenge are no longer available")
+)
+
+// Error is an error of one of the kinds above. It reads and unwraps as the
+// error that caused it.
+type Error struct {
+	Kind error
+	Err  error
+}
+
+func (err *Error) Error() string {
+	return err.Err.Error()
+}
+
+func (err *Error) Unwrap() error {
+	return err.Err
+}
+
+func (err *Error) Is(target error) bool {
+	return target == err.Kind
+}
+
+func newError(kind error, err error) error {
+	return &Error{Kind: kind, Err: err}
+}
+
+// classify gives errors from the stores, the bank and the providers the
+// kind the service reports them as.
+func classify(err error) error {
+	var serviceErr *Error
+	if err == nil || errors.As(err, &serviceErr) {
+		return err
+	}
+	switch {
+	case errors.Is(err, ErrCardExpired), errors.Is(err, fx.ErrRateNotFound):
+		return newError(ErrRejected, err)
+	case errors.Is(err, store.ErrInvalidAmount), errors.Is(err, threeds.ErrChallengeNotFound):
+		return newError(ErrInvalid, err)
+	case errors.Is(err, store.ErrPaymentNotFound), errors.Is(err, customers.ErrCustomerNotFound),
+		errors.Is(err, customers.ErrPaymentMethodNotFound), errors.Is(err, mandates.ErrMandateNotFound):
+		return newError(ErrNotFound, err)
+	case errors.Is(err, store.ErrInvalidTransition), errors.Is(err, mandates.ErrMandateRevoked):
+		return newError(ErrConflict, err)
+	case errors.Is(err, store.ErrVersionMismatch):
+		return newError(ErrPreconditionFailed, err)
+	case errors.Is(err, http_clients.ErrOutcomeUnknown):
+		return newError(ErrOutcomeUnknown, err)
+	case errors.Is(err, routing.ErrNoAcquirer), http_clients.IsTransient(err):
+		return newError(ErrUnavailable, err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/models"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/cards"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/eventlog"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ids"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/mandates"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/store"
+)
+
+// MandatePaymentInput is a merchant-initiated payment on a mandate.
+type MandatePaymentInput struct {
+	MandateId  string
+	MerchantId string
+	Currency   string `validate:"required,iso4217"`
+	Amount     int    `validate:"required,gt=0"`
+}
+
+// ChargeMandate creates a merchant-initiated payment on the mandate's card.
+// It skips 3-D Secure and is sent to the bank without a CVV.
+func (service *PaymentService) ChargeMandate(input MandatePaymentInput) (models.Payment, error) {
+	if err := ids.Validate(ids.MANDATE, input.MandateId); err != nil {
+		return models.Payment{}, newError(ErrInvalid, err)
+	}
+	if err := inputValidator.Struct(input); err != nil {
+		return models.Payment{}, newError(ErrRejected, err)
+	}
+	deps := service.dependencies()
+	mandate, err := deps.Mandates.Active(input.MandateId, input.MerchantId)
+	if err != nil {
+		return models.Payment{}, classify(err)
+	}
+	cardNumber, err := deps.Vault.Detokenize(mandate.CardToken)
+	if err != nil {
+		return models.Payment{}, err
+	}
+	ID, err := ids.NewPaymentId()
+	if err != nil {
+		return models.Payment{}, err
+	}
+	paymentModel, err := service.processPayment(deps, ID, paymentInput{
+		MerchantId:        input.MerchantId,
+		CardNumber:        cardNumber,
+		ExpirationMonth:   mandate.ExpirationMonth,
+		ExpirationYear:    mandate.ExpirationYear,
+		Currency:          input.Currency,
+		Amount:            input.Amount,
+		MandateId:         mandate.Id,
+		MerchantInitiated: true,
+	})
+	return paymentModel, classify(err)
+}
+
+// appendWithMandate records an authorized payment together with the mandate
+// it sets up. The mandate is revoked again if the payment cannot be stored.
+func appendWithMandate(deps Dependencies, ID string, merchantId string, cardNumber string, expirationMonth int, expirationYear int, events ...eventlog.Event) (models.Payment, error) {
+	cardToken, err := deps.Vault.Tokenize(cardNumber)
+	if err != nil {
+		return models.Payment{}, err
+	}
+	mandate, err := deps.Mandates.Create(mandates.Mandate{
+		MerchantId:       merchantId,
+		CardToken:        cardToken,
+		CardLast4:        cards.Last4(cardNumber),
+		CardFingerprint:  cards.Fingerprint(cardNumber),
+		ExpirationMonth:  expirationMonth,
+		ExpirationYear:   expirationYear,
+		InitialPaymentId: ID,
+		CreatedAt:        deps.Clock.Now(),
+	})
+	if err != nil {
+		return models.Payment{}, err
+	}
+	mandateCreated, err := store.NewEvent(ID, store.MANDATE_CREATED, enums.ACTOR_GATEWAY, "stored credential mandate created", deps.Clock.Now(), store.MandateCreatedData{MandateId: mandate.Id})
+	if err != nil {
+		deps.Mandates.Revoke(mandate.Id, deps.Clock.Now())
+		return models.Payment{}, err
+	}
+	paymentModel, err := deps.Store.Append(ID, append(events, mandateCreated)...)
+	if err != nil {
+		deps.Mandates.Revoke(mandate.Id, deps.Clock.Now())
+		return models.Payment{}, err
+	}
+	return paymentModel, nil
+}
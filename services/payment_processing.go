@@ -0,0 +1,228 @@
+package services
+
+import (
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/mapper"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/models"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/bins"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/blocklist"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/cards"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/eventlog"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/fx"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/risk"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/routing"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/store"
+)
+
+// paymentInput is a payment to process, whether it came from a cardholder
+// or was initiated by the merchant on a mandate.
+type paymentInput struct {
+	MerchantId      string
+	CustomerId      string
+	CardNumber      string
+	ExpirationMonth int
+	ExpirationYear  int
+	Currency        string
+	Amount          int
+	CVV             string
+	Email           string
+	IP              string
+	ThreeDS         bool
+	SetupMandate    bool
+	// MandateId and MerchantInitiated are set on payments charged on a
+	// mandate, which skip 3-D Secure and are sent to the bank without a CVV.
+	MandateId         string
+	MerchantInitiated bool
+}
+
+// applyCustomer records the customer on the payment and, when a saved
+// payment method is given, fills in its card details.
+func applyCustomer(deps Dependencies, input *paymentInput, customerId string, paymentMethodId string) error {
+	if paymentMethodId == "" {
+		_, err := deps.Customers.Get(customerId, input.MerchantId)
+		if err != nil {
+			return err
+		}
+		input.CustomerId = customerId
+		return nil
+	}
+	method, err := deps.Customers.PaymentMethod(customerId, input.MerchantId, paymentMethodId)
+	if err != nil {
+		return err
+	}
+	cardNumber, err := deps.Vault.Detokenize(method.CardToken)
+	if err != nil {
+		return err
+	}
+	input.CustomerId = customerId
+	input.CardNumber = cardNumber
+	input.ExpirationMonth = method.ExpirationMonth
+	input.ExpirationYear = method.ExpirationYear
+	return nil
+}
+
+// processPayment screens the payment, asks the bank for a decision and
+// records the outcome.
+func (service *PaymentService) processPayment(deps Dependencies, ID string, input paymentInput) (models.Payment, error) {
+	expiryDate, err := buildExpiryDate(deps, input.ExpirationMonth, input.ExpirationYear)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	cardToken, err := deps.Vault.Tokenize(input.CardNumber)
+	if err != nil {
+		return models.Payment{}, err
+	}
+	cardFingerprint := cards.Fingerprint(input.CardNumber)
+	var bin *bins.Info
+	if deps.Bins != nil {
+		if info, ok := deps.Bins.Lookup(input.CardNumber); ok {
+			bin = &info
+		}
+	}
+	requestedData := store.PaymentRequestedData{
+		MerchantId:        input.MerchantId,
+		CustomerId:        input.CustomerId,
+		CardToken:         cardToken,
+		CardLast4:         cards.Last4(input.CardNumber),
+		CardFingerprint:   cardFingerprint,
+		ExpirationMonth:   input.ExpirationMonth,
+		ExpirationYear:    input.ExpirationYear,
+		CurrencyCode:      input.Currency,
+		Amount:            input.Amount,
+		MandateId:         input.MandateId,
+		MerchantInitiated: input.MerchantInitiated,
+	}
+	if bin != nil {
+		requestedData.Bin = mapper.ToBinData(*bin)
+	}
+	requestedAt := deps.Clock.Now()
+	requested, err := store.NewEvent(ID, store.PAYMENT_REQUESTED, enums.ACTOR_GATEWAY, "payment requested", requestedAt, requestedData)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	if entry, blocked := deps.Blocklist.Match(blocklist.Subject{
+		CardFingerprint: cardFingerprint,
+		CardNumber:      input.CardNumber,
+		IP:              input.IP,
+		Email:           input.Email,
+	}, requestedAt); blocked {
+		blockedEvent, err := store.NewEvent(ID, store.PAYMENT_BLOCKED, enums.ACTOR_BLOCKLIST, entry.Reason, deps.Clock.Now(), store.PaymentBlockedData{
+			ReasonCode: entry.ReasonCode(),
+			EntryId:    entry.Id,
+		})
+		if err != nil {
+			return models.Payment{}, err
+		}
+		return deps.Store.Append(ID, requested, blockedEvent)
+	}
+
+	assessment := deps.Risk.Evaluate(risk.Input{
+		CardNumber:      input.CardNumber,
+		CardFingerprint: cardFingerprint,
+		IP:              input.IP,
+		Currency:        input.Currency,
+		Amount:          input.Amount,
+		Bin:             bin,
+	}, requestedAt)
+	assessed, err := store.NewEvent(ID, store.RISK_ASSESSED, enums.ACTOR_RISK_ENGINE, "risk decision: "+assessment.Decision, deps.Clock.Now(), mapper.ToRiskAssessedData(assessment))
+	if err != nil {
+		return models.Payment{}, err
+	}
+	if assessment.Decision == risk.BLOCK {
+		return deps.Store.Append(ID, requested, assessed)
+	}
+
+	bankPayment := routing.Payment{
+		CardNumber:        input.CardNumber,
+		ExpiryDate:        expiryDate,
+		Currency:          input.Currency,
+		Amount:            input.Amount,
+		CVV:               input.CVV,
+		MerchantInitiated: input.MerchantInitiated,
+		Bin:               bin,
+	}
+	if input.ThreeDS && !input.MerchantInitiated {
+		if _, err := quoteSettlement(deps, ID, input.Currency, input.Amount); err != nil {
+			return models.Payment{}, err
+		}
+		return service.startThreeDSChallenge(deps, ID, pendingAuthentication{Payment: bankPayment, SetupMandate: input.SetupMandate}, requested, assessed)
+	}
+
+	authorization, err := authorizationEvents(deps, ID, bankPayment)
+	if err != nil {
+		return models.Payment{}, err
+	}
+	events := append([]eventlog.Event{requested, assessed}, authorization...)
+	if !authorized(authorization) || !input.SetupMandate || input.MerchantInitiated {
+		return deps.Store.Append(ID, events...)
+	}
+	return appendWithMandate(deps, ID, input.MerchantId, input.CardNumber, input.ExpirationMonth, input.ExpirationYear, events...)
+}
+
+// authorizationEvents asks the bank for a decision. An authorized payment
+// taken in a currency other than the settlement currency is preceded by the
+// conversion quoted just before the bank was called.
+func authorizationEvents(deps Dependencies, ID string, bankPayment routing.Payment) ([]eventlog.Event, error) {
+	converted, err := quoteSettlement(deps, ID, bankPayment.Currency, bankPayment.Amount)
+	if err != nil {
+		return nil, err
+	}
+	decision, err := authorizeWithBank(deps, ID, bankPayment)
+	if err != nil {
+		return nil, err
+	}
+	if converted != nil && decision.Type == store.BANK_AUTHORIZED {
+		return []eventlog.Event{*converted, decision}, nil
+	}
+	return []eventlog.Event{decision}, nil
+}
+
+func authorized(events []eventlog.Event) bool {
+	return len(events) > 0 && events[len(events)-1].Type == store.BANK_AUTHORIZED
+}
+
+// authorizeWithBank asks the routed acquirer for a decision and returns it
+// as an event ready to be appended.
+func authorizeWithBank(deps Dependencies, ID string, bankPayment routing.Payment) (eventlog.Event, error) {
+	result, err := deps.Router.Authorize(bankPayment)
+	if err != nil {
+		return eventlog.Event{}, err
+	}
+	decisionType := store.BANK_DECLINED
+	if result.Status == enums.AUTHORIZED {
+		decisionType = store.BANK_AUTHORIZED
+	}
+	return store.NewEvent(ID, decisionType, enums.ACTOR_ACQUIRING_BANK, bankDecisionReason(result.Status), deps.Clock.Now(), store.BankDecisionData{Acquirer: result.Acquirer})
+}
+
+func bankDecisionReason(status string) string {
+	if status == enums.AUTHORIZED {
+		return "authorized by acquiring bank"
+	}
+	return "declined by acquiring bank"
+}
+
+// quoteSettlement returns the conversion to record for the payment, or nil
+// when it settles in the currency it was taken in.
+func quoteSettlement(deps Dependencies, ID string, currency string, amount int) (*eventlog.Event, error) {
+	if deps.SettlementCurrency == "" || currency == deps.SettlementCurrency {
+		return nil, nil
+	}
+	rate, err := deps.FX.Rate(currency, deps.SettlementCurrency)
+	if err != nil {
+		return nil, err
+	}
+	converted, err := store.NewEvent(ID, store.FX_CONVERTED, enums.ACTOR_GATEWAY, "converted to settlement currency", deps.Clock.Now(), store.FXConvertedData{
+		SettlementCurrency: deps.SettlementCurrency,
+		SettlementAmount:   fx.Convert(amount, rate),
+		Rate:               rate.Rate,
+		RateTimestamp:      rate.Timestamp,
+		RateSource:         rate.Source,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &converted, nil
+}
@@ -0,0 +1,244 @@
+// Package services holds the payment domain logic shared by the REST and
+// gRPC APIs and the background jobs.
+package services
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/models"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/bins"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/blocklist"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/cards"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/clock"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/customers"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/fx"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ids"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/mandates"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/risk"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/routing"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/threeds"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/vault"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/store"
+	"github.com/go-playground/validator/v10"
+)
+
+// Dependencies are the stores, engines and providers payments go through.
+type Dependencies struct {
+	Store           *store.PaymentStore
+	Risk            *risk.Engine
+	Router          *routing.Router
+	Blocklist       *blocklist.Blocklist
+	Customers       *customers.Store
+	Mandates        *mandates.Store
+	DirectoryServer threeds.DirectoryServer
+	// SettlementCurrency is the currency payments taken in other currencies
+	// are converted into at FX's rates. Empty turns conversion off.
+	SettlementCurrency string
+	FX                 fx.Provider
+	// ExpiryLocation is the timezone in which card expiry months end. It
+	// defaults to UTC.
+	ExpiryLocation *time.Location
+	// Bins looks up the issuer of a payment's card. Payments carry no issuer
+	// details when it is nil.
+	Bins bins.Lookup
+	// Vault holds the card numbers payments refer to by token.
+	Vault *vault.Vault
+	// Clock timestamps events and decides when cards expire. It defaults
+	// to the system clock.
+	Clock clock.Clock
+}
+
+type PaymentService struct {
+	mu   sync.RWMutex
+	deps Dependencies
+	// pending holds the card details of open 3-D Secure challenges until
+	// they are completed or expire. The CVV must never be persisted, so open
+	// challenges do not survive a restart.
+	pending map[string]*pendingAuthentication
+}
+
+func NewPaymentService(deps Dependencies) *PaymentService {
+	service := &PaymentService{pending: make(map[string]*pendingAuthentication)}
+	service.SetDependencies(deps)
+	return service
+}
+
+// SetDependencies replaces the service's dependencies. Open 3-D Secure
+// challenges are kept.
+func (service *PaymentService) SetDependencies(deps Dependencies) {
+	if deps.ExpiryLocation == nil {
+		deps.ExpiryLocation = time.UTC
+	}
+	if deps.Clock == nil {
+		deps.Clock = clock.Real{}
+	}
+	service.mu.Lock()
+	service.deps = deps
+	service.mu.Unlock()
+}
+
+func (service *PaymentService) dependencies() Dependencies {
+	service.mu.RLock()
+	defer service.mu.RUnlock()
+	return service.deps
+}
+
+// CreatePaymentInput is a payment made by a cardholder. PaymentMethodId
+// pays with a card saved on CustomerId instead of the card fields.
+type CreatePaymentInput struct {
+	MerchantId      string
+	IP              string
+	CardNumber      string `validate:"required_without=PaymentMethodId,excluded_with=PaymentMethodId,omitempty,gte=14,lte=19,number"`
+	ExpirationMonth int    `validate:"required_without=PaymentMethodId,omitempty,gte=1,lte=12"`
+	ExpirationYear  int    `validate:"required_without=PaymentMethodId"`
+	Currency        string `validate:"required,iso4217"`
+	Amount          int    `validate:"required"`
+	CVV             string `validate:"required,number,gte=3,lte=4"`
+	Email           string `validate:"omitempty,email"`
+	ThreeDS         bool
+	// SetupMandate stores the card for later merchant-initiated payments
+	// once this payment is authorized.
+	SetupMandate    bool
+	CustomerId      string `validate:"required_with=PaymentMethodId"`
+	PaymentMethodId string
+}
+
+// PaymentFilter selects payments. Empty fields match every payment.
+type PaymentFilter struct {
+	MerchantId string
+	CustomerId string
+	Status     string
+}
+
+var inputValidator = validator.New()
+
+// CreatePayment screens the payment, asks the bank for a decision and
+// records the outcome. A payment that was blocked, declined or needs 3-D
+// Secure is returned without an error.
+func (service *PaymentService) CreatePayment(input CreatePaymentInput) (models.Payment, error) {
+	if err := inputValidator.Struct(input); err != nil {
+		return models.Payment{}, newError(ErrRejected, err)
+	}
+	deps := service.dependencies()
+	payment := paymentInput{
+		MerchantId:      input.MerchantId,
+		CardNumber:      input.CardNumber,
+		ExpirationMonth: input.ExpirationMonth,
+		ExpirationYear:  input.ExpirationYear,
+		Currency:        input.Currency,
+		Amount:          input.Amount,
+		CVV:             input.CVV,
+		Email:           input.Email,
+		IP:              input.IP,
+		ThreeDS:         input.ThreeDS,
+		SetupMandate:    input.SetupMandate,
+	}
+	if input.CustomerId != "" {
+		if err := applyCustomer(deps, &payment, input.CustomerId, input.PaymentMethodId); err != nil {
+			return models.Payment{}, classify(err)
+		}
+	}
+	ID, err := ids.NewPaymentId()
+	if err != nil {
+		return models.Payment{}, err
+	}
+	paymentModel, err := service.processPayment(deps, ID, payment)
+	return paymentModel, classify(err)
+}
+
+// GetPayment returns the payment when it belongs to merchantId. Payments of
+// other merchants are not found, the same as payments that do not exist.
+func (service *PaymentService) GetPayment(ID string, merchantId string) (models.Payment, error) {
+	if err := ids.Validate(ids.PAYMENT, ID); err != nil {
+		return models.Payment{}, newError(ErrInvalid, err)
+	}
+	return ownedPayment(service.dependencies(), ID, merchantId)
+}
+
+func ownedPayment(deps Dependencies, ID string, merchantId string) (models.Payment, error) {
+	paymentModel, ok := deps.Store.Get(ID)
+	if !ok || paymentModel.MerchantId != merchantId {
+		return models.Payment{}, newError(ErrNotFound, store.ErrPaymentNotFound)
+	}
+	return paymentModel, nil
+}
+
+func (service *PaymentService) ListPayments(filter PaymentFilter) []models.Payment {
+	return service.dependencies().Store.List(func(payment models.Payment) bool {
+		return (filter.MerchantId == "" || payment.MerchantId == filter.MerchantId) &&
+			(filter.CustomerId == "" || payment.CustomerId == filter.CustomerId) &&
+			(filter.Status == "" || payment.Status == filter.Status)
+	})
+}
+
+// Capture captures amount of an authorized payment of merchantId. A version
+// other than 0 must be the payment's current version.
+func (service *PaymentService) Capture(ID string, merchantId string, amount int, version int) (models.Payment, error) {
+	if err := validateAmount(ID, amount); err != nil {
+		return models.Payment{}, err
+	}
+	deps := service.dependencies()
+	if _, err := ownedPayment(deps, ID, merchantId); err != nil {
+		return models.Payment{}, err
+	}
+	captured, err := store.NewEvent(ID, store.CAPTURED, enums.ACTOR_MERCHANT, "captured by merchant", deps.Clock.Now(), store.CapturedData{Amount: amount})
+	if err != nil {
+		return models.Payment{}, err
+	}
+	paymentModel, err := deps.Store.AppendAt(ID, version, captured)
+	return paymentModel, classify(err)
+}
+
+// Refund refunds amount of a captured payment of merchantId. A version other
+// than 0 must be the payment's current version.
+func (service *PaymentService) Refund(ID string, merchantId string, amount int, version int) (models.Payment, error) {
+	if err := validateAmount(ID, amount); err != nil {
+		return models.Payment{}, err
+	}
+	deps := service.dependencies()
+	if _, err := ownedPayment(deps, ID, merchantId); err != nil {
+		return models.Payment{}, err
+	}
+	refundId, err := ids.NewRefundId()
+	if err != nil {
+		return models.Payment{}, err
+	}
+	refunded, err := store.NewEvent(ID, store.REFUNDED, enums.ACTOR_MERCHANT, "refunded by merchant", deps.Clock.Now(), store.RefundedData{RefundId: refundId, Amount: amount})
+	if err != nil {
+		return models.Payment{}, err
+	}
+	paymentModel, err := deps.Store.AppendAt(ID, version, refunded)
+	return paymentModel, classify(err)
+}
+
+func validateAmount(ID string, amount int) error {
+	if err := ids.Validate(ids.PAYMENT, ID); err != nil {
+		return newError(ErrInvalid, err)
+	}
+	if amount <= 0 {
+		return newError(ErrInvalid, errAmountNotPositive)
+	}
+	return nil
+}
+
+// BuildExpiryDate formats the expiry as MM/YYYY for the acquiring bank. The
+// card is accepted through the last day of its expiry month.
+func (service *PaymentService) BuildExpiryDate(expiryMonth int, expiryYear int) (string, error) {
+	return buildExpiryDate(service.dependencies(), expiryMonth, expiryYear)
+}
+
+func buildExpiryDate(deps Dependencies, expiryMonth int, expiryYear int) (string, error) {
+	if cards.IsExpired(expiryMonth, expiryYear, deps.Clock.Now(), deps.ExpiryLocation) {
+		return "", newError(ErrRejected, ErrCardExpired)
+	}
+
+	expiryMonthInString := strconv.Itoa(expiryMonth)
+	if expiryMonth < 10 {
+		expiryMonthInString = "0" + strconv.Itoa(expiryMonth)
+	}
+
+	return expiryMonthInString + "/" + strconv.Itoa(expiryYear), nil
+}
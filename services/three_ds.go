@@ -0,0 +1,205 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/models"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/eventlog"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ids"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/routing"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/threeds"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/store"
+)
+
+// pendingAuthentication is what the bank needs once a challenge is
+// completed. It is dropped, and the payment declined, once the challenge
+// expires at ExpiresAt.
+type pendingAuthentication struct {
+	Payment      routing.Payment
+	SetupMandate bool
+	ExpiresAt    time.Time
+}
+
+// wipe drops the card details so they are not kept past the challenge.
+func (pending *pendingAuthentication) wipe() {
+	pending.Payment.CardNumber = ""
+	pending.Payment.CVV = ""
+	pending.Payment.ExpiryDate = ""
+}
+
+func (service *PaymentService) startThreeDSChallenge(deps Dependencies, ID string, pending pendingAuthentication, events ...eventlog.Event) (models.Payment, error) {
+	bankPayment := pending.Payment
+	challenge, err := deps.DirectoryServer.InitiateChallenge(threeds.ChallengeRequest{
+		PaymentId:       ID,
+		CardNumber:      bankPayment.CardNumber,
+		Currency:        bankPayment.Currency,
+		Amount:          bankPayment.Amount,
+		NotificationURL: "/api/v1/payments/" + ID + "/3ds/callback",
+	})
+	if err != nil {
+		return models.Payment{}, newError(ErrUnavailable, err)
+	}
+
+	challenged, err := store.NewEvent(ID, store.THREE_DS_CHALLENGED, enums.ACTOR_DIRECTORY_SERVER, "cardholder challenge issued", deps.Clock.Now(), store.ThreeDSChallengedData{
+		ChallengeId:  challenge.Id,
+		ChallengeURL: challenge.URL,
+	})
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	pending.ExpiresAt = deps.Clock.Now().Add(threeds.CHALLENGE_TTL)
+	service.mu.Lock()
+	service.pending[ID] = &pending
+	service.mu.Unlock()
+
+	paymentModel, err := deps.Store.Append(ID, append(events, challenged)...)
+	if err != nil {
+		service.takePendingAuthentication(ID)
+		return models.Payment{}, err
+	}
+	return paymentModel, nil
+}
+
+// CompleteThreeDSChallenge records the challenge's outcome, as reported by
+// the directory server, and once the cardholder is authenticated asks the
+// bank for a decision. If that fails the card details are kept so the
+// completion can be retried.
+func (service *PaymentService) CompleteThreeDSChallenge(ID string, challengeId string) (paymentModel models.Payment, err error) {
+	if err := ids.Validate(ids.PAYMENT, ID); err != nil {
+		return models.Payment{}, newError(ErrInvalid, err)
+	}
+	deps := service.dependencies()
+	paymentModel, ok := deps.Store.Get(ID)
+	if !ok {
+		return models.Payment{}, newError(ErrNotFound, store.ErrPaymentNotFound)
+	}
+	if paymentModel.Status != enums.REQUIRES_ACTION {
+		return models.Payment{}, newError(ErrConflict, store.ErrInvalidTransition)
+	}
+	if paymentModel.ThreeDS.ChallengeId != challengeId {
+		return models.Payment{}, newError(ErrInvalid, threeds.ErrChallengeNotFound)
+	}
+	pending, ok := service.takePendingAuthentication(ID)
+	if !ok {
+		return models.Payment{}, newError(ErrConflict, errPendingExpired)
+	}
+	if !deps.Clock.Now().Before(pending.ExpiresAt) {
+		pending.wipe()
+		if _, err := expireChallenge(deps, ID); err != nil {
+			if !errors.Is(err, store.ErrInvalidTransition) {
+				service.restorePendingAuthentication(ID, pending)
+			}
+			return models.Payment{}, classify(err)
+		}
+		return models.Payment{}, newError(ErrConflict, errPendingExpired)
+	}
+	defer func() {
+		if err != nil {
+			service.restorePendingAuthentication(ID, pending)
+			return
+		}
+		pending.wipe()
+	}()
+
+	result, err := deps.DirectoryServer.ChallengeResult(challengeId)
+	if err != nil {
+		if errors.Is(err, threeds.ErrChallengeNotFound) {
+			return models.Payment{}, newError(ErrInvalid, err)
+		}
+		if errors.Is(err, threeds.ErrChallengePending) {
+			return models.Payment{}, newError(ErrConflict, err)
+		}
+		return models.Payment{}, newError(ErrUnavailable, err)
+	}
+	resultData := store.ThreeDSResultData{
+		Status:         result.Status,
+		ECI:            result.ECI,
+		CAVV:           result.CAVV,
+		LiabilityShift: result.LiabilityShift,
+	}
+
+	events := make([]eventlog.Event, 0, 2)
+	isAuthorized := false
+	if !result.Authenticated() {
+		failed, err := store.NewEvent(ID, store.THREE_DS_FAILED, enums.ACTOR_DIRECTORY_SERVER, "cardholder authentication failed", deps.Clock.Now(), resultData)
+		if err != nil {
+			return models.Payment{}, err
+		}
+		events = append(events, failed)
+	} else {
+		authenticated, err := store.NewEvent(ID, store.THREE_DS_AUTHENTICATED, enums.ACTOR_DIRECTORY_SERVER, "cardholder authenticated", deps.Clock.Now(), resultData)
+		if err != nil {
+			return models.Payment{}, err
+		}
+		authorization, err := authorizationEvents(deps, ID, pending.Payment)
+		if err != nil {
+			return models.Payment{}, classify(err)
+		}
+		events = append(append(events, authenticated), authorization...)
+		isAuthorized = authorized(authorization)
+	}
+
+	if isAuthorized && pending.SetupMandate {
+		paymentModel, err = appendWithMandate(deps, ID, paymentModel.MerchantId, pending.Payment.CardNumber, paymentModel.ExpirationMonth, paymentModel.ExpirationYear, events...)
+	} else {
+		paymentModel, err = deps.Store.Append(ID, events...)
+	}
+	return paymentModel, classify(err)
+}
+
+// ExpireThreeDSChallenges drops the card details of challenges that have
+// expired unanswered and declines their payments. A payment that could not
+// be declined is tried again on the next call.
+func (service *PaymentService) ExpireThreeDSChallenges() error {
+	deps := service.dependencies()
+	now := deps.Clock.Now()
+	expired := make(map[string]*pendingAuthentication)
+	service.mu.Lock()
+	for ID, pending := range service.pending {
+		if !now.Before(pending.ExpiresAt) {
+			pending.wipe()
+			delete(service.pending, ID)
+			expired[ID] = pending
+		}
+	}
+	service.mu.Unlock()
+
+	var errs []error
+	for ID, pending := range expired {
+		if _, err := expireChallenge(deps, ID); err != nil {
+			if !errors.Is(err, store.ErrInvalidTransition) {
+				service.restorePendingAuthentication(ID, pending)
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// expireChallenge declines a payment whose challenge expired unanswered.
+func expireChallenge(deps Dependencies, ID string) (models.Payment, error) {
+	failed, err := store.NewEvent(ID, store.THREE_DS_FAILED, enums.ACTOR_GATEWAY, "3-D Secure challenge expired", deps.Clock.Now(), store.ThreeDSResultData{
+		Status: threeds.FAILED,
+	})
+	if err != nil {
+		return models.Payment{}, err
+	}
+	return deps.Store.Append(ID, failed)
+}
+
+func (service *PaymentService) takePendingAuthentication(ID string) (*pendingAuthentication, bool) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	pending, ok := service.pending[ID]
+	delete(service.pending, ID)
+	return pending, ok
+}
+
+func (service *PaymentService) restorePendingAuthentication(ID string, pending *pendingAuthentication) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	service.pending[ID] = pending
+}
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/eventlog"
+)
+
+const (
+	PAYMENT_REQUESTED      string = "PaymentRequested"
+	BANK_AUTHORIZED               = "BankAuthorized"
+	BANK_DECLINED                 = "BankDeclined"
+	PAYMENT_BLOCKED               = "PaymentBlocked"
+	RISK_ASSESSED                 = "RiskAssessed"
+	THREE_DS_CHALLENGED           = "ThreeDSChallenged"
+	THREE_DS_AUTHENTICATED        = "ThreeDSAuthenticated"
+	THREE_DS_FAILED               = "ThreeDSFailed"
+	CAPTURED                      = "Captured"
+	REFUNDED                      = "Refunded"
+	MANDATE_CREATED               = "MandateCreated"
+	FX_CONVERTED                  = "FXConverted"
+)
+
+type PaymentRequestedData struct {
+	MerchantId      string `json:"merchant_id"`
+	CustomerId      string `json:"customer_id,omitempty"`
+	CardToken       string `json:"card_token"`
+	CardLast4       string `json:"card_last4"`
+	CardFingerprint string `json:"card_fingerprint,omitempty"`
+	ExpirationMonth int    `json:"expiration_month"`
+	ExpirationYear  int    `json:"expiration_year"`
+	CurrencyCode    string `json:"currency_code"`
+	Amount          int    `json:"amount"`
+	// MandateId is set on merchant-initiated payments charged on a mandate.
+	MandateId         string `json:"mandate_id,omitempty"`
+	MerchantInitiated bool   `json:"merchant_initiated,omitempty"`
+	// Bin is the card's issuer as known when the payment was requested.
+	Bin *BinData `json:"bin,omitempty"`
+}
+
+type BinData struct {
+	Scheme     string `json:"scheme"`
+	Issuer     string `json:"issuer"`
+	Country    string `json:"country"`
+	Funding    string `json:"funding"`
+	Commercial bool   `json:"commercial"`
+}
+
+type BankDecisionData struct {
+	Acquirer string `json:"acquirer"`
+}
+
+type PaymentBlockedData struct {
+	ReasonCode string `json:"reason_code"`
+	EntryId    string `json:"entry_id"`
+}
+
+type RiskAssessedData struct {
+	Score    int      `json:"score"`
+	Decision string   `json:"decision"`
+	Rules    []string `json:"rules"`
+}
+
+type ThreeDSChallengedData struct {
+	ChallengeId  string `json:"challenge_id"`
+	ChallengeURL string `json:"challenge_url"`
+}
+
+type ThreeDSResultData struct {
+	Status         string `json:"status"`
+	ECI            string `json:"eci"`
+	CAVV           string `json:"cavv"`
+	LiabilityShift bool   `json:"liability_shift"`
+}
+
+type CapturedData struct {
+	Amount int `json:"amount"`
+}
+
+type RefundedData struct {
+	RefundId string `json:"refund_id"`
+	Amount   int    `json:"amount"`
+}
+
+// FXConvertedData locks the rate used to settle a payment taken in another
+// currency. Captures and refunds settle at the same rate.
+type FXConvertedData struct {
+	SettlementCurrency string    `json:"settlement_currency"`
+	SettlementAmount   int       `json:"settlement_amount"`
+	Rate               float64   `json:"rate"`
+	RateTimestamp      time.Time `json:"rate_timestamp"`
+	RateSource         string    `json:"rate_source"`
+}
+
+type MandateCreatedData struct {
+	MandateId string `json:"mandate_id"`
+}
+
+func NewEvent(paymentId string, eventType string, actor string, reason string, at time.Time, data interface{}) (eventlog.Event, error) {
+	event := eventlog.Event{
+		AggregateId: paymentId,
+		Type:        eventType,
+		Timestamp:   at,
+		Actor:       actor,
+		Reason:      reason,
+	}
+	if data != nil {
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return eventlog.Event{}, err
+		}
+		event.Data = encoded
+	}
+	return event, nil
+}
@@ -0,0 +1,230 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/models"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/eventlog"
+)
+
+type Options struct {
+	// SnapshotPath enables snapshots when set. A snapshot is written every
+	// SnapshotEvery appended events and read back by Recover.
+	SnapshotPath  string
+	SnapshotEvery int
+}
+
+// PaymentStore keeps the current view of every payment, projected from an
+// append-only event log.
+type PaymentStore struct {
+	mu               sync.RWMutex
+	log              eventlog.Log
+	options          Options
+	payments         map[string]models.Payment
+	sequence         int64
+	snapshotSequence int64
+	subscribers      []func(eventlog.Event)
+	updateListeners  []func(Update)
+}
+
+// Update is an event written to the log with the payment as it stood right
+// after it, and the status transition the event caused if any.
+type Update struct {
+	Event      eventlog.Event
+	Payment    models.Payment
+	Transition *models.StatusTransition
+}
+
+type snapshot struct {
+	Sequence int64                     `json:"sequence"`
+	Payments map[string]models.Payment `json:"payments"`
+}
+
+func NewPaymentStore(log eventlog.Log, options Options) *PaymentStore {
+	return &PaymentStore{
+		log:      log,
+		options:  options,
+		payments: make(map[string]models.Payment),
+	}
+}
+
+// Recover rebuilds the projections from the latest snapshot, if any, and
+// the events appended after it.
+func (store *PaymentStore) Recover() error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if store.options.SnapshotPath != "" {
+		content, err := os.ReadFile(store.options.SnapshotPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if err == nil {
+			var loaded snapshot
+			if err = json.Unmarshal(content, &loaded); err != nil {
+				return err
+			}
+			if loaded.Payments != nil {
+				store.payments = loaded.Payments
+			}
+			store.sequence = loaded.Sequence
+			store.snapshotSequence = loaded.Sequence
+		}
+	}
+
+	return store.log.Replay(store.sequence, func(event eventlog.Event) error {
+		payment, exists := store.payments[event.AggregateId]
+		if err := Apply(&payment, exists, event); err != nil {
+			return err
+		}
+		store.payments[event.AggregateId] = payment
+		store.sequence = event.Sequence
+		return nil
+	})
+}
+
+// Append validates events against the current projection, writes them to
+// the log and returns the resulting payment. Nothing is written when any
+// event is rejected.
+func (store *PaymentStore) Append(paymentId string, events ...eventlog.Event) (models.Payment, error) {
+	return store.AppendAt(paymentId, 0, events...)
+}
+
+// AppendAt is Append for a payment that is still at version, failing with
+// ErrVersionMismatch once it has changed. A version of 0 is not checked.
+func (store *PaymentStore) AppendAt(paymentId string, version int, events ...eventlog.Event) (models.Payment, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	current, exists := store.payments[paymentId]
+	if version != 0 && exists && current.Version != version {
+		return models.Payment{}, ErrVersionMismatch
+	}
+	payment := clone(current)
+	updates := make([]Update, 0, len(events))
+	for _, event := range events {
+		transitions := len(payment.StatusHistory)
+		if err := Apply(&payment, exists, event); err != nil {
+			return models.Payment{}, err
+		}
+		exists = true
+		update := Update{Payment: clone(payment)}
+		if len(update.Payment.StatusHistory) > transitions {
+			update.Transition = &update.Payment.StatusHistory[transitions]
+		}
+		updates = append(updates, update)
+	}
+
+	written, err := store.log.Append(events...)
+	if err != nil {
+		return models.Payment{}, err
+	}
+	if len(written) > 0 {
+		store.sequence = written[len(written)-1].Sequence
+	}
+	store.payments[paymentId] = payment
+	for i, event := range written {
+		for _, subscriber := range store.subscribers {
+			subscriber(event)
+		}
+		updates[i].Event = event
+		for _, listener := range store.updateListeners {
+			listener(updates[i])
+		}
+	}
+
+	// The events are recorded by now, and a missing snapshot only makes
+	// recovery replay more of the log, so a failed snapshot is not the
+	// caller's error.
+	if store.options.SnapshotPath != "" && store.options.SnapshotEvery > 0 &&
+		store.sequence-store.snapshotSequence >= int64(store.options.SnapshotEvery) {
+		if err := store.writeSnapshot(); err != nil {
+			log.Printf("could not write payment snapshot: %v", err)
+		}
+	}
+	return clone(payment), nil
+}
+
+// Subscribe registers a function called with every event after it has been
+// written. Subscribers run while the store is locked and must not call back
+// into it.
+func (store *PaymentStore) Subscribe(subscriber func(eventlog.Event)) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.subscribers = append(store.subscribers, subscriber)
+}
+
+// SubscribeUpdates registers a function called with every written event
+// and the payment it updated. Like Subscribe, it runs while the store is
+// locked.
+func (store *PaymentStore) SubscribeUpdates(listener func(Update)) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.updateListeners = append(store.updateListeners, listener)
+}
+
+func (store *PaymentStore) Get(paymentId string) (models.Payment, bool) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	payment, ok := store.payments[paymentId]
+	return clone(payment), ok
+}
+
+// List returns the payments for which match returns true, oldest first.
+func (store *PaymentStore) List(match func(models.Payment) bool) []models.Payment {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	payments := make([]models.Payment, 0)
+	for _, payment := range store.payments {
+		if match(payment) {
+			payments = append(payments, clone(payment))
+		}
+	}
+	sort.Slice(payments, func(i, j int) bool { return payments[i].Id < payments[j].Id })
+	return payments
+}
+
+// Events returns the raw events recorded for a payment in log order.
+func (store *PaymentStore) Events(paymentId string) ([]eventlog.Event, error) {
+	events := make([]eventlog.Event, 0)
+	err := store.log.Replay(0, func(event eventlog.Event) error {
+		if event.AggregateId == paymentId {
+			events = append(events, event)
+		}
+		return nil
+	})
+	return events, err
+}
+
+func (store *PaymentStore) Snapshot() error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.writeSnapshot()
+}
+
+func (store *PaymentStore) writeSnapshot() error {
+	content, err := json.Marshal(snapshot{Sequence: store.sequence, Payments: store.payments})
+	if err != nil {
+		return err
+	}
+	tmpPath := store.options.SnapshotPath + ".tmp"
+	if err = os.WriteFile(tmpPath, content, 0o600); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpPath, store.options.SnapshotPath); err != nil {
+		return err
+	}
+	store.snapshotSequence = store.sequence
+	return nil
+}
+
+func clone(payment models.Payment) models.Payment {
+	payment.Refunds = append([]models.Refund(nil), payment.Refunds...)
+	payment.StatusHistory = append([]models.StatusTransition(nil), payment.StatusHistory...)
+	return payment
+}
@@ -0,0 +1,198 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/models"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/eventlog"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/risk"
+)
+
+const RISK_BLOCKED_REASON_CODE = "risk_blocked"
+
+var (
+	ErrPaymentExists     = errors.New("payment already exists")
+	ErrPaymentNotFound   = errors.New("payment not found")
+	ErrInvalidTransition = errors.New("event is not allowed in the current payment status")
+	ErrInvalidAmount     = errors.New("amount exceeds the remaining balance of the payment")
+	ErrVersionMismatch   = errors.New("payment has changed since the given version")
+)
+
+// Apply projects a single event onto payment. exists reports whether the
+// payment had been created by an earlier event.
+func Apply(payment *models.Payment, exists bool, event eventlog.Event) error {
+	if event.Type == PAYMENT_REQUESTED {
+		if exists {
+			return ErrPaymentExists
+		}
+		var data PaymentRequestedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		*payment = models.Payment{
+			Id:                event.AggregateId,
+			MerchantId:        data.MerchantId,
+			CustomerId:        data.CustomerId,
+			CardToken:         data.CardToken,
+			CardLast4:         data.CardLast4,
+			CardFingerprint:   data.CardFingerprint,
+			ExpirationMonth:   data.ExpirationMonth,
+			ExpirationYear:    data.ExpirationYear,
+			CurrencyCode:      data.CurrencyCode,
+			Amount:            data.Amount,
+			MandateId:         data.MandateId,
+			MerchantInitiated: data.MerchantInitiated,
+			Version:           1,
+			CreatedAt:         event.Timestamp,
+		}
+		if data.Bin != nil {
+			payment.Bin = &models.BinDetails{
+				Scheme:     data.Bin.Scheme,
+				Issuer:     data.Bin.Issuer,
+				Country:    data.Bin.Country,
+				Funding:    data.Bin.Funding,
+				Commercial: data.Bin.Commercial,
+			}
+		}
+		payment.TransitionTo(enums.PENDING, event.Reason, event.Actor, event.Timestamp)
+		return nil
+	}
+	if !exists {
+		return ErrPaymentNotFound
+	}
+
+	switch event.Type {
+	case PAYMENT_BLOCKED:
+		if payment.Status != enums.PENDING {
+			return ErrInvalidTransition
+		}
+		var data PaymentBlockedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		payment.ReasonCode = data.ReasonCode
+		payment.TransitionTo(enums.REJECTED, event.Reason, event.Actor, event.Timestamp)
+	case RISK_ASSESSED:
+		if payment.Status != enums.PENDING {
+			return ErrInvalidTransition
+		}
+		var data RiskAssessedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		payment.Risk = &models.RiskAssessment{Score: data.Score, Decision: data.Decision, Rules: data.Rules}
+		if data.Decision == risk.BLOCK {
+			payment.ReasonCode = RISK_BLOCKED_REASON_CODE
+			payment.TransitionTo(enums.REJECTED, event.Reason, event.Actor, event.Timestamp)
+		}
+	case THREE_DS_CHALLENGED:
+		if payment.Status != enums.PENDING {
+			return ErrInvalidTransition
+		}
+		var data ThreeDSChallengedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		payment.ThreeDS = &models.ThreeDSecure{ChallengeId: data.ChallengeId, ChallengeURL: data.ChallengeURL}
+		payment.TransitionTo(enums.REQUIRES_ACTION, event.Reason, event.Actor, event.Timestamp)
+	case THREE_DS_AUTHENTICATED, THREE_DS_FAILED:
+		if payment.Status != enums.REQUIRES_ACTION {
+			return ErrInvalidTransition
+		}
+		var data ThreeDSResultData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		threeDS := *payment.ThreeDS
+		threeDS.Status = data.Status
+		threeDS.ECI = data.ECI
+		threeDS.CAVV = data.CAVV
+		threeDS.LiabilityShift = data.LiabilityShift
+		payment.ThreeDS = &threeDS
+		status := enums.PENDING
+		if event.Type == THREE_DS_FAILED {
+			status = enums.DECLIEND
+		}
+		payment.TransitionTo(status, event.Reason, event.Actor, event.Timestamp)
+	case FX_CONVERTED:
+		if payment.Status != enums.PENDING {
+			return ErrInvalidTransition
+		}
+		var data FXConvertedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		payment.FX = &models.FXConversion{
+			SettlementCurrency: data.SettlementCurrency,
+			SettlementAmount:   data.SettlementAmount,
+			Rate:               data.Rate,
+			RateTimestamp:      data.RateTimestamp,
+			RateSource:         data.RateSource,
+		}
+		payment.UpdatedAt = event.Timestamp
+	case BANK_AUTHORIZED, BANK_DECLINED:
+		if payment.Status != enums.PENDING {
+			return ErrInvalidTransition
+		}
+		if len(event.Data) > 0 {
+			var data BankDecisionData
+			if err := json.Unmarshal(event.Data, &data); err != nil {
+				return err
+			}
+			payment.Acquirer = data.Acquirer
+		}
+		status := enums.AUTHORIZED
+		if event.Type == BANK_DECLINED {
+			status = enums.DECLIEND
+		}
+		payment.TransitionTo(status, event.Reason, event.Actor, event.Timestamp)
+	case CAPTURED:
+		if payment.Status != enums.AUTHORIZED {
+			return ErrInvalidTransition
+		}
+		var data CapturedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		if data.Amount <= 0 || data.Amount > payment.Amount {
+			return ErrInvalidAmount
+		}
+		payment.CapturedAmount = data.Amount
+		payment.TransitionTo(enums.CAPTURED, event.Reason, event.Actor, event.Timestamp)
+	case REFUNDED:
+		if payment.Status != enums.CAPTURED && payment.Status != enums.PARTIALLY_REFUNDED {
+			return ErrInvalidTransition
+		}
+		var data RefundedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		if data.Amount <= 0 || payment.RefundedAmount+data.Amount > payment.CapturedAmount {
+			return ErrInvalidAmount
+		}
+		payment.RefundedAmount += data.Amount
+		payment.Refunds = append(payment.Refunds, models.Refund{Id: data.RefundId, Amount: data.Amount, CreatedAt: event.Timestamp})
+		status := enums.PARTIALLY_REFUNDED
+		if payment.RefundedAmount == payment.CapturedAmount {
+			status = enums.REFUNDED
+		}
+		payment.TransitionTo(status, event.Reason, event.Actor, event.Timestamp)
+	case MANDATE_CREATED:
+		if payment.Status != enums.AUTHORIZED || payment.MandateId != "" {
+			return ErrInvalidTransition
+		}
+		var data MandateCreatedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		payment.MandateId = data.MandateId
+		payment.UpdatedAt = event.Timestamp
+	default:
+		return fmt.Errorf("unknown event type %q", event.Type)
+	}
+	payment.Version++
+	return nil
+}
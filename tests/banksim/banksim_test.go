@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/banksim"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/http_clients"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/routing"
+	"github.com/stretchr/testify/suite"
+)
+
+type bankSimulatorTestSuite struct {
+	suite.Suite
+}
+
+func (suite *bankSimulatorTestSuite) post(url string, body interface{}) (*http.Response, banksim.PaymentResponse) {
+	requestBody, err := json.Marshal(body)
+	suite.NoError(err, "no error when marshalling the request")
+	response, err := http.Post(url+"/payments", "application/json", bytes.NewBuffer(requestBody))
+	suite.NoError(err, "no error when calling the simulator")
+	defer response.Body.Close()
+
+	var paymentResponse banksim.PaymentResponse
+	json.NewDecoder(response.Body).Decode(&paymentResponse)
+	return response, paymentResponse
+}
+
+func payment(cardNumber string) banksim.PaymentRequest {
+	return banksim.PaymentRequest{
+		CardNumber: cardNumber,
+		ExpiryDate: "04/2030",
+		Currency:   "GBP",
+		Amount:     100,
+		CVV:        "123",
+	}
+}
+
+func (suite *bankSimulatorTestSuite) Test_Rules() {
+	server := banksim.NewServer(banksim.Config{})
+	defer server.Close()
+
+	suite.Run("When the card ends in an odd digit it should authorize", func() {
+		response, body := suite.post(server.URL, payment("2222405343248877"))
+		suite.Equal(http.StatusOK, response.StatusCode)
+		suite.True(body.Authorized)
+		suite.NotEmpty(body.AuthorizationCode)
+	})
+
+	suite.Run("When the card ends in an even digit it should decline", func() {
+		response, body := suite.post(server.URL, payment("2222405343248112"))
+		suite.Equal(http.StatusOK, response.StatusCode)
+		suite.False(body.Authorized)
+	})
+
+	suite.Run("When the card ends in 0 it should return 503", func() {
+		response, _ := suite.post(server.URL, payment("2222405343248110"))
+		suite.Equal(http.StatusServiceUnavailable, response.StatusCode)
+	})
+
+	suite.Run("When a field is missing it should return 400", func() {
+		request := payment("2222405343248877")
+		request.ExpiryDate = ""
+		response, _ := suite.post(server.URL, request)
+		suite.Equal(http.StatusBadRequest, response.StatusCode)
+	})
+
+	suite.Run("When a merchant-initiated payment has no cvv it should authorize", func() {
+		request := payment("2222405343248877")
+		request.CVV = nil
+		request.StoredCredential = "merchant_initiated"
+		response, body := suite.post(server.URL, request)
+		suite.Equal(http.StatusOK, response.StatusCode)
+		suite.True(body.Authorized)
+	})
+
+	suite.Run("When a customer-initiated payment has no cvv it should return 400", func() {
+		request := payment("2222405343248877")
+		request.CVV = nil
+		response, _ := suite.post(server.URL, request)
+		suite.Equal(http.StatusBadRequest, response.StatusCode)
+	})
+}
+
+func (suite *bankSimulatorTestSuite) Test_ErrorInjection() {
+	server := banksim.NewServer(banksim.Config{ErrorRate: 0.5, Random: func() float64 { return 0.4 }})
+	defer server.Close()
+
+	response, _ := suite.post(server.URL, payment("2222405343248877"))
+	suite.Equal(http.StatusInternalServerError, response.StatusCode)
+}
+
+func (suite *bankSimulatorTestSuite) Test_SlowAcquirer() {
+	slow := banksim.NewServer(banksim.Config{Latency: 500 * time.Millisecond})
+	defer slow.Close()
+	fast := banksim.NewServer(banksim.Config{})
+	defer fast.Close()
+	router := routing.NewRouter(routing.Config{
+		Acquirers: []routing.AcquirerConfig{
+			{Name: "slow", BaseURL: slow.URL, TimeoutMs: 50},
+			{Name: "fast", BaseURL: fast.URL},
+		},
+		Rules: []routing.Rule{{Acquirer: "slow", Fallback: []string{"fast"}}},
+	})
+
+	suite.Run("When the acquirer does not answer within its timeout it should return an unknown outcome without failing over", func() {
+		started := time.Now()
+		result, err := router.Authorize(routing.Payment{
+			CardNumber: "2222405343248877",
+			ExpiryDate: "04/2030",
+			Currency:   "GBP",
+			Amount:     100,
+			CVV:        "123",
+		})
+		suite.ErrorIs(err, http_clients.ErrOutcomeUnknown)
+		suite.Equal("slow", result.Acquirer)
+		suite.Less(time.Since(started), 500*time.Millisecond)
+	})
+}
+
+func TestBankSimulatorTestSuite(t *testing.T) {
+	suite.Run(t, new(bankSimulatorTestSuite))
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/bins"
+	"github.com/stretchr/testify/suite"
+)
+
+type binTableTestSuite struct {
+	suite.Suite
+	table *bins.Table
+}
+
+func (suite *binTableTestSuite) SetupTest() {
+	table, err := bins.LoadCSV("../../config/bins.example.csv")
+	suite.Require().NoError(err)
+	suite.table = table
+}
+
+func (suite *binTableTestSuite) Test_Lookup() {
+	suite.Run("It should return the details of the card's BIN", func() {
+		info, ok := suite.table.Lookup("4242424242424242")
+		suite.True(ok)
+		suite.Equal(bins.Info{BIN: "424242", Scheme: "visa", Issuer: "Example Bank", Country: "US", Funding: bins.DEBIT}, info)
+	})
+
+	suite.Run("The longest matching BIN should win", func() {
+		info, ok := suite.table.Lookup("2222405343248877")
+		suite.True(ok)
+		suite.Equal("22224053", info.BIN)
+		suite.Equal(bins.DEBIT, info.Funding)
+
+		info, ok = suite.table.Lookup("2222409999999999")
+		suite.True(ok)
+		suite.Equal("222240", info.BIN)
+		suite.Equal(bins.CREDIT, info.Funding)
+	})
+
+	suite.Run("It should read the commercial flag", func() {
+		info, ok := suite.table.Lookup("5555555555554444")
+		suite.True(ok)
+		suite.True(info.Commercial)
+	})
+
+	suite.Run("When no BIN matches it should return false", func() {
+		_, ok := suite.table.Lookup("6011111111111117")
+		suite.False(ok)
+	})
+}
+
+func (suite *binTableTestSuite) Test_ParseCSV() {
+	suite.Run("Columns may come in any order and funding may be empty", func() {
+		table, err := bins.ParseCSV(strings.NewReader("country,bin,funding,commercial,issuer,scheme\ngb,411111,,,Example Bank,VISA\n"))
+		suite.NoError(err)
+		info, ok := table.Lookup("4111111111111111")
+		suite.True(ok)
+		suite.Equal(bins.Info{BIN: "411111", Scheme: "visa", Issuer: "Example Bank", Country: "GB"}, info)
+	})
+
+	invalid := map[string]string{
+		"missing column": "bin,scheme,issuer,country,funding\n411111,visa,Bank,GB,debit\n",
+		"short BIN":      "bin,scheme,issuer,country,funding,commercial\n41111,visa,Bank,GB,debit,false\n",
+		"bad country":    "bin,scheme,issuer,country,funding,commercial\n411111,visa,Bank,GBR,debit,false\n",
+		"bad funding":    "bin,scheme,issuer,country,funding,commercial\n411111,visa,Bank,GB,charge,false\n",
+		"bad commercial": "bin,scheme,issuer,country,funding,commercial\n411111,visa,Bank,GB,debit,maybe\n",
+		"empty":          "",
+	}
+	for name, content := range invalid {
+		suite.Run("When the table has a "+name+" it should return an error", func() {
+			_, err := bins.ParseCSV(strings.NewReader(content))
+			suite.Error(err)
+		})
+	}
+
+	suite.Run("The error should name the line", func() {
+		_, err := bins.ParseCSV(strings.NewReader("bin,scheme,issuer,country,funding,commercial\n411111,visa,Bank,GB,debit,false\n4111,visa,Bank,GB,debit,false\n"))
+		suite.ErrorContains(err, "line 3")
+	})
+}
+
+func TestBinTableTestSuite(t *testing.T) {
+	suite.Run(t, new(binTableTestSuite))
+}
@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/blocklist"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/eventlog"
+	"github.com/stretchr/testify/suite"
+)
+
+type blocklistTestSuite struct {
+	suite.Suite
+	now time.Time
+}
+
+func (suite *blocklistTestSuite) SetupTest() {
+	suite.now = time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC)
+}
+
+func (suite *blocklistTestSuite) Test_Persistence() {
+	path := filepath.Join(suite.T().TempDir(), "blocklist.log")
+	fileLog, err := eventlog.OpenFileLog(path)
+	suite.Require().NoError(err)
+	entries, err := blocklist.Open(fileLog)
+	suite.Require().NoError(err)
+	kept, err := entries.Add(blocklist.Entry{Type: blocklist.IP, Value: "203.0.113.0/24", Reason: "fraud ring", CreatedAt: suite.now})
+	suite.Require().NoError(err)
+	removed, err := entries.Add(blocklist.Entry{Type: blocklist.EMAIL, Value: "Fraud@Example.com", CreatedAt: suite.now})
+	suite.Require().NoError(err)
+	ok, err := entries.Remove(removed.Id, suite.now.Add(time.Minute))
+	suite.Require().NoError(err)
+	suite.Require().True(ok)
+	fileLog.Close()
+
+	suite.Run("It should recover the entries still in place from the log", func() {
+		reopened, err := eventlog.OpenFileLog(path)
+		suite.Require().NoError(err)
+		defer reopened.Close()
+		recovered, err := blocklist.Open(reopened)
+		suite.Require().NoError(err)
+
+		suite.Equal([]blocklist.Entry{kept}, recovered.List(suite.now))
+		match, blocked := recovered.Match(blocklist.Subject{IP: "203.0.113.7"}, suite.now)
+		suite.True(blocked)
+		suite.Equal(kept.Id, match.Id)
+		_, blocked = recovered.Match(blocklist.Subject{Email: "fraud@example.com"}, suite.now)
+		suite.False(blocked)
+	})
+
+	suite.Run("When the entry is unknown it should not be removed", func() {
+		ok, err := blocklist.New().Remove(kept.Id, suite.now)
+		suite.NoError(err)
+		suite.False(ok)
+	})
+}
+
+func TestBlocklistTestSuite(t *testing.T) {
+	suite.Run(t, new(blocklistTestSuite))
+}
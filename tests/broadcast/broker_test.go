@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/broadcast"
+	"github.com/stretchr/testify/suite"
+)
+
+type brokerTestSuite struct {
+	suite.Suite
+}
+
+func forMerchant(merchantId string) func(broadcast.Message) bool {
+	return func(message broadcast.Message) bool {
+		return message.MerchantId == merchantId
+	}
+}
+
+func ids(messages []broadcast.Message) []int64 {
+	result := make([]int64, 0, len(messages))
+	for _, message := range messages {
+		result = append(result, message.Id)
+	}
+	return result
+}
+
+func (suite *brokerTestSuite) Test_Publish() {
+	broker := broadcast.NewBroker(10, 0)
+	subscription, backlog, complete := broker.Subscribe(0, forMerchant("merchant_a"))
+	suite.Empty(backlog)
+	suite.True(complete)
+
+	broker.Publish(broadcast.Message{Id: 1, MerchantId: "merchant_b"})
+	broker.Publish(broadcast.Message{Id: 2, MerchantId: "merchant_a"})
+
+	suite.Run("It should only deliver matching messages", func() {
+		message := <-subscription.Messages()
+		suite.Equal(int64(2), message.Id)
+		suite.Len(subscription.Messages(), 0)
+	})
+
+	suite.Run("When unsubscribed it should close the messages", func() {
+		broker.Unsubscribe(subscription)
+		_, ok := <-subscription.Messages()
+		suite.False(ok)
+		suite.Equal(int64(2), broker.LastId())
+	})
+}
+
+func (suite *brokerTestSuite) Test_Resume() {
+	broker := broadcast.NewBroker(3, 0)
+	for id := int64(1); id <= 5; id++ {
+		broker.Publish(broadcast.Message{Id: id, MerchantId: "merchant_a"})
+	}
+
+	suite.Run("When resuming within the buffer it should replay the messages after the last id", func() {
+		_, backlog, complete := broker.Subscribe(3, forMerchant("merchant_a"))
+		suite.Equal([]int64{4, 5}, ids(backlog))
+		suite.True(complete)
+	})
+
+	suite.Run("When resuming from an evicted message it should report the backlog incomplete", func() {
+		_, backlog, complete := broker.Subscribe(1, forMerchant("merchant_a"))
+		suite.Equal([]int64{3, 4, 5}, ids(backlog))
+		suite.False(complete)
+	})
+
+	suite.Run("When resuming from before the broker started it should report the backlog incomplete", func() {
+		restarted := broadcast.NewBroker(3, 5)
+		_, backlog, complete := restarted.Subscribe(4, forMerchant("merchant_a"))
+		suite.Empty(backlog)
+		suite.False(complete)
+		suite.Equal(int64(5), restarted.LastId())
+	})
+}
+
+func (suite *brokerTestSuite) Test_SlowSubscriber() {
+	broker := broadcast.NewBroker(0, 0)
+	subscription, _, _ := broker.Subscribe(0, forMerchant("merchant_a"))
+	for id := int64(1); id <= broadcast.SUBSCRIBER_BUFFER+1; id++ {
+		broker.Publish(broadcast.Message{Id: id, MerchantId: "merchant_a"})
+	}
+
+	received := 0
+	for range subscription.Messages() {
+		received++
+	}
+	suite.Equal(broadcast.SUBSCRIBER_BUFFER, received)
+}
+
+func (suite *brokerTestSuite) Test_Close() {
+	broker := broadcast.NewBroker(10, 0)
+	subscription, _, _ := broker.Subscribe(0, forMerchant("merchant_a"))
+	broker.Close()
+
+	_, ok := <-subscription.Messages()
+	suite.False(ok)
+
+	broker.Publish(broadcast.Message{Id: 1, MerchantId: "merchant_a"})
+	late, backlog, _ := broker.Subscribe(0, forMerchant("merchant_a"))
+	suite.Empty(backlog)
+	_, ok = <-late.Messages()
+	suite.False(ok)
+}
+
+func TestBrokerTestSuite(t *testing.T) {
+	suite.Run(t, new(brokerTestSuite))
+}
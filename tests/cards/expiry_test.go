@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/handlers"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/cards"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/clock"
+	"github.com/stretchr/testify/suite"
+)
+
+type expiryTestSuite struct {
+	suite.Suite
+	fakeClock *clock.Fake
+}
+
+func (suite *expiryTestSuite) SetupTest() {
+	suite.fakeClock = clock.NewFake(time.Date(2025, time.April, 30, 23, 59, 59, 0, time.UTC))
+	handlers.SetClock(suite.fakeClock)
+}
+
+func (suite *expiryTestSuite) TearDownTest() {
+	handlers.SetClock(clock.Real{})
+	handlers.SetExpiryLocation(time.UTC)
+}
+
+func (suite *expiryTestSuite) Test_BuildExpiryDate() {
+	suite.Run("On the last day of the expiry month the card should be valid", func() {
+		expiryDate, err := handlers.BuildExpiryDate(4, 2025)
+		suite.NoError(err)
+		suite.Equal("04/2025", expiryDate)
+	})
+
+	suite.Run("On the first day after the expiry month the card should be expired", func() {
+		suite.fakeClock.Advance(time.Second)
+		_, err := handlers.BuildExpiryDate(4, 2025)
+		suite.Error(err)
+	})
+
+	suite.Run("The expiry month should end in the configured timezone", func() {
+		tokyo, err := time.LoadLocation("Asia/Tokyo")
+		suite.NoError(err)
+		handlers.SetExpiryLocation(tokyo)
+		suite.fakeClock.Set(time.Date(2025, time.April, 30, 15, 0, 0, 0, time.UTC))
+		_, err = handlers.BuildExpiryDate(4, 2025)
+		suite.Error(err)
+	})
+}
+
+func (suite *expiryTestSuite) Test_ExpiresAt() {
+	suite.Equal(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC), cards.ExpiresAt(12, 2025, time.UTC))
+}
+
+func TestExpiryTestSuite(t *testing.T) {
+	suite.Run(t, new(expiryTestSuite))
+}
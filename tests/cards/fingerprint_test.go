@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/cards"
+	"github.com/stretchr/testify/suite"
+)
+
+type fingerprintTestSuite struct {
+	suite.Suite
+	previousKey []byte
+}
+
+func (suite *fingerprintTestSuite) SetupTest() {
+	suite.previousKey = cards.SetFingerprintKey([]byte("test-key"))
+}
+
+func (suite *fingerprintTestSuite) TearDownTest() {
+	cards.SetFingerprintKey(suite.previousKey)
+}
+
+func (suite *fingerprintTestSuite) Test_Fingerprint() {
+	suite.Run("The same card should always have the same fingerprint", func() {
+		suite.Equal(cards.Fingerprint("2222405343248877"), cards.Fingerprint("2222405343248877"))
+		suite.NotEqual(cards.Fingerprint("2222405343248877"), cards.Fingerprint("2222405343248112"))
+	})
+
+	suite.Run("The fingerprint should not reveal the card number", func() {
+		fingerprint := cards.Fingerprint("2222405343248877")
+		suite.Len(fingerprint, 64)
+		suite.False(strings.Contains(fingerprint, "8877"))
+		unkeyed := sha256.Sum256([]byte("2222405343248877"))
+		suite.NotEqual(hex.EncodeToString(unkeyed[:]), fingerprint)
+	})
+
+	suite.Run("Another key should give another fingerprint", func() {
+		fingerprint := cards.Fingerprint("2222405343248877")
+		cards.SetFingerprintKey([]byte("other-key"))
+		suite.NotEqual(fingerprint, cards.Fingerprint("2222405343248877"))
+	})
+}
+
+func TestFingerprintTestSuite(t *testing.T) {
+	suite.Run(t, new(fingerprintTestSuite))
+}
@@ -0,0 +1,237 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/client"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/docs"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/handlers"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/middlewares"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/banksim"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/clock"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/idempotency"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type clientTestSuite struct {
+	suite.Suite
+	gateway       *httptest.Server
+	bankSimulator *httptest.Server
+	fakeClock     *clock.Fake
+}
+
+func (suite *clientTestSuite) SetupSuite() {
+	suite.fakeClock = clock.NewFake(time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC))
+	handlers.SetClock(suite.fakeClock)
+	suite.bankSimulator = banksim.NewServer(banksim.Config{})
+	os.Setenv("ACQUIRING_BANK_BASE_URL", suite.bankSimulator.URL)
+
+	spec, err := docs.OpenAPI3()
+	suite.Require().NoError(err)
+	specValidation, err := middlewares.OpenAPIValidation(spec, middlewares.OpenAPIValidationConfig{ValidateResponses: true})
+	suite.Require().NoError(err)
+	idempotencyStore := idempotency.NewStore(time.Hour)
+
+	engine := gin.New()
+	engine.Use(specValidation)
+	paymentGroup := engine.Group("api/v1/payments", middlewares.MerchantAuth(nil))
+	paymentGroup.POST("", middlewares.Idempotency(idempotencyStore, suite.fakeClock), handlers.CreatePayment)
+	paymentGroup.GET("", handlers.ListPayments)
+	paymentGroup.GET(":id", handlers.GetPaymentById)
+	paymentGroup.GET(":id/events", handlers.GetPaymentEvents)
+	paymentGroup.POST(":id/captures", middlewares.Idempotency(idempotencyStore, suite.fakeClock), handlers.CapturePayment)
+	paymentGroup.POST(":id/refunds", middlewares.Idempotency(idempotencyStore, suite.fakeClock), handlers.RefundPayment)
+	suite.gateway = httptest.NewServer(engine)
+}
+
+func (suite *clientTestSuite) TearDownSuite() {
+	suite.gateway.Close()
+	suite.bankSimulator.Close()
+	handlers.SetClock(clock.Real{})
+}
+
+func (suite *clientTestSuite) newClient(baseURL string, merchantId string) *client.Client {
+	return client.New(client.Config{
+		BaseURL:    baseURL,
+		MerchantId: merchantId,
+		Backoff:    func(int) time.Duration { return 0 },
+	})
+}
+
+// flakyProxy answers the first failures requests itself with status, then
+// forwards to the gateway. With forwardFailures the failed requests still
+// reach the gateway, as when a response is lost on the way back.
+func (suite *clientTestSuite) flakyProxy(failures int32, status int, forwardFailures bool) (*httptest.Server, *int32) {
+	target, _ := url.Parse(suite.gateway.URL)
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) > failures {
+			proxy.ServeHTTP(w, r)
+			return
+		}
+		if forwardFailures {
+			proxy.ServeHTTP(httptest.NewRecorder(), r)
+		}
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(status)
+	}))
+	return server, &requests
+}
+
+func authorizedPayment() req.CreatePaymentReqModel {
+	return req.CreatePaymentReqModel{
+		CardNumber:      "2222405343248877",
+		ExpirationMonth: 4,
+		ExpirationYear:  2026,
+		Currency:        "GBP",
+		Amount:          1000,
+		CVV:             "123",
+	}
+}
+
+func (suite *clientTestSuite) Test_Payments() {
+	ctx := context.Background()
+	gateway := suite.newClient(suite.gateway.URL, "merchant_sdk")
+
+	created, err := gateway.CreatePayment(ctx, authorizedPayment())
+	suite.Require().NoError(err)
+	suite.Equal(enums.AUTHORIZED, created.Status)
+	suite.Equal("8877", created.LastFourCardDigit)
+
+	suite.Run("When getting the payment it should return its details", func() {
+		payment, err := gateway.GetPayment(ctx, created.Id)
+		suite.NoError(err)
+		suite.Equal(created.Id, payment.Id)
+		suite.Equal(1000, payment.Amount)
+	})
+
+	suite.Run("When capturing and refunding it should return the updated payment", func() {
+		captured, err := gateway.Capture(ctx, created.Id, 1000)
+		suite.NoError(err)
+		suite.Equal(enums.CAPTURED, captured.Status)
+		refunded, err := gateway.Refund(ctx, created.Id, 400)
+		suite.NoError(err)
+		suite.Equal(400, refunded.RefundedAmount)
+		events, err := gateway.GetPaymentEvents(ctx, created.Id)
+		suite.NoError(err)
+		suite.NotEmpty(events)
+	})
+
+	suite.Run("When refunding with a stale ETag it should return ErrPreconditionFailed", func() {
+		_, etag, err := gateway.GetPaymentWithETag(ctx, created.Id)
+		suite.NoError(err)
+		suite.NotEmpty(etag)
+		_, err = gateway.Refund(client.WithIfMatch(ctx, etag), created.Id, 100)
+		suite.NoError(err)
+		_, err = gateway.Refund(client.WithIfMatch(ctx, etag), created.Id, 100)
+		suite.True(errors.Is(err, client.ErrPreconditionFailed))
+	})
+
+	suite.Run("When listing payments it should page through the merchant's payments", func() {
+		_, err := gateway.CreatePayment(ctx, authorizedPayment())
+		suite.Require().NoError(err)
+		page, err := gateway.ListPayments(ctx, client.ListPaymentsParams{Limit: 1})
+		suite.NoError(err)
+		suite.Len(page.Payments, 1)
+		suite.Equal(2, page.Pagination.TotalItems)
+		suite.Equal(2, page.Pagination.TotalPage)
+
+		other, err := suite.newClient(suite.gateway.URL, "merchant_other").ListPayments(ctx, client.ListPaymentsParams{})
+		suite.NoError(err)
+		suite.Empty(other.Payments)
+	})
+
+	suite.Run("When the payment does not exist it should return ErrNotFound", func() {
+		_, err := gateway.GetPayment(ctx, "pay_0190b5a1c2d37e4f8a9b0c1d2e3f4a5b")
+		suite.True(errors.Is(err, client.ErrNotFound))
+	})
+
+	suite.Run("When the request is invalid it should return an APIError with the reasons", func() {
+		body := authorizedPayment()
+		body.ExpirationMonth = 13
+		_, err := gateway.CreatePayment(ctx, body)
+		suite.True(errors.Is(err, client.ErrBadRequest))
+		var apiErr *client.APIError
+		suite.Require().True(errors.As(err, &apiErr))
+		suite.Equal(http.StatusBadRequest, apiErr.StatusCode)
+		suite.NotEmpty(apiErr.Errors)
+	})
+
+	suite.Run("When refunding more than was captured it should return ErrBadRequest", func() {
+		_, err := gateway.Refund(ctx, created.Id, 100000)
+		suite.True(errors.Is(err, client.ErrBadRequest))
+	})
+}
+
+func (suite *clientTestSuite) Test_Retries() {
+	ctx := context.Background()
+
+	suite.Run("When the gateway is briefly unavailable it should retry", func() {
+		proxy, requests := suite.flakyProxy(2, http.StatusServiceUnavailable, false)
+		defer proxy.Close()
+		payment, err := suite.newClient(proxy.URL, "merchant_retries").CreatePayment(ctx, authorizedPayment())
+		suite.NoError(err)
+		suite.Equal(enums.AUTHORIZED, payment.Status)
+		suite.Equal(int32(3), atomic.LoadInt32(requests))
+	})
+
+	suite.Run("When rate limited it should retry after the advertised delay", func() {
+		proxy, requests := suite.flakyProxy(1, http.StatusTooManyRequests, false)
+		defer proxy.Close()
+		_, err := suite.newClient(proxy.URL, "merchant_retries").ListPayments(ctx, client.ListPaymentsParams{})
+		suite.NoError(err)
+		suite.Equal(int32(2), atomic.LoadInt32(requests))
+	})
+
+	suite.Run("When retries are exhausted it should return the last error", func() {
+		proxy, requests := suite.flakyProxy(10, http.StatusBadGateway, false)
+		defer proxy.Close()
+		_, err := suite.newClient(proxy.URL, "merchant_retries").ListPayments(ctx, client.ListPaymentsParams{})
+		suite.True(errors.Is(err, client.ErrGatewayFailure))
+		suite.Equal(int32(client.DEFAULT_MAX_RETRIES+1), atomic.LoadInt32(requests))
+	})
+
+	suite.Run("When the response to a payment is lost the retry should not pay twice", func() {
+		proxy, _ := suite.flakyProxy(1, http.StatusBadGateway, true)
+		defer proxy.Close()
+		gateway := suite.newClient(proxy.URL, "merchant_lost_response")
+		payment, err := gateway.CreatePayment(ctx, authorizedPayment())
+		suite.NoError(err)
+		page, err := gateway.ListPayments(ctx, client.ListPaymentsParams{})
+		suite.NoError(err)
+		suite.Len(page.Payments, 1)
+		suite.Equal(payment.Id, page.Payments[0].Id)
+	})
+
+	suite.Run("When the caller supplies an idempotency key it should be reused", func() {
+		gateway := suite.newClient(suite.gateway.URL, "merchant_keys")
+		keyed := client.WithIdempotencyKey(ctx, "order-1234")
+		first, err := gateway.CreatePayment(keyed, authorizedPayment())
+		suite.NoError(err)
+		second, err := gateway.CreatePayment(keyed, authorizedPayment())
+		suite.NoError(err)
+		suite.Equal(first.Id, second.Id)
+
+		changed := authorizedPayment()
+		changed.Amount = 2000
+		_, err = gateway.CreatePayment(keyed, changed)
+		suite.True(errors.Is(err, client.ErrUnprocessable))
+	})
+}
+
+func TestClientTestSuite(t *testing.T) {
+	suite.Run(t, new(clientTestSuite))
+}
@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/customers"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/eventlog"
+	"github.com/stretchr/testify/suite"
+)
+
+type customersTestSuite struct {
+	suite.Suite
+	now time.Time
+}
+
+func (suite *customersTestSuite) SetupTest() {
+	suite.now = time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC)
+}
+
+func (suite *customersTestSuite) Test_Persistence() {
+	path := filepath.Join(suite.T().TempDir(), "customers.log")
+	fileLog, err := eventlog.OpenFileLog(path)
+	suite.Require().NoError(err)
+	store, err := customers.Open(fileLog)
+	suite.Require().NoError(err)
+	customer, err := store.Create(customers.Customer{MerchantId: "merchant_a", Email: "shopper@example.com", CreatedAt: suite.now})
+	suite.Require().NoError(err)
+	method, err := store.AttachCard(customer.Id, "merchant_a", customers.PaymentMethod{CardToken: "crd_1", CardLast4: "8877", CardFingerprint: "f1", ExpirationMonth: 4, ExpirationYear: 2030, Brand: "mastercard", CreatedAt: suite.now})
+	suite.Require().NoError(err)
+	fileLog.Close()
+
+	suite.Run("It should recover customers and their saved cards from the log", func() {
+		reopened, err := eventlog.OpenFileLog(path)
+		suite.Require().NoError(err)
+		defer reopened.Close()
+		recovered, err := customers.Open(reopened)
+		suite.Require().NoError(err)
+
+		recoveredMethod, err := recovered.PaymentMethod(customer.Id, "merchant_a", method.Id)
+		suite.NoError(err)
+		suite.Equal(method, recoveredMethod)
+		_, err = recovered.Get(customer.Id, "merchant_b")
+		suite.ErrorIs(err, customers.ErrCustomerNotFound)
+	})
+
+	suite.Run("When the customer is unknown it should not save the card", func() {
+		_, err := store.AttachCard("cus_unknown", "merchant_a", customers.PaymentMethod{CardToken: "crd_2"})
+		suite.ErrorIs(err, customers.ErrCustomerNotFound)
+		content, err := os.ReadFile(path)
+		suite.NoError(err)
+		suite.NotContains(string(content), "crd_2")
+	})
+}
+
+func TestCustomersTestSuite(t *testing.T) {
+	suite.Run(t, new(customersTestSuite))
+}
@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/docs"
+	"github.com/stretchr/testify/suite"
+)
+
+type openAPITestSuite struct {
+	suite.Suite
+}
+
+func (suite *openAPITestSuite) Test_Export() {
+	exported, err := docs.OpenAPI3()
+	suite.Require().NoError(err)
+
+	suite.Run("When loaded it should be a valid OpenAPI 3 document", func() {
+		suite.NoError(exported.Validate(context.Background()))
+	})
+
+	suite.Run("When compared with swagger.json it should be up to date", func() {
+		converted, err := docs.ConvertSwagger()
+		suite.Require().NoError(err)
+		expected, _ := json.Marshal(converted)
+		actual, _ := json.Marshal(exported)
+		suite.JSONEq(string(expected), string(actual), "run go run ./cmd/openapi3 after swag init")
+	})
+
+	suite.Run("When listing paths it should document the payments API", func() {
+		for _, path := range []string{"/api/v1/payments", "/api/v1/payments/{id}", "/api/v1/payments/{id}/captures", "/api/v1/payments/{id}/refunds"} {
+			suite.NotNil(exported.Paths.Find(path), path)
+		}
+	})
+}
+
+func TestOpenAPITestSuite(t *testing.T) {
+	suite.Run(t, new(openAPITestSuite))
+}
@@ -0,0 +1,37 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/etags"
+	"github.com/stretchr/testify/suite"
+)
+
+type etagsTestSuite struct {
+	suite.Suite
+}
+
+func (suite *etagsTestSuite) Test_Strong() {
+	suite.Equal(`"v2-3"`, etags.Strong("v2", 3))
+	suite.NotEqual(etags.Strong("v1", 3), etags.Strong("v2", 3))
+}
+
+func (suite *etagsTestSuite) Test_MatchWeak() {
+	suite.True(etags.MatchWeak(`"v2-3"`, etags.Strong("v2", 3)))
+	suite.True(etags.MatchWeak(`"v2-1", W/"v2-3"`, etags.Strong("v2", 3)))
+	suite.True(etags.MatchWeak(`*`, etags.Strong("v2", 3)))
+	suite.False(etags.MatchWeak(`"v1-3"`, etags.Strong("v2", 3)))
+	suite.False(etags.MatchWeak(``, etags.Strong("v2", 3)))
+}
+
+func (suite *etagsTestSuite) Test_MatchStrong() {
+	suite.True(etags.MatchStrong(`"v2-3"`, etags.Strong("v2", 3)))
+	suite.True(etags.MatchStrong(`"v2-2" , "v2-3"`, etags.Strong("v2", 3)))
+	suite.True(etags.MatchStrong(`*`, etags.Strong("v2", 3)))
+	suite.False(etags.MatchStrong(`W/"v2-3"`, etags.Strong("v2", 3)))
+	suite.False(etags.MatchStrong(`v2-3`, etags.Strong("v2", 3)))
+}
+
+func TestEtagsTestSuite(t *testing.T) {
+	suite.Run(t, new(etagsTestSuite))
+}
@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/fx"
+	"github.com/stretchr/testify/suite"
+)
+
+type fxTestSuite struct {
+	suite.Suite
+}
+
+func (suite *fxTestSuite) Test_Convert() {
+	suite.Equal(7874, fx.Convert(10000, fx.Rate{Base: "USD", Quote: "GBP", Rate: 0.7874}))
+	suite.Equal(5012, fx.Convert(10000, fx.Rate{Base: "JPY", Quote: "GBP", Rate: 0.005012}))
+	suite.Equal(200, fx.Convert(100, fx.Rate{Base: "GBP", Quote: "JPY", Rate: 199.52}))
+	suite.Equal(1, fx.Convert(1, fx.Rate{Base: "USD", Quote: "GBP", Rate: 0.5}))
+}
+
+func (suite *fxTestSuite) Test_Static() {
+	asOf := time.Date(2024, time.June, 15, 16, 0, 0, 0, time.UTC)
+	rates, err := fx.ParseRates("USD/GBP=0.8, eur/gbp=0.85")
+	suite.NoError(err)
+	provider := fx.NewStatic(rates, asOf, "static")
+
+	suite.Run("When the pair is configured it should return it", func() {
+		rate, err := provider.Rate("EUR", "GBP")
+		suite.NoError(err)
+		suite.Equal(0.85, rate.Rate)
+		suite.Equal(asOf, rate.Timestamp)
+		suite.Equal("static", rate.Source)
+	})
+
+	suite.Run("When only the reverse pair is configured it should invert it", func() {
+		rate, err := provider.Rate("GBP", "USD")
+		suite.NoError(err)
+		suite.Equal(1.25, rate.Rate)
+	})
+
+	suite.Run("When the pair is unknown it should return ErrRateNotFound", func() {
+		_, err := provider.Rate("JPY", "GBP")
+		suite.True(errors.Is(err, fx.ErrRateNotFound))
+	})
+
+	suite.Run("When a rate is malformed it should fail to parse", func() {
+		_, err := fx.ParseRates("USD/GBP=abc")
+		suite.Error(err)
+		_, err = fx.ParseRates("USDGBP=0.8")
+		suite.Error(err)
+	})
+}
+
+func (suite *fxTestSuite) Test_File() {
+	path := filepath.Join(suite.T().TempDir(), "rates.json")
+	suite.NoError(os.WriteFile(path, []byte(`{"timestamp":"2024-06-15T16:00:00Z","source":"ecb","rates":{"USD/GBP":0.8}}`), 0o644))
+	provider, err := fx.OpenFile(path)
+	suite.NoError(err)
+
+	rate, err := provider.Rate("USD", "GBP")
+	suite.NoError(err)
+	suite.Equal(0.8, rate.Rate)
+	suite.Equal("ecb", rate.Source)
+
+	suite.Run("When the file changes it should serve the new rates", func() {
+		suite.NoError(os.WriteFile(path, []byte(`{"timestamp":"2024-06-16T16:00:00Z","source":"ecb","rates":{"USD/GBP":0.79}}`), 0o644))
+		suite.NoError(os.Chtimes(path, time.Now().Add(time.Minute), time.Now().Add(time.Minute)))
+		rate, err := provider.Rate("USD", "GBP")
+		suite.NoError(err)
+		suite.Equal(0.79, rate.Rate)
+	})
+
+	suite.Run("When the new file is invalid it should keep the previous rates", func() {
+		suite.NoError(os.WriteFile(path, []byte(`{not json`), 0o644))
+		suite.NoError(os.Chtimes(path, time.Now().Add(2*time.Minute), time.Now().Add(2*time.Minute)))
+		rate, err := provider.Rate("USD", "GBP")
+		suite.NoError(err)
+		suite.Equal(0.79, rate.Rate)
+	})
+}
+
+func TestFXTestSuite(t *testing.T) {
+	suite.Run(t, new(fxTestSuite))
+}
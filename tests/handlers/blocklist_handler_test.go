@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"net/http"
+)
+
+func (suite *integrationTestSuite) Test_Blocklist() {
+	payment := req.CreatePaymentReqModel{
+		CardNumber:      "4000000000000001",
+		ExpirationMonth: 4,
+		ExpirationYear:  2025,
+		Currency:        "GBP",
+		Amount:          100,
+		CVV:             "123",
+		Email:           "Shopper@Example.com",
+	}
+
+	suite.Run("When the bin is invalid it should return 400", func() {
+		statusCode, _ := suite.postJSON("/api/v1/admin/blocklist", req.CreateBlocklistEntryReqModel{
+			Type:   "bin",
+			Value:  "40a",
+			Reason: "compromised range",
+		})
+		suite.Equal(http.StatusBadRequest, statusCode)
+	})
+
+	suite.Run("When the card bin is blocked it should reject the payment without calling the bank", func() {
+		statusCode, entry := suite.postJSON("/api/v1/admin/blocklist", req.CreateBlocklistEntryReqModel{
+			Type:   "bin",
+			Value:  "400000",
+			Reason: "compromised range",
+		})
+		suite.Equal(http.StatusCreated, statusCode)
+
+		statusCode, rejected := suite.postJSON("/api/v1/payments", payment)
+		suite.Equal(http.StatusOK, statusCode)
+		suite.Equal(enums.REJECTED, rejected["status"])
+		suite.Equal("blocked_bin", rejected["reason_code"])
+		suite.Equal("", rejected["acquirer"])
+
+		request, err := http.NewRequest(http.MethodDelete, suite.testingServer.URL+"/api/v1/admin/blocklist/"+entry["id"].(string), nil)
+		suite.NoError(err)
+		response, err := http.DefaultClient.Do(request)
+		suite.NoError(err)
+		suite.Equal(http.StatusOK, response.StatusCode)
+
+		statusCode, authorized := suite.postJSON("/api/v1/payments", payment)
+		suite.Equal(http.StatusOK, statusCode)
+		suite.Equal(enums.AUTHORIZED, authorized["status"])
+	})
+
+	suite.Run("When the email is blocked it should match regardless of case", func() {
+		statusCode, entry := suite.postJSON("/api/v1/admin/blocklist", req.CreateBlocklistEntryReqModel{
+			Type:   "email",
+			Value:  "shopper@example.com",
+			Reason: "chargeback abuse",
+		})
+		suite.Equal(http.StatusCreated, statusCode)
+
+		_, rejected := suite.postJSON("/api/v1/payments", payment)
+		suite.Equal("blocked_email", rejected["reason_code"])
+
+		request, _ := http.NewRequest(http.MethodDelete, suite.testingServer.URL+"/api/v1/admin/blocklist/"+entry["id"].(string), nil)
+		http.DefaultClient.Do(request)
+	})
+}
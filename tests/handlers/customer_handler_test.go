@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+)
+
+func (suite *integrationTestSuite) createCustomerWithCard(cardNumber string) (string, string) {
+	statusCode, customer := suite.postJSON("/api/v1/customers", req.CreateCustomerReqModel{
+		Email: "shopper@example.com",
+		Name:  "Returning Shopper",
+	})
+	suite.Equal(http.StatusCreated, statusCode)
+	customerId := customer["id"].(string)
+
+	statusCode, method := suite.postJSON("/api/v1/customers/"+customerId+"/payment_methods", req.AttachCardReqModel{
+		CardNumber:      cardNumber,
+		ExpirationMonth: 4,
+		ExpirationYear:  2026,
+	})
+	suite.Equal(http.StatusCreated, statusCode)
+	suite.Equal(cardNumber[len(cardNumber)-4:], method["last_four_card_digit"])
+	suite.NotContains(method, "card_number")
+	return customerId, method["id"].(string)
+}
+
+func (suite *integrationTestSuite) Test_Customers() {
+	suite.Run("When paying with a saved card it should use its details and record the customer", func() {
+		customerId, methodId := suite.createCustomerWithCard("2222405343248877")
+
+		statusCode, payment := suite.postJSON("/api/v1/payments", req.CreatePaymentReqModel{
+			CustomerId:      customerId,
+			PaymentMethodId: methodId,
+			Currency:        "GBP",
+			Amount:          250,
+			CVV:             "123",
+		})
+		suite.Equal(http.StatusOK, statusCode)
+		suite.Equal(enums.AUTHORIZED, payment["status"])
+		suite.Equal(customerId, payment["customer_id"])
+		suite.Equal("8877", payment["last_four_card_digit"])
+		suite.Equal(float64(2026), payment["expiry_year"])
+
+		_, customer := suite.getJSON("/api/v1/customers/" + customerId)
+		suite.Len(customer["payment_methods"], 1)
+	})
+
+	suite.Run("When listing a customer's payments it should page through them", func() {
+		customerId, methodId := suite.createCustomerWithCard("2222405343248877")
+		for i := 0; i < 3; i++ {
+			statusCode, _ := suite.postJSON("/api/v1/payments", req.CreatePaymentReqModel{
+				CustomerId:      customerId,
+				PaymentMethodId: methodId,
+				Currency:        "GBP",
+				Amount:          100 + i,
+				CVV:             "123",
+			})
+			suite.Equal(http.StatusOK, statusCode)
+		}
+
+		response, err := http.Get(suite.testingServer.URL + "/api/v1/customers/" + customerId + "/payments?page=2&limit=2")
+		suite.NoError(err)
+		defer response.Body.Close()
+		var body api_response.ResponseWithPagination
+		suite.NoError(json.NewDecoder(response.Body).Decode(&body))
+		suite.Equal(http.StatusOK, response.StatusCode)
+		suite.Equal(3, body.Pagination.TotalItems)
+		suite.Equal(2, body.Pagination.TotalPage)
+		payments := body.Data.([]interface{})
+		suite.Len(payments, 1)
+		suite.Equal(float64(102), payments[0].(map[string]interface{})["amount"])
+	})
+
+	suite.Run("When both a card number and a saved card are sent it should return 400", func() {
+		customerId, methodId := suite.createCustomerWithCard("2222405343248877")
+		statusCode, _ := suite.postJSON("/api/v1/payments", req.CreatePaymentReqModel{
+			CardNumber:      "2222405343248877",
+			ExpirationMonth: 4,
+			ExpirationYear:  2026,
+			CustomerId:      customerId,
+			PaymentMethodId: methodId,
+			Currency:        "GBP",
+			Amount:          250,
+			CVV:             "123",
+		})
+		suite.Equal(http.StatusBadRequest, statusCode)
+	})
+
+	suite.Run("When the saved card belongs to another customer it should return 404", func() {
+		_, methodId := suite.createCustomerWithCard("2222405343248877")
+		otherCustomerId, _ := suite.createCustomerWithCard("2222405343248877")
+		statusCode, _ := suite.postJSON("/api/v1/payments", req.CreatePaymentReqModel{
+			CustomerId:      otherCustomerId,
+			PaymentMethodId: methodId,
+			Currency:        "GBP",
+			Amount:          250,
+			CVV:             "123",
+		})
+		suite.Equal(http.StatusNotFound, statusCode)
+	})
+
+	suite.Run("When the attached card has expired it should return 400", func() {
+		statusCode, customer := suite.postJSON("/api/v1/customers", req.CreateCustomerReqModel{})
+		suite.Equal(http.StatusCreated, statusCode)
+		statusCode, _ = suite.postJSON("/api/v1/customers/"+customer["id"].(string)+"/payment_methods", req.AttachCardReqModel{
+			CardNumber:      "2222405343248877",
+			ExpirationMonth: 1,
+			ExpirationYear:  2024,
+		})
+		suite.Equal(http.StatusBadRequest, statusCode)
+	})
+}
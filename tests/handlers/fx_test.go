@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/handlers"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/fx"
+)
+
+func (suite *integrationTestSuite) Test_SettlementConversion() {
+	asOf := time.Date(2024, time.June, 15, 9, 0, 0, 0, time.UTC)
+	handlers.SetSettlement("GBP", fx.NewStatic(map[string]float64{"USD/GBP": 0.8}, asOf, "static"))
+	defer handlers.SetSettlement("", nil)
+
+	payment := func(cardNumber string, currency string) req.CreatePaymentReqModel {
+		return req.CreatePaymentReqModel{
+			CardNumber:      cardNumber,
+			ExpirationMonth: 4,
+			ExpirationYear:  2026,
+			Currency:        currency,
+			Amount:          1099,
+			CVV:             "123",
+		}
+	}
+
+	suite.Run("When an authorized payment is in another currency it should record the conversion", func() {
+		statusCode, body := suite.postJSON("/api/v1/payments", payment("2222405343248877", "USD"))
+		suite.Equal(http.StatusOK, statusCode)
+		suite.Equal(enums.AUTHORIZED, body["status"])
+		suite.Equal("USD", body["currency_code"])
+		suite.Equal(float64(1099), body["amount"])
+
+		conversion := body["fx"].(map[string]interface{})
+		suite.Equal("GBP", conversion["settlement_currency"])
+		suite.Equal(float64(879), conversion["settlement_amount"])
+		suite.Equal(0.8, conversion["rate"])
+		suite.Equal("2024-06-15T09:00:00Z", conversion["rate_timestamp"])
+	})
+
+	suite.Run("When the payment is in the settlement currency it should not convert", func() {
+		statusCode, body := suite.postJSON("/api/v1/payments", payment("2222405343248877", "GBP"))
+		suite.Equal(http.StatusOK, statusCode)
+		suite.Nil(body["fx"])
+	})
+
+	suite.Run("When the bank declines it should not record a conversion", func() {
+		statusCode, body := suite.postJSON("/api/v1/payments", payment("2222405343248112", "USD"))
+		suite.Equal(http.StatusOK, statusCode)
+		suite.Equal(enums.DECLIEND, body["status"])
+		suite.Nil(body["fx"])
+	})
+
+	suite.Run("When no rate is available it should reject the payment", func() {
+		statusCode, _ := suite.postJSON("/api/v1/payments", payment("2222405343248877", "JPY"))
+		suite.Equal(http.StatusBadRequest, statusCode)
+	})
+}
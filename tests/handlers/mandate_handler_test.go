@@ -0,0 +1,189 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/handlers"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/subscriptions"
+)
+
+func (suite *integrationTestSuite) createMandate(cardNumber string) string {
+	statusCode, payment := suite.postJSON("/api/v1/payments", req.CreatePaymentReqModel{
+		CardNumber:      cardNumber,
+		ExpirationMonth: 4,
+		ExpirationYear:  2026,
+		Currency:        "GBP",
+		Amount:          100,
+		CVV:             "123",
+		SetupMandate:    true,
+	})
+	suite.Equal(http.StatusOK, statusCode)
+	suite.Equal(enums.AUTHORIZED, payment["status"])
+	suite.Equal(false, payment["merchant_initiated"])
+	mandateId, _ := payment["mandate_id"].(string)
+	suite.NotEmpty(mandateId)
+	return mandateId
+}
+
+func (suite *integrationTestSuite) getJSON(path string) (int, map[string]interface{}) {
+	response, err := http.Get(suite.testingServer.URL + path)
+	suite.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	var apiBody api_response.Response
+	suite.NoError(json.NewDecoder(response.Body).Decode(&apiBody), "no error when calling json decode")
+	data, _ := apiBody.Data.(map[string]interface{})
+	return response.StatusCode, data
+}
+
+func (suite *integrationTestSuite) Test_Mandates() {
+	suite.Run("When the first payment is declined it should not set up a mandate", func() {
+		statusCode, payment := suite.postJSON("/api/v1/payments", req.CreatePaymentReqModel{
+			CardNumber:      "2222405343248112",
+			ExpirationMonth: 4,
+			ExpirationYear:  2026,
+			Currency:        "GBP",
+			Amount:          100,
+			CVV:             "123",
+			SetupMandate:    true,
+		})
+		suite.Equal(http.StatusOK, statusCode)
+		suite.Equal(enums.DECLIEND, payment["status"])
+		suite.Nil(payment["mandate_id"])
+	})
+
+	suite.Run("When the mandate is charged it should authorize a merchant-initiated payment without a cvv", func() {
+		mandateId := suite.createMandate("2222405343248877")
+
+		statusCode, payment := suite.postJSON("/api/v1/mandates/"+mandateId+"/payments", req.MandatePaymentReqModel{
+			Currency: "GBP",
+			Amount:   999,
+		})
+		suite.Equal(http.StatusOK, statusCode)
+		suite.Equal(enums.AUTHORIZED, payment["status"])
+		suite.Equal(true, payment["merchant_initiated"])
+		suite.Equal(mandateId, payment["mandate_id"])
+		suite.Equal("8877", payment["last_four_card_digit"])
+		suite.Equal(float64(999), payment["amount"])
+	})
+
+	suite.Run("When the mandate is set up after 3-D Secure it should be created on authorization", func() {
+		statusCode, payment := suite.postJSON("/api/v1/payments", req.CreatePaymentReqModel{
+			CardNumber:      "2222405343248877",
+			ExpirationMonth: 4,
+			ExpirationYear:  2026,
+			Currency:        "EUR",
+			Amount:          100,
+			CVV:             "123",
+			ThreeDS:         true,
+			SetupMandate:    true,
+		})
+		suite.Equal(http.StatusOK, statusCode)
+		suite.Nil(payment["mandate_id"])
+		challengeURL := payment["three_ds"].(map[string]interface{})["challenge_url"].(string)
+		challengeId := challengeURL[len("/3ds/challenges/"):]
+		suite.answerChallenge(challengeId, "Y")
+
+		statusCode, payment = suite.postJSON("/api/v1/payments/"+payment["id"].(string)+"/3ds/callback", req.ThreeDSCallbackReqModel{
+			ChallengeId: challengeId,
+		})
+		suite.Equal(http.StatusOK, statusCode)
+		suite.Equal(enums.AUTHORIZED, payment["status"])
+		suite.NotEmpty(payment["mandate_id"])
+	})
+
+	suite.Run("When the mandate is revoked it should return 409", func() {
+		mandateId := suite.createMandate("2222405343248877")
+		request, _ := http.NewRequest(http.MethodDelete, suite.testingServer.URL+"/api/v1/mandates/"+mandateId, nil)
+		response, err := http.DefaultClient.Do(request)
+		suite.NoError(err)
+		suite.Equal(http.StatusOK, response.StatusCode)
+
+		statusCode, _ := suite.postJSON("/api/v1/mandates/"+mandateId+"/payments", req.MandatePaymentReqModel{
+			Currency: "GBP",
+			Amount:   999,
+		})
+		suite.Equal(http.StatusConflict, statusCode)
+	})
+
+	suite.Run("When the mandate does not exist it should return 404", func() {
+		statusCode, _ := suite.postJSON("/api/v1/mandates/mdt_0190b5a1c2d37e4f8a9b0c1d2e3f4a5b/payments", req.MandatePaymentReqModel{
+			Currency: "GBP",
+			Amount:   999,
+		})
+		suite.Equal(http.StatusNotFound, statusCode)
+	})
+}
+
+func (suite *integrationTestSuite) Test_Subscriptions() {
+	subscriptionStore := subscriptions.NewStore()
+	handlers.SetSubscriptions(subscriptionStore)
+	scheduler := subscriptions.NewScheduler(subscriptionStore, handlers.ChargeSubscription, suite.fakeClock)
+
+	suite.Run("When a subscription is due it should charge the mandate and schedule the next period", func() {
+		mandateId := suite.createMandate("2222405343248877")
+		statusCode, subscription := suite.postJSON("/api/v1/subscriptions", req.CreateSubscriptionReqModel{
+			MandateId:      mandateId,
+			Currency:       "GBP",
+			Amount:         1500,
+			IntervalMonths: 1,
+			RetrySchedule:  []string{"24h"},
+		})
+		suite.Equal(http.StatusCreated, statusCode)
+		suite.Equal(subscriptions.ACTIVE, subscription["status"])
+
+		suite.Equal(1, scheduler.RunDue(suite.fakeClock.Now()))
+
+		_, subscription = suite.getJSON("/api/v1/subscriptions/" + subscription["id"].(string))
+		suite.Equal(subscriptions.ACTIVE, subscription["status"])
+		suite.Equal("2024-07-15T12:00:00Z", subscription["next_charge_at"])
+		paymentIds := subscription["payment_ids"].([]interface{})
+		suite.Len(paymentIds, 1)
+
+		_, payment := suite.getJSON("/api/v1/payments/" + paymentIds[0].(string))
+		suite.Equal(enums.AUTHORIZED, payment["status"])
+		suite.Equal(true, payment["merchant_initiated"])
+		suite.Equal(float64(1500), payment["amount"])
+	})
+
+	suite.Run("When the retries are exhausted it should mark the subscription unpaid", func() {
+		mandateId := suite.createMandate("2222405343248877")
+		statusCode, subscription := suite.postJSON("/api/v1/subscriptions", req.CreateSubscriptionReqModel{
+			MandateId:      mandateId,
+			Currency:       "GBP",
+			Amount:         1500,
+			IntervalMonths: 1,
+			RetrySchedule:  []string{"24h"},
+		})
+		suite.Equal(http.StatusCreated, statusCode)
+		subscriptionId := subscription["id"].(string)
+
+		request, _ := http.NewRequest(http.MethodDelete, suite.testingServer.URL+"/api/v1/mandates/"+mandateId, nil)
+		_, err := http.DefaultClient.Do(request)
+		suite.NoError(err)
+
+		scheduler.RunDue(suite.fakeClock.Now())
+		_, subscription = suite.getJSON("/api/v1/subscriptions/" + subscriptionId)
+		suite.Equal(subscriptions.PAST_DUE, subscription["status"])
+		suite.Equal("2024-06-16T12:00:00Z", subscription["next_charge_at"])
+
+		scheduler.RunDue(suite.fakeClock.Now().Add(24 * time.Hour))
+		_, subscription = suite.getJSON("/api/v1/subscriptions/" + subscriptionId)
+		suite.Equal(subscriptions.UNPAID, subscription["status"])
+	})
+
+	suite.Run("When the mandate does not exist it should return 404", func() {
+		statusCode, _ := suite.postJSON("/api/v1/subscriptions", req.CreateSubscriptionReqModel{
+			MandateId:      "mdt_0190b5a1c2d37e4f8a9b0c1d2e3f4a5b",
+			Currency:       "GBP",
+			Amount:         1500,
+			IntervalMonths: 1,
+		})
+		suite.Equal(http.StatusNotFound, statusCode)
+	})
+}
@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+)
+
+func (suite *integrationTestSuite) sendJSON(method string, path string, body interface{}, headers map[string]string) (*http.Response, map[string]interface{}) {
+	var requestBody []byte
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		suite.NoError(err, "no error when marshalling the request")
+		requestBody = encoded
+	}
+	request, err := http.NewRequest(method, suite.testingServer.URL+path, bytes.NewBuffer(requestBody))
+	suite.NoError(err)
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+	response, err := http.DefaultClient.Do(request)
+	suite.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	var apiBody api_response.Response
+	json.NewDecoder(response.Body).Decode(&apiBody)
+	data, _ := apiBody.Data.(map[string]interface{})
+	return response, data
+}
+
+func (suite *integrationTestSuite) Test_PaymentETags() {
+	response, created := suite.sendJSON(http.MethodPost, "/api/v1/payments", req.CreatePaymentReqModel{
+		CardNumber:      "2222405343248877",
+		ExpirationMonth: 4,
+		ExpirationYear:  2025,
+		Currency:        "GBP",
+		Amount:          1000,
+		CVV:             "123",
+	}, nil)
+	suite.Require().Equal(http.StatusOK, response.StatusCode)
+	ID := created["id"].(string)
+	createdETag := response.Header.Get("ETag")
+	suite.NotEmpty(createdETag)
+
+	suite.Run("When getting the payment it should return the same strong ETag", func() {
+		response, _ := suite.sendJSON(http.MethodGet, "/api/v1/payments/"+ID, nil, nil)
+		suite.Equal(http.StatusOK, response.StatusCode)
+		suite.Equal(createdETag, response.Header.Get("ETag"))
+		suite.NotContains(createdETag, "W/")
+	})
+
+	suite.Run("When If-None-Match lists the current ETag it should return 304", func() {
+		response, _ := suite.sendJSON(http.MethodGet, "/api/v1/payments/"+ID, nil, map[string]string{"If-None-Match": createdETag})
+		suite.Equal(http.StatusNotModified, response.StatusCode)
+		suite.Equal(createdETag, response.Header.Get("ETag"))
+
+		response, _ = suite.sendJSON(http.MethodGet, "/api/v2/payments/"+ID, nil, map[string]string{"If-None-Match": createdETag})
+		suite.Equal(http.StatusOK, response.StatusCode)
+		v2ETag := response.Header.Get("ETag")
+		suite.NotEqual(createdETag, v2ETag)
+
+		response, _ = suite.sendJSON(http.MethodGet, "/api/v2/payments/"+ID, nil, map[string]string{"If-None-Match": "W/" + v2ETag})
+		suite.Equal(http.StatusNotModified, response.StatusCode)
+	})
+
+	suite.Run("When capturing without If-Match it should return 428 and capture nothing", func() {
+		response, _ := suite.sendJSON(http.MethodPost, "/api/v1/payments/"+ID+"/captures", req.CapturePaymentReqModel{Amount: 1000}, nil)
+		suite.Equal(http.StatusPreconditionRequired, response.StatusCode)
+
+		response, _ = suite.sendJSON(http.MethodPost, "/api/v2/payments/"+ID+"/captures", req.CapturePaymentV2ReqModel{
+			Amount: req.MoneyV2ReqModel{Value: 1000, Currency: "GBP"},
+		}, nil)
+		suite.Equal(http.StatusPreconditionRequired, response.StatusCode)
+
+		_, payment := suite.getJSON("/api/v1/payments/" + ID)
+		suite.Equal(float64(0), payment["captured_amount"])
+	})
+
+	suite.Run("When capturing with the ETag of another API version it should return 412", func() {
+		response, _ := suite.sendJSON(http.MethodGet, "/api/v2/payments/"+ID, nil, nil)
+		response, _ = suite.sendJSON(http.MethodPost, "/api/v1/payments/"+ID+"/captures", req.CapturePaymentReqModel{Amount: 1000}, map[string]string{"If-Match": response.Header.Get("ETag")})
+		suite.Equal(http.StatusPreconditionFailed, response.StatusCode)
+		suite.Equal(createdETag, response.Header.Get("ETag"))
+	})
+
+	response, _ = suite.sendJSON(http.MethodPost, "/api/v1/payments/"+ID+"/captures", req.CapturePaymentReqModel{Amount: 1000}, map[string]string{"If-Match": createdETag})
+	suite.Require().Equal(http.StatusOK, response.StatusCode)
+	capturedETag := response.Header.Get("ETag")
+	suite.NotEqual(createdETag, capturedETag)
+
+	suite.Run("When the payment has changed it should return 200 with the new ETag", func() {
+		response, payment := suite.sendJSON(http.MethodGet, "/api/v1/payments/"+ID, nil, map[string]string{"If-None-Match": createdETag})
+		suite.Equal(http.StatusOK, response.StatusCode)
+		suite.Equal(capturedETag, response.Header.Get("ETag"))
+		suite.Equal(float64(1000), payment["captured_amount"])
+	})
+
+	suite.Run("When refunding without If-Match it should return 428", func() {
+		response, _ := suite.sendJSON(http.MethodPost, "/api/v1/payments/"+ID+"/refunds", req.RefundPaymentReqModel{Amount: 100}, nil)
+		suite.Equal(http.StatusPreconditionRequired, response.StatusCode)
+	})
+
+	suite.Run("When refunding with a stale ETag it should return 412 and refund nothing", func() {
+		response, _ := suite.sendJSON(http.MethodPost, "/api/v1/payments/"+ID+"/refunds", req.RefundPaymentReqModel{Amount: 100}, map[string]string{"If-Match": createdETag})
+		suite.Equal(http.StatusPreconditionFailed, response.StatusCode)
+		suite.Equal(capturedETag, response.Header.Get("ETag"))
+
+		_, payment := suite.getJSON("/api/v1/payments/" + ID)
+		suite.Equal(float64(0), payment["refunded_amount"])
+	})
+
+	suite.Run("When refunding with the current ETag it should refund once", func() {
+		response, payment := suite.sendJSON(http.MethodPost, "/api/v1/payments/"+ID+"/refunds", req.RefundPaymentReqModel{Amount: 100}, map[string]string{"If-Match": capturedETag})
+		suite.Equal(http.StatusOK, response.StatusCode)
+		suite.Equal(float64(100), payment["refunded_amount"])
+
+		response, _ = suite.sendJSON(http.MethodPost, "/api/v1/payments/"+ID+"/refunds", req.RefundPaymentReqModel{Amount: 100}, map[string]string{"If-Match": capturedETag})
+		suite.Equal(http.StatusPreconditionFailed, response.StatusCode)
+	})
+
+	suite.Run("When refunding with If-Match * it should refund whatever the version", func() {
+		response, payment := suite.sendJSON(http.MethodPost, "/api/v1/payments/"+ID+"/refunds", req.RefundPaymentReqModel{Amount: 100}, map[string]string{"If-Match": "*"})
+		suite.Equal(http.StatusOK, response.StatusCode)
+		suite.Equal(float64(200), payment["refunded_amount"])
+	})
+}
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/res"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ids"
+)
+
+func (suite *integrationTestSuite) getPaymentEvents(ID string) (int, []res.PaymentEvent) {
+	response, err := http.Get(suite.testingServer.URL + "/api/v1/payments/" + ID + "/events")
+	suite.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	var apiBody struct {
+		Data []res.PaymentEvent `json:"data"`
+	}
+	suite.NoError(json.NewDecoder(response.Body).Decode(&apiBody), "no error when calling json decode")
+	return response.StatusCode, apiBody.Data
+}
+
+func (suite *integrationTestSuite) Test_PaymentEvents() {
+	start := suite.fakeClock.Now()
+	defer suite.fakeClock.Set(start)
+
+	suite.Run("It should list every status transition in order with its time, actor and reason", func() {
+		response, created := suite.sendJSON(http.MethodPost, "/api/v1/payments", req.CreatePaymentReqModel{
+			CardNumber:      "2222405343248877",
+			ExpirationMonth: 4,
+			ExpirationYear:  2025,
+			Currency:        "GBP",
+			Amount:          1000,
+			CVV:             "123",
+		}, nil)
+		suite.Require().Equal(http.StatusOK, response.StatusCode)
+		ID := created["id"].(string)
+
+		suite.fakeClock.Advance(time.Minute)
+		response, _ = suite.sendJSON(http.MethodPost, "/api/v1/payments/"+ID+"/captures", req.CapturePaymentReqModel{Amount: 1000}, map[string]string{"If-Match": "*"})
+		suite.Require().Equal(http.StatusOK, response.StatusCode)
+
+		suite.fakeClock.Advance(time.Minute)
+		response, _ = suite.sendJSON(http.MethodPost, "/api/v1/payments/"+ID+"/refunds", req.RefundPaymentReqModel{Amount: 1000}, map[string]string{"If-Match": "*"})
+		suite.Require().Equal(http.StatusOK, response.StatusCode)
+
+		statusCode, events := suite.getPaymentEvents(ID)
+		suite.Equal(http.StatusOK, statusCode)
+		suite.Require().Len(events, 4)
+		expected := []res.PaymentEvent{
+			{From: "", To: enums.PENDING, Timestamp: start, Reason: "payment requested", Actor: enums.ACTOR_GATEWAY},
+			{From: enums.PENDING, To: enums.AUTHORIZED, Timestamp: start, Reason: "authorized by acquiring bank", Actor: enums.ACTOR_ACQUIRING_BANK},
+			{From: enums.AUTHORIZED, To: enums.CAPTURED, Timestamp: start.Add(time.Minute), Reason: "captured by merchant", Actor: enums.ACTOR_MERCHANT},
+			{From: enums.CAPTURED, To: enums.REFUNDED, Timestamp: start.Add(2 * time.Minute), Reason: "refunded by merchant", Actor: enums.ACTOR_MERCHANT},
+		}
+		for i, event := range events {
+			suite.Equal(expected[i].From, event.From)
+			suite.Equal(expected[i].To, event.To)
+			suite.True(expected[i].Timestamp.Equal(event.Timestamp), "event %d timestamp %s", i, event.Timestamp)
+			suite.Equal(expected[i].Reason, event.Reason)
+			suite.Equal(expected[i].Actor, event.Actor)
+		}
+	})
+
+	suite.Run("When the payment does not exist it should return 404", func() {
+		ID, err := ids.NewPaymentId()
+		suite.Require().NoError(err)
+		statusCode, _ := suite.getPaymentEvents(ID)
+		suite.Equal(http.StatusNotFound, statusCode)
+	})
+
+}
@@ -0,0 +1,204 @@
+package tests
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/handlers"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/middlewares"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/idempotency"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ratelimit"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/proto/paymentpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func (suite *integrationTestSuite) grpcClient(interceptors ...grpc.UnaryServerInterceptor) paymentpb.PaymentServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	interceptors = append([]grpc.UnaryServerInterceptor{middlewares.MerchantAuthInterceptor(nil)}, interceptors...)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
+	paymentpb.RegisterPaymentServiceServer(server, &handlers.PaymentServiceServer{})
+	go server.Serve(listener)
+	suite.T().Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	suite.Require().NoError(err)
+	suite.T().Cleanup(func() { conn.Close() })
+	return paymentpb.NewPaymentServiceClient(conn)
+}
+
+func (suite *integrationTestSuite) Test_PaymentGRPC() {
+	client := suite.grpcClient()
+	ctx := metadata.AppendToOutgoingContext(context.Background(), middlewares.MERCHANT_ID_METADATA, "merchant_grpc")
+	cardPayment := func(cardNumber string) *paymentpb.CreatePaymentRequest {
+		return &paymentpb.CreatePaymentRequest{
+			CardNumber:      cardNumber,
+			ExpirationMonth: 4,
+			ExpirationYear:  2025,
+			Currency:        "GBP",
+			Amount:          100,
+			Cvv:             "123",
+		}
+	}
+
+	suite.Run("When the bank authorizes it should return the payment, also served over REST", func() {
+		payment, err := client.CreatePayment(ctx, cardPayment("2222405343248877"))
+		suite.Require().NoError(err)
+		suite.Equal(enums.AUTHORIZED, payment.Status)
+		suite.Equal("8877", payment.LastFourCardDigit)
+		suite.Equal(int64(100), payment.Amount)
+		suite.Equal("default", payment.Acquirer)
+
+		fetched, err := client.GetPayment(ctx, &paymentpb.GetPaymentRequest{Id: payment.Id})
+		suite.Require().NoError(err)
+		suite.Equal(payment.Status, fetched.Status)
+		suite.True(payment.CreatedAt.AsTime().Equal(fetched.CreatedAt.AsTime()))
+
+		response, details := suite.sendJSON(http.MethodGet, "/api/v1/payments/"+payment.Id, nil, map[string]string{"X-Merchant-Id": "merchant_grpc"})
+		suite.Equal(http.StatusOK, response.StatusCode)
+		suite.Equal(enums.AUTHORIZED, details["status"])
+	})
+
+	suite.Run("When the bank declines it should return the declined payment", func() {
+		payment, err := client.CreatePayment(ctx, cardPayment("2222405343248112"))
+		suite.Require().NoError(err)
+		suite.Equal(enums.DECLIEND, payment.Status)
+	})
+
+	suite.Run("When the request is invalid it should return InvalidArgument", func() {
+		request := cardPayment("12345567")
+		_, err := client.CreatePayment(ctx, request)
+		suite.Equal(codes.InvalidArgument, status.Code(err))
+		suite.Contains(status.Convert(err).Message(), "CardNumber")
+	})
+
+	suite.Run("When the card has expired it should return InvalidArgument", func() {
+		request := cardPayment("2222405343248877")
+		request.ExpirationYear = 2023
+		_, err := client.CreatePayment(ctx, request)
+		suite.Equal(codes.InvalidArgument, status.Code(err))
+		suite.Equal("expiry date must be in future date", status.Convert(err).Message())
+	})
+
+	suite.Run("When the customer does not exist it should return NotFound", func() {
+		_, err := client.CreatePayment(ctx, &paymentpb.CreatePaymentRequest{
+			CustomerId:      "cus_0190b5a1c2d37e4f8a9b0c1d2e3f4a5b",
+			PaymentMethodId: "pm_0190b5a1c2d37e4f8a9b0c1d2e3f4a5b",
+			Currency:        "GBP",
+			Amount:          100,
+			Cvv:             "123",
+		})
+		suite.Equal(codes.NotFound, status.Code(err))
+	})
+
+	suite.Run("When the bank cannot be reached it should return Unavailable", func() {
+		unreachable := httptest.NewServer(http.NotFoundHandler())
+		unreachable.Close()
+		os.Setenv("ACQUIRING_BANK_BASE_URL", unreachable.URL)
+		defer os.Setenv("ACQUIRING_BANK_BASE_URL", suite.bankSimulator.URL)
+
+		_, err := client.CreatePayment(ctx, cardPayment("2222405343248877"))
+		suite.Equal(codes.Unavailable, status.Code(err))
+	})
+
+	suite.Run("When getting a payment with an invalid or unknown id it should return the matching code", func() {
+		_, err := client.GetPayment(ctx, &paymentpb.GetPaymentRequest{Id: "not-a-payment"})
+		suite.Equal(codes.InvalidArgument, status.Code(err))
+		_, err = client.GetPayment(ctx, &paymentpb.GetPaymentRequest{Id: "pay_0190b5a1c2d37e4f8a9b0c1d2e3f4a5b"})
+		suite.Equal(codes.NotFound, status.Code(err))
+	})
+
+	suite.Run("When another merchant gets the payment it should return NotFound", func() {
+		otherCtx := metadata.AppendToOutgoingContext(context.Background(), middlewares.MERCHANT_ID_METADATA, "merchant_grpc_other")
+		payment, err := client.CreatePayment(otherCtx, cardPayment("2222405343248877"))
+		suite.Require().NoError(err)
+		_, err = client.GetPayment(ctx, &paymentpb.GetPaymentRequest{Id: payment.Id})
+		suite.Equal(codes.NotFound, status.Code(err))
+	})
+
+	suite.Run("When listing payments it should only return the merchant's, paginated", func() {
+		page, err := client.ListPayments(ctx, &paymentpb.ListPaymentsRequest{Limit: 1})
+		suite.Require().NoError(err)
+		suite.Len(page.Payments, 1)
+		suite.Equal(int32(2), page.Pagination.TotalItems)
+		suite.Equal(int32(2), page.Pagination.TotalPage)
+
+		declined, err := client.ListPayments(ctx, &paymentpb.ListPaymentsRequest{Status: enums.DECLIEND})
+		suite.Require().NoError(err)
+		suite.Len(declined.Payments, 1)
+		suite.Equal("8112", declined.Payments[0].LastFourCardDigit)
+
+		other, err := client.ListPayments(metadata.AppendToOutgoingContext(context.Background(), middlewares.MERCHANT_ID_METADATA, "merchant_other"), &paymentpb.ListPaymentsRequest{})
+		suite.Require().NoError(err)
+		suite.Empty(other.Payments)
+
+		_, err = client.ListPayments(ctx, &paymentpb.ListPaymentsRequest{Limit: 101})
+		suite.Equal(codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func (suite *integrationTestSuite) Test_PaymentGRPCLimits() {
+	perCard := ratelimit.Limit{Burst: 2, Period: time.Hour}
+	client := suite.grpcClient(
+		middlewares.IdempotencyInterceptor(idempotency.NewStore(24*time.Hour), suite.fakeClock, paymentpb.PaymentService_CreatePayment_FullMethodName),
+		middlewares.RateLimitInterceptor(ratelimit.NewMemoryStore(), middlewares.RateLimitConfig{PerCard: &perCard, Clock: suite.fakeClock}, paymentpb.PaymentService_CreatePayment_FullMethodName),
+	)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), middlewares.MERCHANT_ID_METADATA, "merchant_grpc_limits")
+	cardPayment := func(cardNumber string, amount int64) *paymentpb.CreatePaymentRequest {
+		return &paymentpb.CreatePaymentRequest{
+			CardNumber:      cardNumber,
+			ExpirationMonth: 4,
+			ExpirationYear:  2025,
+			Currency:        "GBP",
+			Amount:          amount,
+			Cvv:             "123",
+		}
+	}
+
+	suite.Run("When a call repeats an idempotency key it should return the first payment without using up the limit", func() {
+		keyed := metadata.AppendToOutgoingContext(ctx, middlewares.IDEMPOTENCY_KEY_METADATA, "idem_grpc")
+		first, err := client.CreatePayment(keyed, cardPayment("2222405343248877", 100))
+		suite.Require().NoError(err)
+
+		for i := 0; i < 2; i++ {
+			var header metadata.MD
+			replayed, err := client.CreatePayment(keyed, cardPayment("2222405343248877", 100), grpc.Header(&header))
+			suite.Require().NoError(err)
+			suite.Equal(first.Id, replayed.Id)
+			suite.Equal([]string{"true"}, header.Get(middlewares.IDEMPOTENT_REPLAYED_METADATA))
+		}
+
+		_, err = client.CreatePayment(ctx, cardPayment("2222405343248877", 100))
+		suite.Require().NoError(err)
+	})
+
+	suite.Run("When the key is reused with another request it should return InvalidArgument", func() {
+		keyed := metadata.AppendToOutgoingContext(ctx, middlewares.IDEMPOTENCY_KEY_METADATA, "idem_grpc")
+		_, err := client.CreatePayment(keyed, cardPayment("2222405343248877", 200))
+		suite.Equal(codes.InvalidArgument, status.Code(err))
+	})
+
+	suite.Run("When the card is over its limit it should return ResourceExhausted", func() {
+		var header metadata.MD
+		_, err := client.CreatePayment(ctx, cardPayment("2222405343248877", 100), grpc.Header(&header))
+		suite.Equal(codes.ResourceExhausted, status.Code(err))
+		suite.Equal([]string{"1800"}, header.Get("retry-after"))
+
+		_, err = client.CreatePayment(ctx, cardPayment("2222405343248112", 100))
+		suite.Require().NoError(err)
+	})
+}
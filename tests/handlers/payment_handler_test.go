@@ -3,40 +3,113 @@ package tests
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/docs"
 	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
 	"github.com/cko-recruitment/payment-gateway-challenge-go/handlers"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/middlewares"
 	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/banksim"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/bins"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/cards"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/clock"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ids"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/threeds"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/suite"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
+	"time"
 )
 
+// TEST_MERCHANT_ID is the merchant requests act for when a test does not
+// name one.
+const TEST_MERCHANT_ID = "default"
+
 type integrationTestSuite struct {
 	suite.Suite
 	ginEngine          *gin.Engine
 	paymentRouterGroup *gin.RouterGroup
 	baseUrl            string
 	testingServer      *httptest.Server
+	bankSimulator      *httptest.Server
+	fakeClock          *clock.Fake
 }
 
 func (suite *integrationTestSuite) SetupSuite() {
+	suite.fakeClock = clock.NewFake(time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC))
+	handlers.SetClock(suite.fakeClock)
+	suite.bankSimulator = banksim.NewServer(banksim.Config{})
+	os.Setenv("ACQUIRING_BANK_BASE_URL", suite.bankSimulator.URL)
+
+	spec, err := docs.OpenAPI3()
+	suite.Require().NoError(err)
+	specValidation, err := middlewares.OpenAPIValidation(spec, middlewares.OpenAPIValidationConfig{ValidateResponses: true})
+	suite.Require().NoError(err)
 	suite.ginEngine = gin.Default()
+	suite.ginEngine.Use(specValidation, asTestMerchant, middlewares.MerchantAuth(nil))
 	suite.paymentRouterGroup = suite.ginEngine.Group("api/v1/payments")
 	suite.paymentRouterGroup.POST("", handlers.CreatePayment)
+	suite.paymentRouterGroup.GET(":id", handlers.GetPaymentById)
+	suite.paymentRouterGroup.GET(":id/events", handlers.GetPaymentEvents)
+	suite.paymentRouterGroup.POST(":id/3ds/callback", handlers.CompleteThreeDSChallenge)
+	threeDSSimulator := threeds.NewSimulator("", suite.fakeClock)
+	handlers.SetDirectoryServer(threeDSSimulator)
+	suite.ginEngine.GET(threeds.CHALLENGE_PATH+":id", gin.WrapH(threeDSSimulator))
+	suite.ginEngine.POST(threeds.CHALLENGE_PATH+":id", gin.WrapH(threeDSSimulator))
+	suite.paymentRouterGroup.POST(":id/captures", handlers.CapturePayment)
+	suite.paymentRouterGroup.POST(":id/refunds", handlers.RefundPayment)
+	suite.paymentRouterGroup.GET(":id/stream", handlers.StreamPayment)
+	suite.ginEngine.GET("api/v1/events/stream", handlers.StreamMerchantEvents)
+	paymentV2Group := suite.ginEngine.Group("api/v2/payments")
+	paymentV2Group.POST("", handlers.CreatePaymentV2)
+	paymentV2Group.GET("", handlers.ListPaymentsV2)
+	paymentV2Group.GET(":id", handlers.GetPaymentV2)
+	paymentV2Group.POST(":id/captures", handlers.CapturePaymentV2)
+	paymentV2Group.POST(":id/refunds", handlers.RefundPaymentV2)
+	suite.ginEngine.GET("api/v1/reports", handlers.ListReports)
+	suite.ginEngine.GET("api/v1/reports/:id", handlers.GetReport)
+	suite.ginEngine.POST("api/v1/admin/reconciliations", handlers.ReconcileSettlement)
+	blocklistGroup := suite.ginEngine.Group("api/v1/admin/blocklist")
+	blocklistGroup.POST("", handlers.AddBlocklistEntry)
+	blocklistGroup.GET("", handlers.ListBlocklistEntries)
+	blocklistGroup.DELETE(":id", handlers.RemoveBlocklistEntry)
+	customerGroup := suite.ginEngine.Group("api/v1/customers")
+	customerGroup.POST("", handlers.CreateCustomer)
+	customerGroup.GET(":id", handlers.GetCustomer)
+	customerGroup.POST(":id/payment_methods", handlers.AttachCard)
+	customerGroup.GET(":id/payments", handlers.ListCustomerPayments)
+	mandateGroup := suite.ginEngine.Group("api/v1/mandates")
+	mandateGroup.GET(":id", handlers.GetMandate)
+	mandateGroup.DELETE(":id", handlers.RevokeMandate)
+	mandateGroup.POST(":id/payments", handlers.CreateMandatePayment)
+	subscriptionGroup := suite.ginEngine.Group("api/v1/subscriptions")
+	subscriptionGroup.POST("", handlers.CreateSubscription)
+	subscriptionGroup.GET(":id", handlers.GetSubscription)
+	subscriptionGroup.DELETE(":id", handlers.CancelSubscription)
 	suite.baseUrl = "http://localhost:8081"
 
-	suite.testingServer = httptest.NewServer(suite.ginEngine)
+	suite.testingServer = httptest.NewServer(middlewares.NegotiateVersion(suite.ginEngine, middlewares.VersionConfig{Default: "1", Supported: []string{"1", "2"}}))
 	suite.ginEngine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/v1/payments", nil))
 }
 
+// asTestMerchant stands in for the proxy that authenticates merchants in
+// front of the gateway, so that tests only name the merchant when it
+// matters.
+func asTestMerchant(context *gin.Context) {
+	if context.GetHeader(middlewares.MERCHANT_ID_HEADER) == "" {
+		context.Request.Header.Set(middlewares.MERCHANT_ID_HEADER, TEST_MERCHANT_ID)
+	}
+	context.Next()
+}
+
 func (suite *integrationTestSuite) TearDownSuite() {
 	suite.testingServer.Close()
+	suite.bankSimulator.Close()
+	handlers.SetClock(clock.Real{})
 
 }
 func (suite *integrationTestSuite) Test_CreatePaymentRejectedStatus() {
@@ -462,12 +535,142 @@ func (suite *integrationTestSuite) Test_CreatePaymentAuthorizedStatus() {
 
 }
 
-func TestIntegrationTestSuite(t *testing.T) {
-	err := godotenv.Load("../../.env")
-	if err != nil {
-		t.Fatalf("could not load .env file: %v", err)
+func (suite *integrationTestSuite) Test_CardFingerprint() {
+	suite.Run("Payments with the same card should have the same card fingerprint", func() {
+		body := req.CreatePaymentReqModel{
+			CardNumber:      "2222405343248877",
+			ExpirationMonth: 4,
+			ExpirationYear:  2025,
+			Currency:        "GBP",
+			Amount:          100,
+			CVV:             "123",
+		}
+		_, first := suite.sendJSON(http.MethodPost, "/api/v1/payments", body, nil)
+		_, second := suite.sendJSON(http.MethodPost, "/api/v1/payments", body, nil)
+		body.CardNumber = "2222405343248112"
+		body.ExpirationYear = 2026
+		_, other := suite.sendJSON(http.MethodPost, "/api/v1/payments", body, nil)
+
+		suite.Equal(cards.Fingerprint("2222405343248877"), first["card_fingerprint"])
+		suite.Equal(first["card_fingerprint"], second["card_fingerprint"])
+		suite.NotEqual(first["card_fingerprint"], other["card_fingerprint"])
+		suite.NotContains(first, "card_number")
+	})
+
+}
+
+func (suite *integrationTestSuite) Test_BinDetails() {
+	table, err := bins.LoadCSV("../../config/bins.example.csv")
+	suite.Require().NoError(err)
+	handlers.SetBinLookup(table)
+	defer handlers.SetBinLookup(nil)
+	body := req.CreatePaymentReqModel{
+		CardNumber:      "2222405343248877",
+		ExpirationMonth: 4,
+		ExpirationYear:  2025,
+		Currency:        "GBP",
+		Amount:          100,
+		CVV:             "123",
+	}
+
+	suite.Run("When the BIN is known the payment should carry its issuer", func() {
+		_, payment := suite.sendJSON(http.MethodPost, "/api/v1/payments", body, nil)
+		suite.Equal(map[string]interface{}{
+			"scheme":     "mastercard",
+			"issuer":     "Simulator Bank",
+			"country":    "GB",
+			"funding":    "debit",
+			"commercial": false,
+		}, payment["bin"])
+
+		_, fetched := suite.sendJSON(http.MethodGet, "/api/v2/payments/"+payment["id"].(string), nil, nil)
+		suite.Equal(payment["bin"], fetched["card"].(map[string]interface{})["bin"])
+	})
+
+	suite.Run("When the BIN is unknown the payment should have no issuer", func() {
+		body.CardNumber = "2223000048400011"
+		response, payment := suite.sendJSON(http.MethodPost, "/api/v1/payments", body, nil)
+		suite.Equal(http.StatusOK, response.StatusCode)
+		suite.Equal("0011", payment["last_four_card_digit"])
+		suite.NotContains(payment, "bin")
+	})
+
+}
+
+func (suite *integrationTestSuite) Test_InvalidPaymentIds() {
+	wrongPrefix, err := ids.New(ids.MANDATE)
+	suite.Require().NoError(err)
+	invalid := map[string]string{
+		"malformed":    "pay_not-a-uuid",
+		"wrong prefix": wrongPrefix,
 	}
-	fmt.Println("env loaded")
 
+	for name, ID := range invalid {
+		suite.Run("When getting a payment with a "+name+" id it should return 400", func() {
+			response, _ := suite.sendJSON(http.MethodGet, "/api/v1/payments/"+ID, nil, nil)
+			suite.Equal(http.StatusBadRequest, response.StatusCode)
+		})
+
+		suite.Run("When capturing a payment with a "+name+" id it should return 400", func() {
+			response, _ := suite.sendJSON(http.MethodPost, "/api/v1/payments/"+ID+"/captures", req.CapturePaymentReqModel{Amount: 100}, map[string]string{"If-Match": "*"})
+			suite.Equal(http.StatusBadRequest, response.StatusCode)
+		})
+
+		suite.Run("When refunding a payment with a "+name+" id it should return 400", func() {
+			response, _ := suite.sendJSON(http.MethodPost, "/api/v1/payments/"+ID+"/refunds", req.RefundPaymentReqModel{Amount: 100}, map[string]string{"If-Match": "*"})
+			suite.Equal(http.StatusBadRequest, response.StatusCode)
+		})
+	}
+
+}
+
+func (suite *integrationTestSuite) Test_OtherMerchantPayments() {
+	owner := map[string]string{"X-Merchant-Id": "merchant_owner"}
+	other := map[string]string{"X-Merchant-Id": "merchant_other", "If-Match": "*"}
+	response, created := suite.sendJSON(http.MethodPost, "/api/v1/payments", req.CreatePaymentReqModel{
+		CardNumber:      "2222405343248877",
+		ExpirationMonth: 4,
+		ExpirationYear:  2025,
+		Currency:        "GBP",
+		Amount:          1000,
+		CVV:             "123",
+	}, owner)
+	suite.Require().Equal(http.StatusOK, response.StatusCode)
+	ID := created["id"].(string)
+
+	suite.Run("When another merchant gets the payment it should return 404", func() {
+		response, _ := suite.sendJSON(http.MethodGet, "/api/v1/payments/"+ID, nil, other)
+		suite.Equal(http.StatusNotFound, response.StatusCode)
+	})
+
+	suite.Run("When another merchant lists the payment's events it should return 404", func() {
+		response, _ := suite.sendJSON(http.MethodGet, "/api/v1/payments/"+ID+"/events", nil, other)
+		suite.Equal(http.StatusNotFound, response.StatusCode)
+	})
+
+	suite.Run("When another merchant captures the payment it should return 404", func() {
+		response, _ := suite.sendJSON(http.MethodPost, "/api/v1/payments/"+ID+"/captures", req.CapturePaymentReqModel{Amount: 1000}, other)
+		suite.Equal(http.StatusNotFound, response.StatusCode)
+	})
+
+	suite.Run("When another merchant refunds the payment it should return 404", func() {
+		response, _ := suite.sendJSON(http.MethodPost, "/api/v1/payments/"+ID+"/refunds", req.RefundPaymentReqModel{Amount: 100}, other)
+		suite.Equal(http.StatusNotFound, response.StatusCode)
+	})
+
+	suite.Run("When another merchant gets the payment over v2 it should return 404", func() {
+		response, _ := suite.sendJSON(http.MethodGet, "/api/v2/payments/"+ID, nil, other)
+		suite.Equal(http.StatusNotFound, response.StatusCode)
+	})
+
+	suite.Run("The owner should still see the payment unchanged", func() {
+		response, payment := suite.sendJSON(http.MethodGet, "/api/v1/payments/"+ID, nil, owner)
+		suite.Equal(http.StatusOK, response.StatusCode)
+		suite.Equal(enums.AUTHORIZED, payment["status"])
+	})
+
+}
+
+func TestIntegrationTestSuite(t *testing.T) {
 	suite.Run(t, new(integrationTestSuite))
 }
@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/res"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/handlers"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/broadcast"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/etags"
+)
+
+type streamEvent struct {
+	Id     string
+	Event  string
+	Update res.PaymentStatusUpdate
+}
+
+type eventStream struct {
+	response *http.Response
+	reader   *bufio.Reader
+	cancel   context.CancelFunc
+}
+
+func (suite *integrationTestSuite) openStream(path string, headers map[string]string) *eventStream {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, suite.testingServer.URL+path, nil)
+	suite.Require().NoError(err)
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+	response, err := http.DefaultClient.Do(request)
+	suite.Require().NoError(err)
+	return &eventStream{response: response, reader: bufio.NewReader(response.Body), cancel: cancel}
+}
+
+func (stream *eventStream) Close() {
+	stream.cancel()
+	stream.response.Body.Close()
+}
+
+// next returns the next event, skipping comments and retry hints, or false
+// once the stream has ended.
+func (suite *integrationTestSuite) next(stream *eventStream) (streamEvent, bool) {
+	var event streamEvent
+	for {
+		line, err := stream.reader.ReadString('\n')
+		if err != nil {
+			return streamEvent{}, false
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "" && event.Event != "":
+			return event, true
+		case strings.HasPrefix(line, "id:"):
+			event.Id = strings.TrimPrefix(line, "id:")
+		case strings.HasPrefix(line, "event:"):
+			event.Event = strings.TrimPrefix(line, "event:")
+		case strings.HasPrefix(line, "data:") && event.Event == handlers.PAYMENT_STATUS_EVENT:
+			suite.NoError(json.Unmarshal([]byte(strings.TrimPrefix(line, "data:")), &event.Update))
+		}
+	}
+}
+
+func (suite *integrationTestSuite) Test_PaymentStreams() {
+	handlers.SetPaymentStreams(broadcast.NewBroker(handlers.DEFAULT_STREAM_BUFFER, 0))
+	defer handlers.SetPaymentStreams(broadcast.NewBroker(handlers.DEFAULT_STREAM_BUFFER, 0))
+
+	response, created := suite.sendJSON(http.MethodPost, "/api/v1/payments", req.CreatePaymentReqModel{
+		CardNumber:      "2222405343248877",
+		ExpirationMonth: 4,
+		ExpirationYear:  2025,
+		Currency:        "GBP",
+		Amount:          1000,
+		CVV:             "123",
+	}, map[string]string{"X-Merchant-Id": "merchant_stream"})
+	suite.Require().Equal(http.StatusOK, response.StatusCode)
+	ID := created["id"].(string)
+
+	var authorizedId string
+	suite.Run("When following a payment it should replay its buffered transitions", func() {
+		stream := suite.openStream("/api/v1/payments/"+ID+"/stream", map[string]string{"X-Merchant-Id": "merchant_stream"})
+		defer stream.Close()
+		suite.Equal(http.StatusOK, stream.response.StatusCode)
+		suite.Equal("text/event-stream", stream.response.Header.Get("Content-Type"))
+
+		pending, ok := suite.next(stream)
+		suite.Require().True(ok)
+		suite.Equal(enums.PENDING, pending.Update.To)
+		authorized, ok := suite.next(stream)
+		suite.Require().True(ok)
+		suite.Equal(handlers.PAYMENT_STATUS_EVENT, authorized.Event)
+		suite.Equal(ID, authorized.Update.PaymentId)
+		suite.Equal(enums.PENDING, authorized.Update.From)
+		suite.Equal(enums.AUTHORIZED, authorized.Update.To)
+		authorizedId = authorized.Id
+	})
+
+	suite.Run("When the payment changes it should push the transition", func() {
+		stream := suite.openStream("/api/v1/payments/"+ID+"/stream", map[string]string{handlers.LAST_EVENT_ID_HEADER: authorizedId, "X-Merchant-Id": "merchant_stream"})
+		defer stream.Close()
+
+		response, _ := suite.sendJSON(http.MethodPost, "/api/v1/payments/"+ID+"/captures", req.CapturePaymentReqModel{Amount: 1000}, map[string]string{"X-Merchant-Id": "merchant_stream", "If-Match": "*"})
+		suite.Equal(http.StatusOK, response.StatusCode)
+
+		captured, ok := suite.next(stream)
+		suite.Require().True(ok)
+		suite.Equal(enums.AUTHORIZED, captured.Update.From)
+		suite.Equal(enums.CAPTURED, captured.Update.To)
+		suite.Equal(etags.Strong("v1", captured.Update.Version), response.Header.Get("ETag"))
+	})
+
+	suite.Run("When another merchant follows the payment it should return 404", func() {
+		stream := suite.openStream("/api/v1/payments/"+ID+"/stream", map[string]string{"X-Merchant-Id": "merchant_other"})
+		defer stream.Close()
+		suite.Equal(http.StatusNotFound, stream.response.StatusCode)
+
+		stream = suite.openStream("/api/v1/payments/"+ID+"/stream", nil)
+		defer stream.Close()
+		suite.Equal(http.StatusNotFound, stream.response.StatusCode)
+	})
+
+	suite.Run("When following the merchant it should only push its payments from now on", func() {
+		stream := suite.openStream("/api/v1/events/stream", map[string]string{"X-Merchant-Id": "merchant_stream"})
+		defer stream.Close()
+
+		response, _ := suite.sendJSON(http.MethodPost, "/api/v1/payments", req.CreatePaymentReqModel{
+			CardNumber:      "2222405343248877",
+			ExpirationMonth: 4,
+			ExpirationYear:  2025,
+			Currency:        "GBP",
+			Amount:          500,
+			CVV:             "123",
+		}, nil)
+		suite.Require().Equal(http.StatusOK, response.StatusCode)
+		response, other := suite.sendJSON(http.MethodPost, "/api/v1/payments", req.CreatePaymentReqModel{
+			CardNumber:      "2222405343248877",
+			ExpirationMonth: 4,
+			ExpirationYear:  2025,
+			Currency:        "GBP",
+			Amount:          500,
+			CVV:             "123",
+		}, map[string]string{"X-Merchant-Id": "merchant_stream"})
+		suite.Require().Equal(http.StatusOK, response.StatusCode)
+
+		event, ok := suite.next(stream)
+		suite.Require().True(ok)
+		suite.Equal(other["id"], event.Update.PaymentId)
+		suite.Equal(enums.PENDING, event.Update.To)
+	})
+
+	suite.Run("When Last-Event-ID is not a number it should return 400", func() {
+		stream := suite.openStream("/api/v1/events/stream", map[string]string{handlers.LAST_EVENT_ID_HEADER: "abc"})
+		defer stream.Close()
+		suite.Equal(http.StatusBadRequest, stream.response.StatusCode)
+	})
+
+	suite.Run("When resuming from before the buffer it should send a reset", func() {
+		handlers.SetPaymentStreams(broadcast.NewBroker(handlers.DEFAULT_STREAM_BUFFER, 1000))
+		stream := suite.openStream("/api/v1/events/stream", map[string]string{handlers.LAST_EVENT_ID_HEADER: "1"})
+		defer stream.Close()
+
+		event, ok := suite.next(stream)
+		suite.Require().True(ok)
+		suite.Equal(handlers.STREAM_RESET_EVENT, event.Event)
+	})
+
+	suite.Run("When the streams are closed on shutdown it should end the stream", func() {
+		broker := broadcast.NewBroker(handlers.DEFAULT_STREAM_BUFFER, 0)
+		handlers.SetPaymentStreams(broker)
+		stream := suite.openStream("/api/v1/events/stream", nil)
+		defer stream.Close()
+
+		broker.Close()
+		_, ok := suite.next(stream)
+		suite.False(ok)
+	})
+}
@@ -0,0 +1,192 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/middlewares"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/cards"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ids"
+)
+
+func (suite *integrationTestSuite) createPaymentV2(cardNumber string) map[string]interface{} {
+	statusCode, payment := suite.postJSON("/api/v2/payments", req.CreatePaymentV2ReqModel{
+		Card: &req.CardV2ReqModel{
+			Number:      cardNumber,
+			ExpiryMonth: 4,
+			ExpiryYear:  2025,
+		},
+		Amount: req.MoneyV2ReqModel{Value: 1000, Currency: "GBP"},
+		CVV:    "123",
+	})
+	suite.Equal(http.StatusOK, statusCode)
+	return payment
+}
+
+func (suite *integrationTestSuite) Test_PaymentsV2() {
+	suite.Run("When the bank authorizes it should return nested card and amount objects", func() {
+		payment := suite.createPaymentV2("2222405343248877")
+		suite.Equal(enums.AUTHORIZED, payment["status"])
+		suite.Equal(map[string]interface{}{"last4": "8877", "fingerprint": cards.Fingerprint("2222405343248877"), "expiry_month": float64(4), "expiry_year": float64(2025)}, payment["card"])
+		suite.Equal(map[string]interface{}{"value": float64(1000), "currency": "GBP"}, payment["amount"])
+		suite.Equal(map[string]interface{}{"value": float64(0), "currency": "GBP"}, payment["captured"])
+		suite.NotContains(payment, "last_four_card_digit")
+		suite.NotContains(payment, "currency_code")
+	})
+
+	suite.Run("When the bank declines it should return the declined status", func() {
+		payment := suite.createPaymentV2("2222405343248112")
+		suite.Equal(enums.DECLIEND, payment["status"])
+	})
+
+	suite.Run("When the card is missing it should return 400", func() {
+		statusCode, _ := suite.postJSON("/api/v2/payments", req.CreatePaymentV2ReqModel{
+			Amount: req.MoneyV2ReqModel{Value: 1000, Currency: "GBP"},
+			CVV:    "123",
+		})
+		suite.Equal(http.StatusBadRequest, statusCode)
+	})
+
+	suite.Run("When paying with a saved card it should use its details", func() {
+		customerId, methodId := suite.createCustomerWithCard("2222405343248877")
+		statusCode, payment := suite.postJSON("/api/v2/payments", req.CreatePaymentV2ReqModel{
+			CustomerId:      customerId,
+			PaymentMethodId: methodId,
+			Amount:          req.MoneyV2ReqModel{Value: 250, Currency: "GBP"},
+			CVV:             "123",
+		})
+		suite.Equal(http.StatusOK, statusCode)
+		suite.Equal(enums.AUTHORIZED, payment["status"])
+		suite.Equal("8877", payment["card"].(map[string]interface{})["last4"])
+	})
+
+	suite.Run("When a v1 payment is fetched through v2 it should use the v2 shape", func() {
+		statusCode, created := suite.postJSON("/api/v1/payments", req.CreatePaymentReqModel{
+			CardNumber:      "2222405343248877",
+			ExpirationMonth: 4,
+			ExpirationYear:  2025,
+			Currency:        "GBP",
+			Amount:          100,
+			CVV:             "123",
+		})
+		suite.Equal(http.StatusOK, statusCode)
+
+		statusCode, payment := suite.getJSON("/api/v2/payments/" + created["id"].(string))
+		suite.Equal(http.StatusOK, statusCode)
+		suite.Equal(map[string]interface{}{"value": float64(100), "currency": "GBP"}, payment["amount"])
+	})
+
+	suite.Run("When capturing and refunding it should report amounts in the payment currency", func() {
+		ID := suite.createPaymentV2("2222405343248877")["id"].(string)
+
+		response, payment := suite.sendJSON(http.MethodPost, "/api/v2/payments/"+ID+"/captures", req.CapturePaymentV2ReqModel{
+			Amount: req.MoneyV2ReqModel{Value: 1000, Currency: "GBP"},
+		}, map[string]string{"If-Match": "*"})
+		suite.Equal(http.StatusOK, response.StatusCode)
+		suite.Equal(map[string]interface{}{"value": float64(1000), "currency": "GBP"}, payment["captured"])
+
+		response, payment = suite.sendJSON(http.MethodPost, "/api/v2/payments/"+ID+"/refunds", req.RefundPaymentV2ReqModel{
+			Amount: req.MoneyV2ReqModel{Value: 400, Currency: "GBP"},
+		}, map[string]string{"If-Match": response.Header.Get("ETag")})
+		suite.Equal(http.StatusOK, response.StatusCode)
+		suite.Equal(map[string]interface{}{"value": float64(400), "currency": "GBP"}, payment["refunded"])
+		refunds := payment["refunds"].([]interface{})
+		suite.Len(refunds, 1)
+		suite.Equal(map[string]interface{}{"value": float64(400), "currency": "GBP"}, refunds[0].(map[string]interface{})["amount"])
+	})
+
+	suite.Run("When the capture currency differs from the payment it should return 400", func() {
+		ID := suite.createPaymentV2("2222405343248877")["id"].(string)
+
+		response, _ := suite.sendJSON(http.MethodPost, "/api/v2/payments/"+ID+"/captures", req.CapturePaymentV2ReqModel{
+			Amount: req.MoneyV2ReqModel{Value: 1000, Currency: "EUR"},
+		}, map[string]string{"If-Match": "*"})
+		suite.Equal(http.StatusBadRequest, response.StatusCode)
+
+		_, payment := suite.getJSON("/api/v2/payments/" + ID)
+		suite.Equal(enums.AUTHORIZED, payment["status"])
+	})
+
+	suite.Run("When capturing an unknown payment it should return 404", func() {
+		ID, err := ids.NewPaymentId()
+		suite.NoError(err)
+		response, _ := suite.sendJSON(http.MethodPost, "/api/v2/payments/"+ID+"/captures", req.CapturePaymentV2ReqModel{
+			Amount: req.MoneyV2ReqModel{Value: 1000, Currency: "GBP"},
+		}, map[string]string{"If-Match": "*"})
+		suite.Equal(http.StatusNotFound, response.StatusCode)
+	})
+
+	suite.Run("When listing payments it should page through them in the v2 shape", func() {
+		suite.createPaymentV2("2222405343248877")
+
+		response, err := http.Get(suite.testingServer.URL + "/api/v2/payments?limit=1")
+		suite.NoError(err)
+		defer response.Body.Close()
+		var body api_response.ResponseWithPagination
+		suite.NoError(json.NewDecoder(response.Body).Decode(&body))
+		suite.Equal(http.StatusOK, response.StatusCode)
+		payments := body.Data.([]interface{})
+		suite.Len(payments, 1)
+		suite.Contains(payments[0], "card")
+	})
+
+	suite.Run("When the page is past the last one it should return no payments", func() {
+		response, err := http.Get(suite.testingServer.URL + "/api/v2/payments?page=100000000000000001&limit=100")
+		suite.NoError(err)
+		defer response.Body.Close()
+		var body api_response.ResponseWithPagination
+		suite.NoError(json.NewDecoder(response.Body).Decode(&body))
+		suite.Equal(http.StatusOK, response.StatusCode)
+		suite.Empty(body.Data)
+	})
+}
+
+func (suite *integrationTestSuite) Test_VersionNegotiation() {
+	get := func(path string, version string) (*http.Response, map[string]interface{}) {
+		request, err := http.NewRequest(http.MethodGet, suite.testingServer.URL+path, nil)
+		suite.NoError(err)
+		if version != "" {
+			request.Header.Set(middlewares.API_VERSION_HEADER, version)
+		}
+		response, err := http.DefaultClient.Do(request)
+		suite.NoError(err)
+		defer response.Body.Close()
+		var apiBody api_response.Response
+		json.NewDecoder(response.Body).Decode(&apiBody)
+		data, _ := apiBody.Data.(map[string]interface{})
+		return response, data
+	}
+	ID := suite.createPaymentV2("2222405343248877")["id"].(string)
+
+	suite.Run("When no version is asked for it should serve v1", func() {
+		response, payment := get("/api/payments/"+ID, "")
+		suite.Equal(http.StatusOK, response.StatusCode)
+		suite.Equal("1", response.Header.Get(middlewares.API_VERSION_HEADER))
+		suite.Equal("8877", payment["last_four_card_digit"])
+	})
+
+	suite.Run("When version 2 is asked for it should serve v2", func() {
+		response, payment := get("/api/payments/"+ID, "2")
+		suite.Equal(http.StatusOK, response.StatusCode)
+		suite.Equal("2", response.Header.Get(middlewares.API_VERSION_HEADER))
+		suite.Equal("8877", payment["card"].(map[string]interface{})["last4"])
+	})
+
+	suite.Run("When the path names a version it should ignore the header", func() {
+		_, payment := get("/api/v1/payments/"+ID, "2")
+		suite.Equal("8877", payment["last_four_card_digit"])
+	})
+
+	suite.Run("When an unsupported version is asked for it should return 400", func() {
+		response, _ := get("/api/payments/"+ID, "3")
+		suite.Equal(http.StatusBadRequest, response.StatusCode)
+	})
+
+	suite.Run("When the version has no such route it should return 404", func() {
+		response, _ := get("/api/customers", "2")
+		suite.Equal(http.StatusNotFound, response.StatusCode)
+	})
+}
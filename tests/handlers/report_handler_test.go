@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/handlers"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/middlewares"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/reports"
+)
+
+func (suite *integrationTestSuite) Test_Reports() {
+	store := reports.NewStore("")
+	handlers.SetReports(store, time.UTC)
+	defer handlers.SetReports(reports.NewStore(""), time.UTC)
+	report := reports.Report{
+		Id:         "rpt_0190b5a1c2d37e4f8a9b0c1d2e3f4a5b",
+		MerchantId: TEST_MERCHANT_ID,
+		Date:       "2024-06-14",
+		Rows:       []reports.Row{{Currency: "GBP", Gross: 10000, Fees: 105, Refunds: 2500, Net: 7395, Captures: 1, RefundCount: 1}},
+	}
+	suite.NoError(store.Save(report))
+
+	suite.Run("When the report is requested as JSON it should return its totals", func() {
+		statusCode, body := suite.getJSON("/api/v1/reports/" + report.Id)
+		suite.Equal(http.StatusOK, statusCode)
+		suite.Equal("2024-06-14", body["date"])
+		row := body["rows"].([]interface{})[0].(map[string]interface{})
+		suite.Equal(float64(7395), row["net"])
+	})
+
+	suite.Run("When the report is requested as CSV it should download the file", func() {
+		response, err := http.Get(suite.testingServer.URL + "/api/v1/reports/" + report.Id + "?format=csv")
+		suite.NoError(err)
+		defer response.Body.Close()
+		suite.Equal(http.StatusOK, response.StatusCode)
+		suite.Equal("text/csv", response.Header.Get("Content-Type"))
+		content, _ := io.ReadAll(response.Body)
+		suite.Equal("date,merchant_id,currency,gross,fees,refunds,net,captures,refund_count\n2024-06-14,default,GBP,10000,105,2500,7395,1,1\n", string(content))
+	})
+
+	suite.Run("When the report belongs to another merchant it should return 404", func() {
+		request, _ := http.NewRequest(http.MethodGet, suite.testingServer.URL+"/api/v1/reports/"+report.Id, nil)
+		request.Header.Set(middlewares.MERCHANT_ID_HEADER, "merchant_b")
+		response, err := http.DefaultClient.Do(request)
+		suite.NoError(err)
+		suite.Equal(http.StatusNotFound, response.StatusCode)
+	})
+}
+
+func (suite *integrationTestSuite) Test_Reconciliation() {
+	statusCode, payment := suite.postJSON("/api/v1/payments", req.CreatePaymentReqModel{
+		CardNumber:      "2222405343248877",
+		ExpirationMonth: 4,
+		ExpirationYear:  2026,
+		Currency:        "GBP",
+		Amount:          1000,
+		CVV:             "123",
+	})
+	suite.Equal(http.StatusOK, statusCode)
+	paymentId := payment["id"].(string)
+	response, payment := suite.sendJSON(http.MethodPost, "/api/v1/payments/"+paymentId+"/captures", req.CapturePaymentReqModel{Amount: 1000}, map[string]string{"If-Match": "*"})
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Equal(enums.CAPTURED, payment["status"])
+
+	reconcile := func(file string) (int, map[string]interface{}) {
+		response, err := http.Post(suite.testingServer.URL+"/api/v1/admin/reconciliations?date=2024-06-15", "text/csv", strings.NewReader(file))
+		suite.NoError(err)
+		defer response.Body.Close()
+		var apiBody api_response.Response
+		suite.NoError(json.NewDecoder(response.Body).Decode(&apiBody))
+		data, _ := apiBody.Data.(map[string]interface{})
+		return response.StatusCode, data
+	}
+	discrepancyCodes := func(body map[string]interface{}) []string {
+		codes := make([]string, 0)
+		for _, discrepancy := range body["discrepancies"].([]interface{}) {
+			if discrepancy.(map[string]interface{})["payment_id"] == paymentId {
+				codes = append(codes, discrepancy.(map[string]interface{})["code"].(string))
+			}
+		}
+		return codes
+	}
+
+	suite.Run("When the acquirer settled the captured amount it should match", func() {
+		statusCode, body := reconcile("payment_id,type,currency,amount\n" + paymentId + ",capture,GBP,1000\n")
+		suite.Equal(http.StatusOK, statusCode)
+		suite.Equal(float64(1), body["matched"])
+		suite.Empty(discrepancyCodes(body))
+	})
+
+	suite.Run("When the amounts disagree it should flag the payment", func() {
+		_, body := reconcile("payment_id,type,currency,amount\n" + paymentId + ",capture,GBP,900\n")
+		suite.Equal([]string{reports.CAPTURE_AMOUNT_MISMATCH}, discrepancyCodes(body))
+	})
+
+	suite.Run("When the payment is missing from the file it should flag it", func() {
+		_, body := reconcile("payment_id,type,currency,amount\n")
+		suite.Equal([]string{reports.MISSING_FROM_FILE}, discrepancyCodes(body))
+	})
+
+	suite.Run("When the file is malformed it should return 400", func() {
+		statusCode, _ := reconcile("not,a,settlement,file\n")
+		suite.Equal(http.StatusBadRequest, statusCode)
+	})
+}
@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/req"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/api_response"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+func (suite *integrationTestSuite) postJSON(path string, body interface{}) (int, map[string]interface{}) {
+	requestBody, err := json.Marshal(body)
+	suite.NoError(err, "no error when marshalling the request")
+
+	response, err := http.Post(suite.testingServer.URL+path, "application/json", bytes.NewBuffer(requestBody))
+	suite.NoError(err, "no error when calling the endpoint")
+
+	bodyBytes, _ := io.ReadAll(response.Body)
+	var apiBody api_response.Response
+	suite.NoError(json.Unmarshal(bodyBytes, &apiBody), "no error when calling json decode")
+
+	data, _ := apiBody.Data.(map[string]interface{})
+	return response.StatusCode, data
+}
+
+func (suite *integrationTestSuite) createThreeDSPayment() (string, string) {
+	statusCode, payment := suite.postJSON("/api/v1/payments", req.CreatePaymentReqModel{
+		CardNumber:      "2222405343248877",
+		ExpirationMonth: 4,
+		ExpirationYear:  2025,
+		Currency:        "EUR",
+		Amount:          100,
+		CVV:             "123",
+		ThreeDS:         true,
+	})
+	suite.Equal(http.StatusOK, statusCode)
+	suite.Equal(enums.REQUIRES_ACTION, payment["status"])
+
+	threeDS := payment["three_ds"].(map[string]interface{})
+	challengeURL := threeDS["challenge_url"].(string)
+	suite.NotEmpty(challengeURL)
+	challengeId := challengeURL[len("/3ds/challenges/"):]
+	return payment["id"].(string), challengeId
+}
+
+// answerChallenge posts the cardholder's answer from the simulator's
+// challenge page and returns the page that notifies the gateway.
+func (suite *integrationTestSuite) answerChallenge(challengeId string, response string) string {
+	answered, err := http.PostForm(suite.testingServer.URL+"/3ds/challenges/"+challengeId, url.Values{"response": {response}})
+	suite.Require().NoError(err)
+	defer answered.Body.Close()
+	suite.Require().Equal(http.StatusOK, answered.StatusCode)
+	page, err := io.ReadAll(answered.Body)
+	suite.Require().NoError(err)
+	return string(page)
+}
+
+func (suite *integrationTestSuite) Test_CreatePaymentThreeDS() {
+	suite.Run("When the cardholder authenticates it should authorize with liability shift", func() {
+		paymentId, challengeId := suite.createThreeDSPayment()
+		notification := suite.answerChallenge(challengeId, "Y")
+		suite.Contains(notification, `action="/api/v1/payments/`+paymentId+`/3ds/callback"`)
+		suite.Contains(notification, `value="`+challengeId+`"`)
+
+		statusCode, payment := suite.postJSON("/api/v1/payments/"+paymentId+"/3ds/callback", req.ThreeDSCallbackReqModel{
+			ChallengeId: challengeId,
+		})
+		suite.Equal(http.StatusOK, statusCode)
+		suite.Equal(enums.AUTHORIZED, payment["status"])
+		threeDS := payment["three_ds"].(map[string]interface{})
+		suite.Equal(true, threeDS["liability_shift"])
+		suite.Equal("05", threeDS["eci"])
+	})
+
+	suite.Run("When the cardholder fails the challenge it should decline without calling the bank", func() {
+		paymentId, challengeId := suite.createThreeDSPayment()
+		suite.answerChallenge(challengeId, "N")
+
+		statusCode, payment := suite.postJSON("/api/v1/payments/"+paymentId+"/3ds/callback", req.ThreeDSCallbackReqModel{
+			ChallengeId: challengeId,
+		})
+		suite.Equal(http.StatusOK, statusCode)
+		suite.Equal(enums.DECLIEND, payment["status"])
+		suite.Equal("", payment["acquirer"])
+	})
+
+	suite.Run("When the challenge was already completed it should return 409", func() {
+		paymentId, challengeId := suite.createThreeDSPayment()
+		suite.answerChallenge(challengeId, "Y")
+		callback := req.ThreeDSCallbackReqModel{ChallengeId: challengeId}
+
+		statusCode, _ := suite.postJSON("/api/v1/payments/"+paymentId+"/3ds/callback", callback)
+		suite.Equal(http.StatusOK, statusCode)
+		statusCode, _ = suite.postJSON("/api/v1/payments/"+paymentId+"/3ds/callback", callback)
+		suite.Equal(http.StatusConflict, statusCode)
+	})
+
+	suite.Run("When the cardholder has not answered it should return 409", func() {
+		paymentId, challengeId := suite.createThreeDSPayment()
+
+		statusCode, _ := suite.postJSON("/api/v1/payments/"+paymentId+"/3ds/callback", req.ThreeDSCallbackReqModel{ChallengeId: challengeId})
+		suite.Equal(http.StatusConflict, statusCode)
+	})
+
+	suite.Run("When the callback claims another outcome it should use the directory server's", func() {
+		paymentId, challengeId := suite.createThreeDSPayment()
+		suite.answerChallenge(challengeId, "N")
+
+		statusCode, payment := suite.postJSON("/api/v1/payments/"+paymentId+"/3ds/callback", map[string]string{
+			"challenge_id": challengeId,
+			"response":     "Y",
+		})
+		suite.Equal(http.StatusOK, statusCode)
+		suite.Equal(enums.DECLIEND, payment["status"])
+		suite.Equal(false, payment["three_ds"].(map[string]interface{})["liability_shift"])
+	})
+}
@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ids"
+	"github.com/stretchr/testify/suite"
+)
+
+type idsTestSuite struct {
+	suite.Suite
+}
+
+func (suite *idsTestSuite) Test_New() {
+	suite.Run("It should prefix the id with the resource type", func() {
+		id, err := ids.New(ids.REFUND)
+		suite.NoError(err)
+		suite.True(strings.HasPrefix(id, "ref_"))
+		suite.NoError(ids.Validate(ids.REFUND, id))
+	})
+
+	suite.Run("Ids generated later should sort after earlier ones", func() {
+		generated := make([]string, 0, 100)
+		for i := 0; i < 100; i++ {
+			id, err := ids.NewPaymentId()
+			suite.NoError(err)
+			generated = append(generated, id)
+		}
+		suite.True(sort.StringsAreSorted(generated))
+	})
+}
+
+func (suite *idsTestSuite) Test_Validate() {
+	paymentId, err := ids.NewPaymentId()
+	suite.NoError(err)
+
+	suite.Run("When the prefix does not match it should return an error", func() {
+		suite.ErrorIs(ids.Validate(ids.TOKEN, paymentId), ids.ErrInvalidId)
+	})
+
+	suite.Run("When the body is not a uuid it should return an error", func() {
+		suite.ErrorIs(ids.Validate(ids.PAYMENT, "pay_not-a-valid-id"), ids.ErrInvalidId)
+	})
+
+	suite.Run("When the body is a random uuid it should return an error", func() {
+		suite.ErrorIs(ids.Validate(ids.PAYMENT, "pay_0bb074056d444b50a14f7ae0beff13ad"), ids.ErrInvalidId)
+	})
+}
+
+func TestIdsTestSuite(t *testing.T) {
+	suite.Run(t, new(idsTestSuite))
+}
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/ledger"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/eventlog"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/store"
+	"github.com/stretchr/testify/suite"
+)
+
+type ledgerTestSuite struct {
+	suite.Suite
+	merchantLedger *ledger.Ledger
+	paymentStore   *store.PaymentStore
+}
+
+func (suite *ledgerTestSuite) SetupTest() {
+	suite.merchantLedger = ledger.New(ledger.FeeSchedule{BasisPoints: 100, Fixed: 5})
+	suite.paymentStore = store.NewPaymentStore(eventlog.NewMemoryLog(), store.Options{})
+	suite.paymentStore.Subscribe(func(event eventlog.Event) {
+		suite.NoError(suite.merchantLedger.Apply(event))
+	})
+}
+
+func (suite *ledgerTestSuite) append(paymentId string, eventType string, data interface{}) {
+	event, err := store.NewEvent(paymentId, eventType, enums.ACTOR_GATEWAY, eventType, time.Now(), data)
+	suite.NoError(err)
+	_, err = suite.paymentStore.Append(paymentId, event)
+	suite.NoError(err)
+}
+
+func (suite *ledgerTestSuite) authorize(paymentId string, merchantId string, currency string, amount int) {
+	suite.append(paymentId, store.PAYMENT_REQUESTED, store.PaymentRequestedData{
+		MerchantId:      merchantId,
+		CardToken:       "crd_1",
+		CardLast4:       "8877",
+		ExpirationMonth: 4,
+		ExpirationYear:  2030,
+		CurrencyCode:    currency,
+		Amount:          amount,
+	})
+	suite.append(paymentId, store.BANK_AUTHORIZED, nil)
+}
+
+func (suite *ledgerTestSuite) assertInvariants() {
+	totals := make(map[string]int)
+	for _, entry := range suite.merchantLedger.Entries() {
+		sums := make(map[string]int)
+		for _, posting := range entry.Postings {
+			sums[posting.Currency] += posting.Amount
+			totals[posting.Currency] += posting.Amount
+		}
+		for currency, sum := range sums {
+			suite.Equalf(0, sum, "entry %s of type %s does not balance in %s", entry.Id, entry.Type, currency)
+		}
+	}
+	for currency, total := range totals {
+		suite.Equalf(0, total, "trial balance in %s is not zero", currency)
+	}
+}
+
+func (suite *ledgerTestSuite) Test_Balances() {
+	suite.authorize("pay_1", "merchant_a", "GBP", 10000)
+	suite.authorize("pay_2", "merchant_a", "GBP", 500)
+	suite.authorize("pay_3", "merchant_a", "USD", 2000)
+	suite.authorize("pay_4", "merchant_b", "GBP", 700)
+
+	suite.Run("Authorizations should be pending", func() {
+		suite.Equal([]ledger.Balance{
+			{Currency: "GBP", Available: 0, Pending: 10500},
+			{Currency: "USD", Available: 0, Pending: 2000},
+		}, suite.merchantLedger.Balances("merchant_a"))
+		suite.assertInvariants()
+	})
+
+	suite.Run("Captures should become available net of fees", func() {
+		suite.append("pay_1", store.CAPTURED, store.CapturedData{Amount: 8000})
+		suite.Equal([]ledger.Balance{
+			{Currency: "GBP", Available: 8000 - 85, Pending: 500},
+			{Currency: "USD", Available: 0, Pending: 2000},
+		}, suite.merchantLedger.Balances("merchant_a"))
+		suite.Equal(-85, suite.merchantLedger.AccountBalance(ledger.GATEWAY_FEES, "GBP"))
+		suite.assertInvariants()
+	})
+
+	suite.Run("Refunds should reduce the available balance", func() {
+		suite.append("pay_1", store.REFUNDED, store.RefundedData{RefundId: "ref_1", Amount: 3000})
+		suite.Equal(8000-85-3000, suite.merchantLedger.Balances("merchant_a")[0].Available)
+		suite.Equal(5000, suite.merchantLedger.AccountBalance(ledger.ACQUIRER_RECEIVABLE, "GBP"))
+		suite.assertInvariants()
+	})
+
+	suite.Run("Merchants should not see each other's balances", func() {
+		suite.Equal([]ledger.Balance{
+			{Currency: "GBP", Available: 0, Pending: 700},
+		}, suite.merchantLedger.Balances("merchant_b"))
+	})
+}
+
+func (suite *ledgerTestSuite) Test_SettlementCurrency() {
+	suite.append("pay_1", store.PAYMENT_REQUESTED, store.PaymentRequestedData{
+		MerchantId:      "merchant_a",
+		CardToken:       "crd_1",
+		CardLast4:       "8877",
+		ExpirationMonth: 4,
+		ExpirationYear:  2030,
+		CurrencyCode:    "USD",
+		Amount:          10000,
+	})
+	suite.append("pay_1", store.FX_CONVERTED, store.FXConvertedData{SettlementCurrency: "GBP", SettlementAmount: 7874, Rate: 0.7874})
+	suite.append("pay_1", store.BANK_AUTHORIZED, nil)
+
+	suite.Equal([]ledger.Balance{
+		{Currency: "GBP", Available: 0, Pending: 7874},
+	}, suite.merchantLedger.Balances("merchant_a"))
+
+	suite.append("pay_1", store.CAPTURED, store.CapturedData{Amount: 5000})
+	suite.append("pay_1", store.REFUNDED, store.RefundedData{RefundId: "ref_1", Amount: 1000})
+	suite.Equal([]ledger.Balance{
+		{Currency: "GBP", Available: 3937 - 44 - 787, Pending: 0},
+	}, suite.merchantLedger.Balances("merchant_a"))
+	suite.assertInvariants()
+}
+
+func (suite *ledgerTestSuite) Test_Post() {
+	suite.Run("When postings do not sum to zero it should reject the entry", func() {
+		err := suite.merchantLedger.Post(ledger.JournalEntry{
+			Type: ledger.ENTRY_FEE,
+			Postings: []ledger.Posting{
+				{Account: ledger.GATEWAY_FEES, Currency: "GBP", Amount: -10},
+				{Account: ledger.MerchantAvailableAccount("merchant_a"), Currency: "GBP", Amount: 9},
+			},
+		})
+		suite.ErrorIs(err, ledger.ErrUnbalancedEntry)
+		suite.Empty(suite.merchantLedger.Entries())
+	})
+
+	suite.Run("When postings balance across different currencies it should reject the entry", func() {
+		err := suite.merchantLedger.Post(ledger.JournalEntry{
+			Type: ledger.ENTRY_FEE,
+			Postings: []ledger.Posting{
+				{Account: ledger.GATEWAY_FEES, Currency: "GBP", Amount: -10},
+				{Account: ledger.MerchantAvailableAccount("merchant_a"), Currency: "USD", Amount: 10},
+			},
+		})
+		suite.ErrorIs(err, ledger.ErrUnbalancedEntry)
+	})
+}
+
+func TestLedgerTestSuite(t *testing.T) {
+	suite.Run(t, new(ledgerTestSuite))
+}
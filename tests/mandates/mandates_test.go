@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/eventlog"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/mandates"
+	"github.com/stretchr/testify/suite"
+)
+
+type mandatesTestSuite struct {
+	suite.Suite
+	now time.Time
+}
+
+func (suite *mandatesTestSuite) SetupTest() {
+	suite.now = time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC)
+}
+
+func (suite *mandatesTestSuite) Test_Persistence() {
+	path := filepath.Join(suite.T().TempDir(), "mandates.log")
+	fileLog, err := eventlog.OpenFileLog(path)
+	suite.Require().NoError(err)
+	store, err := mandates.Open(fileLog)
+	suite.Require().NoError(err)
+	active, err := store.Create(mandates.Mandate{MerchantId: "merchant_a", CardToken: "crd_1", CardLast4: "8877", ExpirationMonth: 4, ExpirationYear: 2030, InitialPaymentId: "pay_1", CreatedAt: suite.now})
+	suite.Require().NoError(err)
+	revoked, err := store.Create(mandates.Mandate{MerchantId: "merchant_a", CardToken: "crd_2", CardLast4: "8112", CreatedAt: suite.now})
+	suite.Require().NoError(err)
+	_, err = store.Revoke(revoked.Id, suite.now.Add(time.Hour))
+	suite.Require().NoError(err)
+	fileLog.Close()
+
+	suite.Run("It should recover the mandates and their status from the log", func() {
+		reopened, err := eventlog.OpenFileLog(path)
+		suite.Require().NoError(err)
+		defer reopened.Close()
+		recovered, err := mandates.Open(reopened)
+		suite.Require().NoError(err)
+
+		mandate, err := recovered.Active(active.Id, "merchant_a")
+		suite.NoError(err)
+		suite.Equal(active, mandate)
+		_, err = recovered.Active(revoked.Id, "merchant_a")
+		suite.ErrorIs(err, mandates.ErrMandateRevoked)
+	})
+
+	suite.Run("When the mandate is unknown it should not be revoked", func() {
+		_, err := mandates.New().Revoke(active.Id, suite.now)
+		suite.ErrorIs(err, mandates.ErrMandateNotFound)
+	})
+}
+
+func TestMandatesTestSuite(t *testing.T) {
+	suite.Run(t, new(mandatesTestSuite))
+}
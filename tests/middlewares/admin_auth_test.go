@@ -0,0 +1,48 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/middlewares"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type adminAuthTestSuite struct {
+	suite.Suite
+}
+
+func (suite *adminAuthTestSuite) get(apiKey string, header string) int {
+	ginEngine := gin.New()
+	ginEngine.GET("/api/v1/admin/blocklist", middlewares.AdminAuth(apiKey), func(context *gin.Context) {
+		context.Status(http.StatusOK)
+	})
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/admin/blocklist", nil)
+	if header != "" {
+		request.Header.Set(middlewares.ADMIN_API_KEY_HEADER, header)
+	}
+	ginEngine.ServeHTTP(recorder, request)
+	return recorder.Code
+}
+
+func (suite *adminAuthTestSuite) Test_AdminAuth() {
+	suite.Run("When the key matches it should pass the request on", func() {
+		suite.Equal(http.StatusOK, suite.get("admin_secret", "admin_secret"))
+	})
+
+	suite.Run("When the key is missing or wrong it should return 401", func() {
+		suite.Equal(http.StatusUnauthorized, suite.get("admin_secret", ""))
+		suite.Equal(http.StatusUnauthorized, suite.get("admin_secret", "admin_guess"))
+	})
+
+	suite.Run("When no key is configured it should refuse every request", func() {
+		suite.Equal(http.StatusUnauthorized, suite.get("", ""))
+	})
+}
+
+func TestAdminAuthTestSuite(t *testing.T) {
+	suite.Run(t, new(adminAuthTestSuite))
+}
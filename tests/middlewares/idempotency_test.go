@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/middlewares"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/clock"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/idempotency"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type idempotencyTestSuite struct {
+	suite.Suite
+	ginEngine *gin.Engine
+	store     *idempotency.Store
+	fakeClock *clock.Fake
+	processed int
+}
+
+func (suite *idempotencyTestSuite) SetupTest() {
+	suite.fakeClock = clock.NewFake(time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC))
+	suite.store = idempotency.NewStore(time.Hour)
+	suite.processed = 0
+
+	suite.ginEngine = gin.New()
+	suite.ginEngine.POST("/api/v1/payments", middlewares.MerchantAuth(nil), middlewares.Idempotency(suite.store, suite.fakeClock), func(context *gin.Context) {
+		suite.processed++
+		context.JSON(http.StatusOK, gin.H{"payment": suite.processed})
+	})
+}
+
+func (suite *idempotencyTestSuite) post(merchantId string, key string, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/payments", bytes.NewBufferString(body))
+	request.Header.Set("X-Merchant-Id", merchantId)
+	if key != "" {
+		request.Header.Set(middlewares.IDEMPOTENCY_KEY_HEADER, key)
+	}
+	suite.ginEngine.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (suite *idempotencyTestSuite) Test_Replay() {
+	first := suite.post("merchant_a", "key_1", `{"amount":100}`)
+	suite.Equal(`{"payment":1}`, first.Body.String())
+
+	suite.Run("When the key is repeated it should replay the first response", func() {
+		replayed := suite.post("merchant_a", "key_1", `{"amount":100}`)
+		suite.Equal(http.StatusOK, replayed.Code)
+		suite.Equal(`{"payment":1}`, replayed.Body.String())
+		suite.Equal("true", replayed.Header().Get(middlewares.IDEMPOTENT_REPLAYED_HEADER))
+		suite.Equal(1, suite.processed)
+	})
+
+	suite.Run("When the key is reused with another body it should return 422", func() {
+		response := suite.post("merchant_a", "key_1", `{"amount":200}`)
+		suite.Equal(http.StatusUnprocessableEntity, response.Code)
+		suite.Equal(1, suite.processed)
+	})
+
+	suite.Run("When another merchant uses the same key it should be processed", func() {
+		response := suite.post("merchant_b", "key_1", `{"amount":100}`)
+		suite.Equal(`{"payment":2}`, response.Body.String())
+	})
+
+	suite.Run("When no key is sent every request should be processed", func() {
+		for i := 0; i < 2; i++ {
+			suite.post("merchant_a", "", `{"amount":100}`)
+		}
+		suite.Equal(4, suite.processed)
+	})
+
+	suite.Run("When the key has expired it should be processed again", func() {
+		suite.fakeClock.Advance(time.Hour)
+		response := suite.post("merchant_a", "key_1", `{"amount":100}`)
+		suite.Equal(`{"payment":5}`, response.Body.String())
+	})
+}
+
+func (suite *idempotencyTestSuite) Test_InProgress() {
+	now := suite.fakeClock.Now()
+	_, err := suite.store.Begin("merchant_a POST /api/v1/payments key_2", "fingerprint", now)
+	suite.NoError(err)
+
+	suite.Run("When the first request has not finished it should return ErrInProgress", func() {
+		_, err := suite.store.Begin("merchant_a POST /api/v1/payments key_2", "fingerprint", now)
+		suite.ErrorIs(err, idempotency.ErrInProgress)
+	})
+
+	suite.Run("When the first request is released the key should be free again", func() {
+		suite.store.Release("merchant_a POST /api/v1/payments key_2")
+		stored, err := suite.store.Begin("merchant_a POST /api/v1/payments key_2", "fingerprint", now)
+		suite.NoError(err)
+		suite.Nil(stored)
+	})
+}
+
+func TestIdempotencyTestSuite(t *testing.T) {
+	suite.Run(t, new(idempotencyTestSuite))
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/middlewares"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type merchantAuthTestSuite struct {
+	suite.Suite
+}
+
+func (suite *merchantAuthTestSuite) get(apiKeys map[string]string, headers map[string]string) (int, string) {
+	ginEngine := gin.New()
+	ginEngine.GET("/api/v1/balances", middlewares.MerchantAuth(apiKeys), func(context *gin.Context) {
+		context.String(http.StatusOK, middlewares.MerchantIdFrom(context))
+	})
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/balances", nil)
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	ginEngine.ServeHTTP(recorder, request)
+	return recorder.Code, recorder.Body.String()
+}
+
+func (suite *merchantAuthTestSuite) call(apiKeys map[string]string, pairs ...string) (string, error) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(pairs...))
+	response, err := middlewares.MerchantAuthInterceptor(apiKeys)(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return middlewares.MerchantIdFromContext(ctx), nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return response.(string), nil
+}
+
+func (suite *merchantAuthTestSuite) Test_TrustedHeader() {
+	suite.Run("When the merchant header is set it should act for that merchant", func() {
+		code, merchantId := suite.get(nil, map[string]string{middlewares.MERCHANT_ID_HEADER: "merchant_a"})
+		suite.Equal(http.StatusOK, code)
+		suite.Equal("merchant_a", merchantId)
+	})
+
+	suite.Run("When the merchant header is missing it should return 401", func() {
+		code, _ := suite.get(nil, nil)
+		suite.Equal(http.StatusUnauthorized, code)
+	})
+}
+
+func (suite *merchantAuthTestSuite) Test_APIKeys() {
+	apiKeys := map[string]string{"key_a": "merchant_a", "key_b": "merchant_b"}
+
+	suite.Run("When the API key is known it should act for its merchant", func() {
+		code, merchantId := suite.get(apiKeys, map[string]string{middlewares.API_KEY_HEADER: "key_b"})
+		suite.Equal(http.StatusOK, code)
+		suite.Equal("merchant_b", merchantId)
+
+		code, merchantId = suite.get(apiKeys, map[string]string{"Authorization": "Bearer key_a", middlewares.MERCHANT_ID_HEADER: "merchant_a"})
+		suite.Equal(http.StatusOK, code)
+		suite.Equal("merchant_a", merchantId)
+	})
+
+	suite.Run("When the API key is missing or unknown it should return 401, whatever the merchant header says", func() {
+		code, _ := suite.get(apiKeys, map[string]string{middlewares.MERCHANT_ID_HEADER: "merchant_a"})
+		suite.Equal(http.StatusUnauthorized, code)
+		code, _ = suite.get(apiKeys, map[string]string{middlewares.API_KEY_HEADER: "key_guess", middlewares.MERCHANT_ID_HEADER: "merchant_a"})
+		suite.Equal(http.StatusUnauthorized, code)
+	})
+
+	suite.Run("When the merchant header names another merchant it should return 403", func() {
+		code, _ := suite.get(apiKeys, map[string]string{middlewares.API_KEY_HEADER: "key_a", middlewares.MERCHANT_ID_HEADER: "merchant_b"})
+		suite.Equal(http.StatusForbidden, code)
+	})
+}
+
+func (suite *merchantAuthTestSuite) Test_Interceptor() {
+	apiKeys := map[string]string{"key_a": "merchant_a"}
+
+	suite.Run("When the metadata identifies a merchant it should act for it", func() {
+		merchantId, err := suite.call(nil, middlewares.MERCHANT_ID_METADATA, "merchant_b")
+		suite.Require().NoError(err)
+		suite.Equal("merchant_b", merchantId)
+
+		merchantId, err = suite.call(apiKeys, middlewares.API_KEY_METADATA, "key_a")
+		suite.Require().NoError(err)
+		suite.Equal("merchant_a", merchantId)
+	})
+
+	suite.Run("When the call cannot be tied to a merchant it should be refused", func() {
+		_, err := suite.call(nil)
+		suite.Equal(codes.Unauthenticated, status.Code(err))
+		_, err = suite.call(apiKeys, middlewares.MERCHANT_ID_METADATA, "merchant_a")
+		suite.Equal(codes.Unauthenticated, status.Code(err))
+		_, err = suite.call(apiKeys, middlewares.API_KEY_METADATA, "key_a", middlewares.MERCHANT_ID_METADATA, "merchant_b")
+		suite.Equal(codes.PermissionDenied, status.Code(err))
+	})
+}
+
+func TestMerchantAuthTestSuite(t *testing.T) {
+	suite.Run(t, new(merchantAuthTestSuite))
+}
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/docs"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/middlewares"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+const paymentId = "pay_0190b5a1c2d37e4f8a9b0c1d2e3f4a5b"
+
+type openAPIValidationTestSuite struct {
+	suite.Suite
+	handlerCalls int
+}
+
+func (suite *openAPIValidationTestSuite) engine(config middlewares.OpenAPIValidationConfig) *gin.Engine {
+	spec, err := docs.OpenAPI3()
+	suite.Require().NoError(err)
+	validation, err := middlewares.OpenAPIValidation(spec, config)
+	suite.Require().NoError(err)
+
+	engine := gin.New()
+	engine.Use(validation)
+	engine.POST("/api/v1/payments", func(context *gin.Context) {
+		suite.handlerCalls++
+		context.JSON(http.StatusOK, gin.H{"code": 200, "errors": []string{}, "data": gin.H{"id": paymentId}})
+	})
+	engine.GET("/api/v1/payments/:id", func(context *gin.Context) {
+		context.JSON(http.StatusOK, gin.H{"code": 200, "errors": []string{}, "data": gin.H{"amount": "100"}})
+	})
+	engine.GET("/api/v1/reports/:id", func(context *gin.Context) {
+		context.Data(http.StatusOK, "text/csv", []byte("date,merchant_id\n"))
+	})
+	engine.GET("/internal", func(context *gin.Context) {
+		context.String(http.StatusOK, "ok")
+	})
+	return engine
+}
+
+func (suite *openAPIValidationTestSuite) serve(engine *gin.Engine, method string, path string, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	if body != "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	engine.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (suite *openAPIValidationTestSuite) Test_Requests() {
+	engine := suite.engine(middlewares.OpenAPIValidationConfig{})
+
+	suite.Run("When the body matches the spec it should reach the handler", func() {
+		suite.handlerCalls = 0
+		response := suite.serve(engine, http.MethodPost, "/api/v1/payments", `{"card_number":"2222405343248877","expiration_month":4,"expiration_year":2026,"currency":"GBP","amount":100,"cvv":"123"}`)
+		suite.Equal(http.StatusOK, response.Code)
+		suite.Equal(1, suite.handlerCalls)
+	})
+
+	suite.Run("When the body breaks the spec it should return 400 naming the field", func() {
+		suite.handlerCalls = 0
+		response := suite.serve(engine, http.MethodPost, "/api/v1/payments", `{"card_number":"2222405343248877","expiration_month":13,"expiration_year":2026,"currency":"GBP","amount":100,"cvv":"123"}`)
+		suite.Equal(http.StatusBadRequest, response.Code)
+		suite.Contains(response.Body.String(), "expiration_month")
+		suite.Equal(0, suite.handlerCalls)
+	})
+
+	suite.Run("When the route is not in the spec it should not be checked", func() {
+		response := suite.serve(engine, http.MethodGet, "/internal", "")
+		suite.Equal(http.StatusOK, response.Code)
+		suite.Equal("ok", response.Body.String())
+	})
+
+	suite.Run("When responses are not validated it should pass them through", func() {
+		response := suite.serve(engine, http.MethodGet, "/api/v1/payments/"+paymentId, "")
+		suite.Equal(http.StatusOK, response.Code)
+	})
+}
+
+func (suite *openAPIValidationTestSuite) Test_Responses() {
+	engine := suite.engine(middlewares.OpenAPIValidationConfig{ValidateResponses: true})
+
+	suite.Run("When the response matches the spec it should be written unchanged", func() {
+		response := suite.serve(engine, http.MethodPost, "/api/v1/payments", `{"card_number":"2222405343248877","expiration_month":4,"expiration_year":2026,"currency":"GBP","amount":100,"cvv":"123"}`)
+		suite.Equal(http.StatusOK, response.Code)
+		suite.Contains(response.Body.String(), paymentId)
+	})
+
+	suite.Run("When the response breaks the spec it should return 500", func() {
+		response := suite.serve(engine, http.MethodGet, "/api/v1/payments/"+paymentId, "")
+		suite.Equal(http.StatusInternalServerError, response.Code)
+		suite.Contains(response.Body.String(), "data.amount")
+	})
+
+	suite.Run("When the response is not JSON it should be written straight through", func() {
+		response := suite.serve(engine, http.MethodGet, "/api/v1/reports/rpt_0190b5a1c2d37e4f8a9b0c1d2e3f4a5b?format=csv", "")
+		suite.Equal(http.StatusOK, response.Code)
+		suite.Equal("date,merchant_id\n", response.Body.String())
+	})
+}
+
+func TestOpenAPIValidationTestSuite(t *testing.T) {
+	suite.Run(t, new(openAPIValidationTestSuite))
+}
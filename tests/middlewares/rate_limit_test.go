@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/middlewares"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/clock"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/idempotency"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type rateLimitTestSuite struct {
+	suite.Suite
+	ginEngine *gin.Engine
+	fakeClock *clock.Fake
+}
+
+func (suite *rateLimitTestSuite) SetupTest() {
+	suite.fakeClock = clock.NewFake(time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC))
+
+	perAPIKey := ratelimit.Limit{Burst: 3, Period: time.Minute}
+	perCard := ratelimit.Limit{Burst: 2, Period: time.Hour}
+	rateLimit := middlewares.RateLimit(ratelimit.NewMemoryStore(), middlewares.RateLimitConfig{PerAPIKey: &perAPIKey, PerCard: &perCard, Clock: suite.fakeClock})
+	echo := func(context *gin.Context) {
+		body, _ := context.GetRawData()
+		context.String(http.StatusOK, string(body))
+	}
+	idempotent := middlewares.Idempotency(idempotency.NewStore(24*time.Hour), suite.fakeClock)
+	suite.ginEngine = gin.New()
+	suite.ginEngine.POST("/api/v1/payments", idempotent, rateLimit, echo)
+	suite.ginEngine.POST("/api/v2/payments", idempotent, rateLimit, echo)
+}
+
+func (suite *rateLimitTestSuite) post(apiKey string, body string) *httptest.ResponseRecorder {
+	return suite.postTo("/api/v1/payments", apiKey, body)
+}
+
+func (suite *rateLimitTestSuite) postTo(path string, apiKey string, body string) *httptest.ResponseRecorder {
+	return suite.postWithKey(path, apiKey, "", body)
+}
+
+func (suite *rateLimitTestSuite) postWithKey(path string, apiKey string, idempotencyKey string, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+	request.Header.Set(middlewares.API_KEY_HEADER, apiKey)
+	if idempotencyKey != "" {
+		request.Header.Set(middlewares.IDEMPOTENCY_KEY_HEADER, idempotencyKey)
+	}
+	suite.ginEngine.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (suite *rateLimitTestSuite) Test_PerAPIKey() {
+	for i := 2; i >= 0; i-- {
+		response := suite.post("key_a", "{}")
+		suite.Equal(http.StatusOK, response.Code)
+		suite.Equal("3", response.Header().Get("RateLimit-Limit"))
+		suite.Equal(string(rune('0'+i)), response.Header().Get("RateLimit-Remaining"))
+	}
+
+	suite.Run("When the limit is exhausted it should return 429 with Retry-After", func() {
+		response := suite.post("key_a", "{}")
+		suite.Equal(http.StatusTooManyRequests, response.Code)
+		suite.Equal("20", response.Header().Get("Retry-After"))
+	})
+
+	suite.Run("Other API keys should not be affected", func() {
+		suite.Equal(http.StatusOK, suite.post("key_b", "{}").Code)
+	})
+
+	suite.Run("When the bucket refills it should allow again", func() {
+		suite.fakeClock.Advance(20 * time.Second)
+		suite.Equal(http.StatusOK, suite.post("key_a", "{}").Code)
+	})
+}
+
+func (suite *rateLimitTestSuite) Test_PerCard() {
+	body := `{"card_number":"2222405343248877"}`
+	suite.Equal(http.StatusOK, suite.post("key_a", body).Code)
+	suite.Equal(http.StatusOK, suite.post("key_b", body).Code)
+
+	response := suite.post("key_c", body)
+	suite.Equal(http.StatusTooManyRequests, response.Code)
+
+	suite.Run("The handler should still receive the request body", func() {
+		response := suite.post("key_d", `{"card_number":"4111111111111111"}`)
+		suite.Equal(`{"card_number":"4111111111111111"}`, response.Body.String())
+	})
+
+	suite.Run("A request denied by the card limit should not use up the API key limit", func() {
+		for i := 0; i < 5; i++ {
+			suite.Equal(http.StatusTooManyRequests, suite.post("key_e", body).Code)
+		}
+		response := suite.post("key_e", "{}")
+		suite.Equal(http.StatusOK, response.Code)
+		suite.Equal("2", response.Header().Get("RateLimit-Remaining"))
+	})
+
+	suite.Run("When the body is too large it should return 413", func() {
+		large := `{"card_number":"2222405343248877","padding":"` + strings.Repeat("x", middlewares.MAX_RATE_LIMITED_BODY) + `"}`
+		suite.Equal(http.StatusRequestEntityTooLarge, suite.post("key_f", large).Code)
+	})
+}
+
+func (suite *rateLimitTestSuite) Test_PerCardV2() {
+	body := `{"card":{"number":"2222405343248877","expiry_month":4,"expiry_year":2030}}`
+	suite.Equal(http.StatusOK, suite.postTo("/api/v2/payments", "key_a", body).Code)
+	suite.Equal(http.StatusOK, suite.postTo("/api/v2/payments", "key_b", body).Code)
+	suite.Equal(http.StatusTooManyRequests, suite.postTo("/api/v2/payments", "key_c", body).Code)
+
+	suite.Run("The v1 and v2 shapes of the same card should share its limit", func() {
+		suite.Equal(http.StatusTooManyRequests, suite.post("key_d", `{"card_number":"2222405343248877"}`).Code)
+	})
+
+	suite.Run("Other cards should not be affected", func() {
+		suite.Equal(http.StatusOK, suite.postTo("/api/v2/payments", "key_e", `{"card":{"number":"4111111111111111"}}`).Code)
+	})
+}
+
+func (suite *rateLimitTestSuite) Test_IdempotentReplay() {
+	for i := 0; i < 3; i++ {
+		suite.Equal(http.StatusOK, suite.postWithKey("/api/v1/payments", "key_a", "idem_1", "{}").Code)
+	}
+
+	suite.Run("A replayed request should not use up the limit", func() {
+		response := suite.postWithKey("/api/v1/payments", "key_a", "idem_2", "{}")
+		suite.Equal(http.StatusOK, response.Code)
+		suite.Equal("1", response.Header().Get("RateLimit-Remaining"))
+	})
+
+	suite.Run("When the limit is exhausted a retry should still be replayed", func() {
+		suite.Equal(http.StatusOK, suite.postWithKey("/api/v1/payments", "key_a", "idem_3", "{}").Code)
+		suite.Equal(http.StatusTooManyRequests, suite.post("key_a", "{}").Code)
+
+		response := suite.postWithKey("/api/v1/payments", "key_a", "idem_1", "{}")
+		suite.Equal(http.StatusOK, response.Code)
+		suite.Equal("true", response.Header().Get(middlewares.IDEMPOTENT_REPLAYED_HEADER))
+	})
+}
+
+func TestRateLimitTestSuite(t *testing.T) {
+	suite.Run(t, new(rateLimitTestSuite))
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/middlewares"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type versioningTestSuite struct {
+	suite.Suite
+	ginEngine *gin.Engine
+	handler   http.Handler
+	// globalRuns counts the requests seen by the engine's global middleware.
+	globalRuns int
+}
+
+func (suite *versioningTestSuite) SetupTest() {
+	suite.ginEngine = gin.New()
+	suite.globalRuns = 0
+	suite.ginEngine.Use(func(context *gin.Context) {
+		suite.globalRuns++
+		context.Next()
+	})
+	v1 := suite.ginEngine.Group("/api/v1/payments", middlewares.Deprecation(middlewares.DeprecationConfig{
+		DeprecatedAt: time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC),
+		Sunset:       time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+		Successor:    "/api/v2/payments",
+	}))
+	v1.GET("", func(context *gin.Context) {
+		context.String(http.StatusOK, "v1")
+	})
+	suite.ginEngine.GET("/api/v2/payments", func(context *gin.Context) {
+		context.String(http.StatusOK, "v2")
+	})
+	suite.handler = middlewares.NegotiateVersion(suite.ginEngine, middlewares.VersionConfig{Default: "1", Supported: []string{"1", "2"}})
+}
+
+func (suite *versioningTestSuite) get(path string, version string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	if version != "" {
+		request.Header.Set(middlewares.API_VERSION_HEADER, version)
+	}
+	suite.handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (suite *versioningTestSuite) Test_Deprecation() {
+	suite.Run("When calling v1 it should announce its deprecation and sunset", func() {
+		response := suite.get("/api/v1/payments", "")
+		suite.Equal(http.StatusOK, response.Code)
+		suite.Equal("@1717200000", response.Header().Get("Deprecation"))
+		suite.Equal("Sun, 01 Jun 2025 00:00:00 GMT", response.Header().Get("Sunset"))
+		suite.Equal(`</api/v2/payments>; rel="successor-version"`, response.Header().Get("Link"))
+	})
+
+	suite.Run("When calling v2 it should not be marked deprecated", func() {
+		response := suite.get("/api/v2/payments", "")
+		suite.Equal("v2", response.Body.String())
+		suite.Empty(response.Header().Get("Deprecation"))
+		suite.Empty(response.Header().Get("Sunset"))
+	})
+}
+
+func (suite *versioningTestSuite) Test_NegotiateVersion() {
+	suite.Run("When no version is asked for it should serve the default", func() {
+		response := suite.get("/api/payments", "")
+		suite.Equal(http.StatusOK, response.Code)
+		suite.Equal("v1", response.Body.String())
+		suite.Equal("1", response.Header().Get(middlewares.API_VERSION_HEADER))
+		suite.NotEmpty(response.Header().Get("Deprecation"))
+	})
+
+	suite.Run("It should run the global middlewares once", func() {
+		suite.globalRuns = 0
+		suite.Equal(http.StatusOK, suite.get("/api/payments", "2").Code)
+		suite.Equal(1, suite.globalRuns)
+	})
+
+	suite.Run("When a version is asked for it should serve it", func() {
+		response := suite.get("/api/payments", "2")
+		suite.Equal(http.StatusOK, response.Code)
+		suite.Equal("v2", response.Body.String())
+		suite.Equal("2", response.Header().Get(middlewares.API_VERSION_HEADER))
+	})
+
+	suite.Run("When an unsupported version is asked for it should return 400", func() {
+		response := suite.get("/api/payments", "7")
+		suite.Equal(http.StatusBadRequest, response.Code)
+		suite.Contains(response.Body.String(), "unsupported API version")
+	})
+
+	suite.Run("When the negotiated route does not exist it should return 404", func() {
+		suite.Equal(http.StatusNotFound, suite.get("/api/refunds", "2").Code)
+		suite.Equal(http.StatusNotFound, suite.get("/api/v3/payments", "").Code)
+		suite.Equal(http.StatusNotFound, suite.get("/other", "2").Code)
+	})
+}
+
+func TestVersioningTestSuite(t *testing.T) {
+	suite.Run(t, new(versioningTestSuite))
+}
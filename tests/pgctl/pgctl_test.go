@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/apimodels/res"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/handlers"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/middlewares"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/banksim"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/clock"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/pgctl"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/reports"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type pgctlTestSuite struct {
+	suite.Suite
+	gateway       *httptest.Server
+	bankSimulator *httptest.Server
+	fakeClock     *clock.Fake
+	configPath    string
+}
+
+func (suite *pgctlTestSuite) SetupSuite() {
+	suite.fakeClock = clock.NewFake(time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC))
+	handlers.SetClock(suite.fakeClock)
+	suite.bankSimulator = banksim.NewServer(banksim.Config{})
+	os.Setenv("ACQUIRING_BANK_BASE_URL", suite.bankSimulator.URL)
+
+	reportStore := reports.NewStore("")
+	suite.Require().NoError(reportStore.Save(reports.Report{
+		Id:         "rpt_0190b5a1c2d37e4f8a9b0c1d2e3f4a5b",
+		MerchantId: "merchant_ops",
+		Date:       "2024-06-14",
+		Rows:       []reports.Row{{Currency: "GBP", Gross: 1000, Net: 1000, Captures: 1}},
+	}))
+	handlers.SetReports(reportStore, time.UTC)
+
+	engine := gin.New()
+	paymentGroup := engine.Group("api/v1/payments", middlewares.MerchantAuth(nil))
+	paymentGroup.POST("", handlers.CreatePayment)
+	paymentGroup.GET("", handlers.ListPayments)
+	paymentGroup.GET(":id", handlers.GetPaymentById)
+	paymentGroup.GET(":id/events", handlers.GetPaymentEvents)
+	paymentGroup.POST(":id/captures", handlers.CapturePayment)
+	paymentGroup.POST(":id/refunds", handlers.RefundPayment)
+	engine.GET("api/v1/reports", middlewares.MerchantAuth(nil), handlers.ListReports)
+	engine.GET("api/v1/reports/:id", middlewares.MerchantAuth(nil), handlers.GetReport)
+	suite.gateway = httptest.NewServer(engine)
+
+	suite.configPath = filepath.Join(suite.T().TempDir(), "config.json")
+	config, _ := json.Marshal(pgctl.Config{BaseURL: suite.gateway.URL, MerchantId: "merchant_ops"})
+	suite.Require().NoError(os.WriteFile(suite.configPath, config, 0o600))
+}
+
+func (suite *pgctlTestSuite) TearDownSuite() {
+	suite.gateway.Close()
+	suite.bankSimulator.Close()
+	handlers.SetClock(clock.Real{})
+	handlers.SetReports(reports.NewStore(""), time.UTC)
+}
+
+func (suite *pgctlTestSuite) run(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := pgctl.Run(append([]string{"-config", suite.configPath}, args...), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func (suite *pgctlTestSuite) createPayment() res.PaymentDetails {
+	code, stdout, stderr := suite.run("-output", "json", "payments", "create", "-amount", "2500")
+	suite.Require().Equal(0, code, stderr)
+	var payment res.PaymentDetails
+	suite.Require().NoError(json.Unmarshal([]byte(stdout), &payment))
+	return payment
+}
+
+func (suite *pgctlTestSuite) Test_Payments() {
+	payment := suite.createPayment()
+	suite.Equal(enums.AUTHORIZED, payment.Status)
+	suite.Equal(2500, payment.Amount)
+
+	suite.Run("When getting a payment it should print a table row", func() {
+		code, stdout, _ := suite.run("payments", "get", payment.Id)
+		suite.Equal(0, code)
+		lines := strings.Split(strings.TrimSpace(stdout), "\n")
+		suite.Len(lines, 2)
+		suite.True(strings.HasPrefix(lines[0], "ID"))
+		suite.Contains(lines[1], payment.Id)
+		suite.Contains(lines[1], "**** 8877")
+	})
+
+	suite.Run("When refunding it should accept the id after the flags", func() {
+		response, err := http_post(suite.gateway.URL+"/api/v1/payments/"+payment.Id+"/captures", "merchant_ops", `{"amount":2500}`)
+		suite.Require().NoError(err)
+		response.Body.Close()
+		suite.Require().Equal(http.StatusOK, response.StatusCode)
+		code, stdout, stderr := suite.run("-output", "json", "payments", "refund", "-amount", "500", payment.Id)
+		suite.Equal(0, code, stderr)
+		var refunded res.PaymentDetails
+		suite.NoError(json.Unmarshal([]byte(stdout), &refunded))
+		suite.Equal(500, refunded.RefundedAmount)
+	})
+
+	suite.Run("When listing with a status filter it should only print matching payments", func() {
+		suite.createPayment()
+		code, stdout, _ := suite.run("payments", "list", "-status", enums.AUTHORIZED)
+		suite.Equal(0, code)
+		suite.NotContains(stdout, payment.Id)
+		suite.Contains(stdout, "page 1 of 1, 1 payments")
+	})
+
+	suite.Run("When showing events it should print the status history", func() {
+		code, stdout, _ := suite.run("payments", "events", payment.Id)
+		suite.Equal(0, code)
+		suite.Contains(stdout, enums.CAPTURED)
+	})
+
+	suite.Run("When the payment does not exist it should fail with the gateway's error", func() {
+		code, _, stderr := suite.run("payments", "get", "pay_0190b5a1c2d37e4f8a9b0c1d2e3f4a5b")
+		suite.Equal(1, code)
+		suite.Contains(stderr, "404")
+	})
+
+	suite.Run("When the command is unknown it should print the usage", func() {
+		code, _, stderr := suite.run("webhooks", "tail")
+		suite.Equal(2, code)
+		suite.Contains(stderr, "Usage: pgctl")
+	})
+}
+
+func (suite *pgctlTestSuite) Test_Reports() {
+	suite.Run("When listing reports it should print their net totals", func() {
+		code, stdout, _ := suite.run("reports", "list")
+		suite.Equal(0, code)
+		suite.Contains(stdout, "2024-06-14")
+		suite.Contains(stdout, "GBP 1000")
+	})
+
+	suite.Run("When exporting a report it should write the CSV file", func() {
+		out := filepath.Join(suite.T().TempDir(), "report.csv")
+		code, _, stderr := suite.run("reports", "export", "rpt_0190b5a1c2d37e4f8a9b0c1d2e3f4a5b", "-out", out)
+		suite.Equal(0, code, stderr)
+		content, err := os.ReadFile(out)
+		suite.NoError(err)
+		suite.Equal("date,merchant_id,currency,gross,fees,refunds,net,captures,refund_count\n2024-06-14,merchant_ops,GBP,1000,0,0,1000,1,0\n", string(content))
+	})
+
+	suite.Run("When another merchant is configured by flag it should not see the report", func() {
+		code, _, stderr := suite.run("-merchant-id", "merchant_other", "reports", "export", "rpt_0190b5a1c2d37e4f8a9b0c1d2e3f4a5b")
+		suite.Equal(1, code)
+		suite.Contains(stderr, "404")
+	})
+}
+
+func TestPgctlTestSuite(t *testing.T) {
+	suite.Run(t, new(pgctlTestSuite))
+}
+
+func http_post(url string, merchantId string, body string) (*http.Response, error) {
+	request, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Merchant-Id", merchantId)
+	request.Header.Set("If-Match", "*")
+	return http.DefaultClient.Do(request)
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/ledger"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/models"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/clock"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/reports"
+	"github.com/stretchr/testify/suite"
+)
+
+type reportsTestSuite struct {
+	suite.Suite
+	merchantLedger *ledger.Ledger
+}
+
+var day = time.Date(2024, time.June, 15, 0, 0, 0, 0, time.UTC)
+
+func (suite *reportsTestSuite) SetupTest() {
+	suite.merchantLedger = ledger.New(ledger.FeeSchedule{BasisPoints: 100, Fixed: 5})
+	at := day.Add(10 * time.Hour)
+	suite.NoError(suite.merchantLedger.RecordAuthorization("merchant_a", "pay_1", "GBP", 10000, at))
+	suite.NoError(suite.merchantLedger.RecordCapture("merchant_a", "pay_1", "GBP", 10000, 10000, at))
+	suite.NoError(suite.merchantLedger.RecordRefund("merchant_a", "pay_1", "GBP", 2500, at.Add(time.Hour)))
+	suite.NoError(suite.merchantLedger.RecordAuthorization("merchant_a", "pay_2", "USD", 2000, at))
+	suite.NoError(suite.merchantLedger.RecordCapture("merchant_a", "pay_2", "USD", 2000, 2000, at))
+	suite.NoError(suite.merchantLedger.RecordAuthorization("merchant_b", "pay_3", "GBP", 700, at))
+	suite.NoError(suite.merchantLedger.RecordCapture("merchant_b", "pay_3", "GBP", 700, 700, day.AddDate(0, 0, 1)))
+}
+
+func (suite *reportsTestSuite) Test_Generate() {
+	generated, err := reports.Generate(suite.merchantLedger.Entries(), day, day.AddDate(0, 0, 1))
+	suite.NoError(err)
+	suite.Len(generated, 1)
+
+	report := generated[0]
+	suite.Equal("merchant_a", report.MerchantId)
+	suite.Equal("2024-06-15", report.Date)
+	suite.Equal([]reports.Row{
+		{Currency: "GBP", Gross: 10000, Fees: 105, Refunds: 2500, Net: 7395, Captures: 1, RefundCount: 1},
+		{Currency: "USD", Gross: 2000, Fees: 25, Refunds: 0, Net: 1975, Captures: 1},
+	}, report.Rows)
+
+	var csv bytes.Buffer
+	suite.NoError(reports.WriteCSV(&csv, report))
+	suite.Equal("date,merchant_id,currency,gross,fees,refunds,net,captures,refund_count\n"+
+		"2024-06-15,merchant_a,GBP,10000,105,2500,7395,1,1\n"+
+		"2024-06-15,merchant_a,USD,2000,25,0,1975,1,0\n", csv.String())
+}
+
+func (suite *reportsTestSuite) Test_Scheduler() {
+	dir := suite.T().TempDir()
+	store := reports.NewStore(dir)
+	scheduler := reports.NewScheduler(store, suite.merchantLedger.Entries, time.UTC, clock.Real{})
+
+	suite.Run("When the day is over it should generate its reports once", func() {
+		generated, err := scheduler.RunDaily(day.AddDate(0, 0, 1).Add(time.Minute))
+		suite.NoError(err)
+		suite.Len(generated, 1)
+
+		generated, err = scheduler.RunDaily(day.AddDate(0, 0, 1).Add(time.Hour))
+		suite.NoError(err)
+		suite.Empty(generated)
+	})
+
+	suite.Run("When the store is reopened it should load the written reports", func() {
+		reopened := reports.NewStore(dir)
+		suite.NoError(reopened.Load())
+		listed := reopened.List("merchant_a")
+		suite.Len(listed, 1)
+		suite.Equal(7395, listed[0].Rows[0].Net)
+		suite.True(reopened.Generated("2024-06-15"))
+		suite.Empty(reopened.List("merchant_b"))
+	})
+
+	suite.Run("When days were missed while stopped it should catch up from the last report", func() {
+		reopened := reports.NewStore(dir)
+		suite.NoError(reopened.Load())
+		restarted := reports.NewScheduler(reopened, suite.merchantLedger.Entries, time.UTC, clock.Real{})
+
+		generated, err := restarted.RunDaily(day.AddDate(0, 0, 3).Add(time.Minute))
+		suite.NoError(err)
+		suite.Len(generated, 1)
+		suite.Equal("merchant_b", generated[0].MerchantId)
+		suite.Equal("2024-06-16", generated[0].Date)
+		suite.Len(reopened.List("merchant_a"), 1)
+	})
+}
+
+func (suite *reportsTestSuite) Test_Reconcile() {
+	payments := map[string]models.Payment{
+		"pay_1": {Id: "pay_1", Status: enums.PARTIALLY_REFUNDED, CurrencyCode: "GBP", CapturedAmount: 10000, RefundedAmount: 2500},
+		"pay_2": {Id: "pay_2", Status: enums.CAPTURED, CurrencyCode: "USD", CapturedAmount: 2000},
+		"pay_3": {Id: "pay_3", Status: enums.CAPTURED, CurrencyCode: "GBP", CapturedAmount: 700},
+	}
+	lookup := func(paymentId string) (models.Payment, bool) {
+		payment, ok := payments[paymentId]
+		return payment, ok
+	}
+
+	rows, err := reports.ParseSettlementFile(strings.NewReader("payment_id,type,currency,amount\n" +
+		"pay_1,capture,GBP,10000\n" +
+		"pay_1,refund,GBP,2500\n" +
+		"pay_2,capture,USD,1999\n" +
+		"pay_9,capture,GBP,100\n"))
+	suite.NoError(err)
+
+	reconciliation := reports.Reconcile(rows, lookup, []models.Payment{payments["pay_1"], payments["pay_3"]})
+	suite.Equal(1, reconciliation.Matched)
+	suite.Equal([]reports.Discrepancy{
+		{PaymentId: "pay_2", Code: reports.CAPTURE_AMOUNT_MISMATCH, Expected: "2000", Actual: "1999"},
+		{PaymentId: "pay_9", Code: reports.UNKNOWN_PAYMENT},
+		{PaymentId: "pay_3", Code: reports.MISSING_FROM_FILE},
+	}, reconciliation.Discrepancies)
+
+	suite.Run("When the file is malformed it should return ErrInvalidSettlementFile", func() {
+		_, err := reports.ParseSettlementFile(strings.NewReader("id,amount\npay_1,100\n"))
+		suite.True(errors.Is(err, reports.ErrInvalidSettlementFile))
+		_, err = reports.ParseSettlementFile(strings.NewReader("payment_id,type,currency,amount\npay_1,chargeback,GBP,100\n"))
+		suite.True(errors.Is(err, reports.ErrInvalidSettlementFile))
+	})
+}
+
+func TestReportsTestSuite(t *testing.T) {
+	suite.Run(t, new(reportsTestSuite))
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/bins"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/cards"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/risk"
+	"github.com/stretchr/testify/suite"
+)
+
+type riskEngineTestSuite struct {
+	suite.Suite
+	engine *risk.Engine
+	now    time.Time
+}
+
+func (suite *riskEngineTestSuite) SetupTest() {
+	config, err := risk.LoadConfig("../../config/risk_rules.example.yaml")
+	suite.NoError(err)
+	suite.engine = risk.NewEngine(config)
+	suite.now = time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC)
+}
+
+func input(cardNumber string, ip string, amount int) risk.Input {
+	return risk.Input{
+		CardNumber:      cardNumber,
+		CardFingerprint: cards.Fingerprint(cardNumber),
+		IP:              ip,
+		Currency:        "GBP",
+		Amount:          amount,
+	}
+}
+
+func (suite *riskEngineTestSuite) Test_Evaluate() {
+	suite.Run("When no rule triggers it should allow", func() {
+		assessment := suite.engine.Evaluate(input("2222405343248877", "81.2.69.10", 100), suite.now)
+		suite.Equal(risk.Assessment{Decision: risk.ALLOW, Rules: []string{}}, assessment)
+	})
+
+	suite.Run("When the amount exceeds the threshold it should flag for review", func() {
+		assessment := suite.engine.Evaluate(input("2222405343248112", "81.2.69.10", 500001), suite.now)
+		suite.Equal(risk.REVIEW, assessment.Decision)
+		suite.Equal([]string{"amount_threshold_gbp"}, assessment.Rules)
+	})
+
+	suite.Run("When the bin and ip countries differ it should add the mismatch score", func() {
+		assessment := suite.engine.Evaluate(input("2222405343248113", "203.0.113.7", 100), suite.now)
+		suite.Equal(30, assessment.Score)
+		suite.Equal([]string{"country_mismatch"}, assessment.Rules)
+	})
+
+	suite.Run("When the card's BIN is known its country should be used for the mismatch", func() {
+		card := input("2222405343248877", "81.2.69.10", 100)
+		card.Bin = &bins.Info{Country: "US", Funding: bins.CREDIT}
+		assessment := suite.engine.Evaluate(card, suite.now)
+		suite.Equal([]string{"country_mismatch"}, assessment.Rules)
+	})
+
+	suite.Run("When the card is prepaid it should add the funding score", func() {
+		card := input("4000050000000001", "", 100)
+		card.Bin = &bins.Info{Country: "GB", Funding: bins.PREPAID}
+		assessment := suite.engine.Evaluate(card, suite.now)
+		suite.Equal(40, assessment.Score)
+		suite.Equal([]string{"funding_prepaid"}, assessment.Rules)
+	})
+
+	suite.Run("When a card is used too often it should trigger velocity", func() {
+		for i := 0; i < 5; i++ {
+			suite.Equal(risk.ALLOW, suite.engine.Evaluate(input("4000000000000002", "", 100), suite.now).Decision)
+		}
+		assessment := suite.engine.Evaluate(input("4000000000000002", "", 100), suite.now)
+		suite.Equal([]string{"card_velocity"}, assessment.Rules)
+
+		assessment = suite.engine.Evaluate(input("4000000000000002", "", 100), suite.now.Add(2*time.Hour))
+		suite.Empty(assessment.Rules)
+	})
+}
+
+func (suite *riskEngineTestSuite) Test_WatchFile() {
+	path := filepath.Join(suite.T().TempDir(), "rules.yaml")
+	suite.NoError(os.WriteFile(path, []byte("block_score: 100\n"), 0o600))
+	config, err := risk.LoadConfig(path)
+	suite.NoError(err)
+	engine := risk.NewEngine(config)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go engine.WatchFile(path, 10*time.Millisecond, stop)
+	time.Sleep(50 * time.Millisecond)
+
+	blocked := cards.Fingerprint("2222405343248877")
+	rules := "block_score: 100\nblocked_cards:\n  score: 100\n  fingerprints: [\"" + blocked + "\"]\n"
+	suite.NoError(os.WriteFile(path, []byte(rules), 0o600))
+	suite.NoError(os.Chtimes(path, time.Now().Add(time.Second), time.Now().Add(time.Second)))
+
+	suite.Eventually(func() bool {
+		return engine.Evaluate(input("2222405343248877", "", 100), suite.now).Decision == risk.BLOCK
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRiskEngineTestSuite(t *testing.T) {
+	suite.Run(t, new(riskEngineTestSuite))
+}
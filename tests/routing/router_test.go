@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/bins"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/http_clients"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/routing"
+	"github.com/stretchr/testify/suite"
+)
+
+type fakeAcquirer struct {
+	status string
+	err    error
+	calls  int
+}
+
+func (acquirer *fakeAcquirer) AuthorizePayment(cardNumber string, expiryDate string, currency string, amount int, cvv string) (string, error) {
+	acquirer.calls++
+	return acquirer.status, acquirer.err
+}
+
+type routerTestSuite struct {
+	suite.Suite
+	router *routing.Router
+	bankA  *fakeAcquirer
+	bankB  *fakeAcquirer
+}
+
+func (suite *routerTestSuite) SetupTest() {
+	config, err := routing.LoadConfig("../../config/acquirers.example.json")
+	suite.NoError(err)
+	suite.router = routing.NewRouter(config)
+	suite.bankA = &fakeAcquirer{status: enums.AUTHORIZED}
+	suite.bankB = &fakeAcquirer{status: enums.AUTHORIZED}
+	suite.router.SetAcquirer("bank_a", suite.bankA)
+	suite.router.SetAcquirer("bank_b", suite.bankB)
+}
+
+func (suite *routerTestSuite) Test_Candidates() {
+	suite.Run("It should route by card brand", func() {
+		suite.Equal([]string{"bank_b", "bank_a"}, suite.router.Candidates(routing.Payment{CardNumber: "371449635398431", Currency: "GBP"}))
+	})
+
+	suite.Run("It should route by bin range with its fallback", func() {
+		suite.Equal([]string{"bank_a", "bank_b"}, suite.router.Candidates(routing.Payment{CardNumber: "2222405343248877", Currency: "GBP"}))
+	})
+
+	suite.Run("It should split by weight", func() {
+		payment := routing.Payment{CardNumber: "4111111111111111", Currency: "USD"}
+		suite.router.SetRandom(func(n int) int { return 69 })
+		suite.Equal("bank_a", suite.router.Candidates(payment)[0])
+		suite.router.SetRandom(func(n int) int { return 70 })
+		suite.Equal("bank_b", suite.router.Candidates(payment)[0])
+	})
+
+	suite.Run("It should route by issuing country and funding", func() {
+		debit := &bins.Info{Country: "US", Funding: bins.DEBIT}
+		suite.Equal([]string{"bank_b", "bank_a"}, suite.router.Candidates(routing.Payment{CardNumber: "4242424242424242", Currency: "GBP", Bin: debit}))
+		credit := &bins.Info{Country: "US", Funding: bins.CREDIT}
+		suite.Equal([]string{"bank_a"}, suite.router.Candidates(routing.Payment{CardNumber: "4242424242424242", Currency: "GBP", Bin: credit}))
+		suite.Equal([]string{"bank_a"}, suite.router.Candidates(routing.Payment{CardNumber: "4242424242424242", Currency: "GBP"}))
+	})
+
+	suite.Run("The scheme of a known BIN should win over the card number", func() {
+		suite.Equal([]string{"bank_b", "bank_a"}, suite.router.Candidates(routing.Payment{CardNumber: "4000000000000002", Currency: "GBP", Bin: &bins.Info{Scheme: "amex"}}))
+	})
+
+	suite.Run("When no rule matches it should use the default acquirer", func() {
+		suite.Equal([]string{"bank_a"}, suite.router.Candidates(routing.Payment{CardNumber: "4111111111111111", Currency: "GBP"}))
+	})
+}
+
+func (suite *routerTestSuite) Test_Authorize() {
+	payment := routing.Payment{CardNumber: "2222405343248877", Currency: "GBP"}
+
+	suite.Run("When the primary acquirer fails transiently it should fall back", func() {
+		suite.bankA.err = &http_clients.TransientError{StatusCode: 503}
+		result, err := suite.router.Authorize(payment)
+		suite.NoError(err)
+		suite.Equal(routing.Result{Status: enums.AUTHORIZED, Acquirer: "bank_b"}, result)
+	})
+
+	suite.Run("When the primary acquirer fails permanently it should not fall back", func() {
+		suite.bankA.err = errors.New("bad request")
+		suite.bankB.calls = 0
+		_, err := suite.router.Authorize(payment)
+		suite.Error(err)
+		suite.Equal(0, suite.bankB.calls)
+	})
+
+	suite.Run("When the primary acquirer's outcome is unknown it should not fall back", func() {
+		suite.bankA.err = fmt.Errorf("%w: timeout", http_clients.ErrOutcomeUnknown)
+		suite.bankB.calls = 0
+		result, err := suite.router.Authorize(payment)
+		suite.ErrorIs(err, http_clients.ErrOutcomeUnknown)
+		suite.Equal("bank_a", result.Acquirer)
+		suite.Equal(0, suite.bankB.calls)
+	})
+
+	suite.Run("When every acquirer is unavailable it should decline", func() {
+		suite.bankA.err = &http_clients.TransientError{StatusCode: 503}
+		suite.bankB.err = &http_clients.TransientError{StatusCode: 502}
+		result, err := suite.router.Authorize(payment)
+		suite.NoError(err)
+		suite.Equal(routing.Result{Status: enums.DECLIEND, Acquirer: "bank_b"}, result)
+	})
+}
+
+func (suite *routerTestSuite) Test_AcquirerFailures() {
+	var fallbackCalls int32
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fallbackCalls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"authorized":true,"authorization_code":"fallback"}`))
+	}))
+	defer fallback.Close()
+	authorize := func(primary string) (routing.Result, error) {
+		atomic.StoreInt32(&fallbackCalls, 0)
+		router := routing.NewRouter(routing.Config{
+			Acquirers: []routing.AcquirerConfig{{Name: "primary", BaseURL: primary, TimeoutMs: 50}, {Name: "fallback", BaseURL: fallback.URL}},
+			Rules:     []routing.Rule{{Acquirer: "primary", Fallback: []string{"fallback"}}},
+			Default:   "primary",
+		})
+		return router.Authorize(routing.Payment{CardNumber: "2222405343248877", Currency: "GBP", Amount: 100, CVV: "123"})
+	}
+
+	suite.Run("When the primary refuses the connection it should fall back", func() {
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+		result, err := authorize(closed.URL)
+		suite.NoError(err)
+		suite.Equal(routing.Result{Status: enums.AUTHORIZED, Acquirer: "fallback"}, result)
+	})
+
+	suite.Run("When the primary answers 5xx without an authorization it should fall back", func() {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer failing.Close()
+		result, err := authorize(failing.URL)
+		suite.NoError(err)
+		suite.Equal("fallback", result.Acquirer)
+	})
+
+	suite.Run("When the primary times out it should return an unknown outcome without falling back", func() {
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer slow.Close()
+		result, err := authorize(slow.URL)
+		suite.ErrorIs(err, http_clients.ErrOutcomeUnknown)
+		suite.Equal("primary", result.Acquirer)
+		suite.Equal(int32(0), atomic.LoadInt32(&fallbackCalls))
+	})
+
+	suite.Run("When the primary answers 5xx with an authorization it should return an unknown outcome", func() {
+		authorizedFailure := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"authorized":true,"authorization_code":"primary"}`))
+		}))
+		defer authorizedFailure.Close()
+		_, err := authorize(authorizedFailure.URL)
+		suite.ErrorIs(err, http_clients.ErrOutcomeUnknown)
+		suite.Equal(int32(0), atomic.LoadInt32(&fallbackCalls))
+	})
+}
+
+func TestRouterTestSuite(t *testing.T) {
+	suite.Run(t, new(routerTestSuite))
+}
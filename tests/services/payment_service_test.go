@@ -0,0 +1,433 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/blocklist"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/clock"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/customers"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/eventlog"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/http_clients"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/mandates"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/risk"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/routing"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/threeds"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/vault"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/services"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/store"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	authorizedCard = "2222405343248877"
+	declinedCard   = "2222405343248112"
+)
+
+// fakeAcquirer declines declinedCard and authorizes every other card.
+type fakeAcquirer struct {
+	err   error
+	calls int
+}
+
+func (acquirer *fakeAcquirer) AuthorizePayment(cardNumber string, expiryDate string, currency string, amount int, cvv string) (string, error) {
+	acquirer.calls++
+	if acquirer.err != nil {
+		return "", acquirer.err
+	}
+	if cardNumber == declinedCard {
+		return enums.DECLIEND, nil
+	}
+	return enums.AUTHORIZED, nil
+}
+
+func (acquirer *fakeAcquirer) AuthorizeMerchantInitiated(cardNumber string, expiryDate string, currency string, amount int) (string, error) {
+	return acquirer.AuthorizePayment(cardNumber, expiryDate, currency, amount, "")
+}
+
+type paymentServiceTestSuite struct {
+	suite.Suite
+	service         *services.PaymentService
+	deps            services.Dependencies
+	acquirer        *fakeAcquirer
+	directoryServer *threeds.Simulator
+	fakeClock       *clock.Fake
+}
+
+func (suite *paymentServiceTestSuite) SetupTest() {
+	suite.fakeClock = clock.NewFake(time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC))
+	suite.acquirer = &fakeAcquirer{}
+	suite.directoryServer = threeds.NewSimulator("", suite.fakeClock)
+	router := routing.NewSingleAcquirerRouter()
+	router.SetAcquirer("default", suite.acquirer)
+	suite.deps = services.Dependencies{
+		Store:           store.NewPaymentStore(eventlog.NewMemoryLog(), store.Options{}),
+		Risk:            risk.NewEngine(risk.Config{}),
+		Router:          router,
+		Blocklist:       blocklist.New(),
+		Customers:       customers.New(),
+		Mandates:        mandates.New(),
+		DirectoryServer: suite.directoryServer,
+		Vault:           vault.New(),
+		Clock:           suite.fakeClock,
+	}
+	suite.service = services.NewPaymentService(suite.deps)
+}
+
+func cardPayment(cardNumber string) services.CreatePaymentInput {
+	return services.CreatePaymentInput{
+		MerchantId:      "merchant_a",
+		CardNumber:      cardNumber,
+		ExpirationMonth: 4,
+		ExpirationYear:  2025,
+		Currency:        "GBP",
+		Amount:          1000,
+		CVV:             "123",
+	}
+}
+
+func (suite *paymentServiceTestSuite) Test_CreatePayment() {
+	suite.Run("When the bank authorizes it should store the authorized payment", func() {
+		payment, err := suite.service.CreatePayment(cardPayment(authorizedCard))
+		suite.NoError(err)
+		suite.Equal(enums.AUTHORIZED, payment.Status)
+		suite.Equal("default", payment.Acquirer)
+
+		stored, err := suite.service.GetPayment(payment.Id, "merchant_a")
+		suite.NoError(err)
+		suite.Equal(payment.Status, stored.Status)
+	})
+
+	suite.Run("When the bank declines it should return the declined payment without an error", func() {
+		payment, err := suite.service.CreatePayment(cardPayment(declinedCard))
+		suite.NoError(err)
+		suite.Equal(enums.DECLIEND, payment.Status)
+	})
+
+	suite.Run("When the input is invalid it should be rejected before the bank is called", func() {
+		suite.acquirer.calls = 0
+		input := cardPayment("1234")
+		input.Currency = "POUNDS"
+		_, err := suite.service.CreatePayment(input)
+		suite.ErrorIs(err, services.ErrRejected)
+		suite.Contains(err.Error(), "CardNumber")
+		suite.Contains(err.Error(), "Currency")
+		suite.Equal(0, suite.acquirer.calls)
+	})
+
+	suite.Run("When the card has expired it should be rejected", func() {
+		input := cardPayment(authorizedCard)
+		input.ExpirationYear = 2024
+		input.ExpirationMonth = 5
+		_, err := suite.service.CreatePayment(input)
+		suite.ErrorIs(err, services.ErrRejected)
+		suite.ErrorIs(err, services.ErrCardExpired)
+	})
+
+	suite.Run("When the customer does not exist it should not be found", func() {
+		_, err := suite.service.CreatePayment(services.CreatePaymentInput{
+			MerchantId:      "merchant_a",
+			CustomerId:      "cus_0190b5a1c2d37e4f8a9b0c1d2e3f4a5b",
+			PaymentMethodId: "pm_0190b5a1c2d37e4f8a9b0c1d2e3f4a5b",
+			Currency:        "GBP",
+			Amount:          1000,
+			CVV:             "123",
+		})
+		suite.ErrorIs(err, services.ErrNotFound)
+		suite.ErrorIs(err, customers.ErrCustomerNotFound)
+	})
+
+	suite.Run("When paying with a saved card it should use its details", func() {
+		customer, err := suite.deps.Customers.Create(customers.Customer{MerchantId: "merchant_a", Email: "shopper@example.com"})
+		suite.Require().NoError(err)
+		cardToken, err := suite.deps.Vault.Tokenize(authorizedCard)
+		suite.Require().NoError(err)
+		method, err := suite.deps.Customers.AttachCard(customer.Id, "merchant_a", customers.PaymentMethod{CardToken: cardToken, CardLast4: "8877", ExpirationMonth: 4, ExpirationYear: 2026})
+		suite.Require().NoError(err)
+
+		payment, err := suite.service.CreatePayment(services.CreatePaymentInput{
+			MerchantId:      "merchant_a",
+			CustomerId:      customer.Id,
+			PaymentMethodId: method.Id,
+			Currency:        "GBP",
+			Amount:          1000,
+			CVV:             "123",
+		})
+		suite.NoError(err)
+		suite.Equal(enums.AUTHORIZED, payment.Status)
+		suite.Equal(customer.Id, payment.CustomerId)
+		suite.Equal(2026, payment.ExpirationYear)
+		suite.Equal(cardToken, payment.CardToken)
+	})
+
+	suite.Run("When the bank cannot be reached it should be unavailable", func() {
+		suite.acquirer.err = &http_clients.TransientError{Err: errors.New("connection refused")}
+		defer func() { suite.acquirer.err = nil }()
+		_, err := suite.service.CreatePayment(cardPayment(authorizedCard))
+		suite.ErrorIs(err, services.ErrUnavailable)
+	})
+
+	suite.Run("When the bank's decision is unknown it should say so", func() {
+		suite.acquirer.err = fmt.Errorf("%w: timeout", http_clients.ErrOutcomeUnknown)
+		defer func() { suite.acquirer.err = nil }()
+		_, err := suite.service.CreatePayment(cardPayment(authorizedCard))
+		suite.ErrorIs(err, services.ErrOutcomeUnknown)
+		suite.NotErrorIs(err, services.ErrUnavailable)
+	})
+
+	suite.Run("When the bank fails otherwise it should be an internal error", func() {
+		suite.acquirer.err = errors.New("unexpected response")
+		defer func() { suite.acquirer.err = nil }()
+		_, err := suite.service.CreatePayment(cardPayment(authorizedCard))
+		suite.Error(err)
+		for _, kind := range []error{services.ErrRejected, services.ErrInvalid, services.ErrNotFound, services.ErrConflict, services.ErrUnavailable, services.ErrOutcomeUnknown} {
+			suite.NotErrorIs(err, kind)
+		}
+	})
+}
+
+func (suite *paymentServiceTestSuite) Test_GetAndListPayments() {
+	authorized, err := suite.service.CreatePayment(cardPayment(authorizedCard))
+	suite.Require().NoError(err)
+	_, err = suite.service.CreatePayment(cardPayment(declinedCard))
+	suite.Require().NoError(err)
+	other := cardPayment(authorizedCard)
+	other.MerchantId = "merchant_b"
+	_, err = suite.service.CreatePayment(other)
+	suite.Require().NoError(err)
+
+	suite.Run("When the id is malformed it should be invalid", func() {
+		_, err := suite.service.GetPayment("not-a-payment", "merchant_a")
+		suite.ErrorIs(err, services.ErrInvalid)
+	})
+
+	suite.Run("When the payment does not exist it should not be found", func() {
+		_, err := suite.service.GetPayment("pay_0190b5a1c2d37e4f8a9b0c1d2e3f4a5b", "merchant_a")
+		suite.ErrorIs(err, services.ErrNotFound)
+		suite.ErrorIs(err, store.ErrPaymentNotFound)
+	})
+
+	suite.Run("When listing it should apply every filter", func() {
+		suite.Len(suite.service.ListPayments(services.PaymentFilter{MerchantId: "merchant_a"}), 2)
+		payments := suite.service.ListPayments(services.PaymentFilter{MerchantId: "merchant_a", Status: enums.AUTHORIZED})
+		suite.Len(payments, 1)
+		suite.Equal(authorized.Id, payments[0].Id)
+		suite.Len(suite.service.ListPayments(services.PaymentFilter{}), 3)
+	})
+}
+
+func (suite *paymentServiceTestSuite) Test_CaptureAndRefund() {
+	payment, err := suite.service.CreatePayment(cardPayment(authorizedCard))
+	suite.Require().NoError(err)
+
+	suite.Run("When the payment belongs to another merchant it should not be found", func() {
+		_, err := suite.service.GetPayment(payment.Id, "merchant_b")
+		suite.ErrorIs(err, services.ErrNotFound)
+		_, err = suite.service.Capture(payment.Id, "merchant_b", 1000, 0)
+		suite.ErrorIs(err, services.ErrNotFound)
+		_, err = suite.service.Refund(payment.Id, "merchant_b", 100, 0)
+		suite.ErrorIs(err, services.ErrNotFound)
+
+		current, err := suite.service.GetPayment(payment.Id, "merchant_a")
+		suite.NoError(err)
+		suite.Equal(enums.AUTHORIZED, current.Status)
+	})
+
+	suite.Run("When refunding before capture it should conflict", func() {
+		_, err := suite.service.Refund(payment.Id, "merchant_a", 100, 0)
+		suite.ErrorIs(err, services.ErrConflict)
+	})
+
+	suite.Run("When the amount is not positive it should be invalid", func() {
+		_, err := suite.service.Capture(payment.Id, "merchant_a", 0, 0)
+		suite.ErrorIs(err, services.ErrInvalid)
+	})
+
+	suite.Run("When capturing more than authorized it should be invalid", func() {
+		_, err := suite.service.Capture(payment.Id, "merchant_a", 1001, 0)
+		suite.ErrorIs(err, services.ErrInvalid)
+		suite.ErrorIs(err, store.ErrInvalidAmount)
+	})
+
+	suite.Run("When capturing and refunding it should update the amounts", func() {
+		captured, err := suite.service.Capture(payment.Id, "merchant_a", 1000, 0)
+		suite.NoError(err)
+		suite.Equal(enums.CAPTURED, captured.Status)
+
+		refunded, err := suite.service.Refund(payment.Id, "merchant_a", 400, 0)
+		suite.NoError(err)
+		suite.Equal(enums.PARTIALLY_REFUNDED, refunded.Status)
+		suite.Equal(400, refunded.RefundedAmount)
+		suite.Len(refunded.Refunds, 1)
+		suite.Equal(captured.Version+1, refunded.Version)
+	})
+
+	suite.Run("When refunding at a version that is no longer current it should fail the precondition", func() {
+		current, err := suite.service.GetPayment(payment.Id, "merchant_a")
+		suite.Require().NoError(err)
+
+		_, err = suite.service.Refund(payment.Id, "merchant_a", 100, current.Version-1)
+		suite.ErrorIs(err, services.ErrPreconditionFailed)
+		suite.ErrorIs(err, store.ErrVersionMismatch)
+
+		refunded, err := suite.service.Refund(payment.Id, "merchant_a", 100, current.Version)
+		suite.NoError(err)
+		suite.Equal(500, refunded.RefundedAmount)
+	})
+}
+
+func (suite *paymentServiceTestSuite) Test_ThreeDS() {
+	input := cardPayment(authorizedCard)
+	input.ThreeDS = true
+	input.SetupMandate = true
+	payment, err := suite.service.CreatePayment(input)
+	suite.Require().NoError(err)
+	suite.Require().Equal(enums.REQUIRES_ACTION, payment.Status)
+	suite.Equal(0, suite.acquirer.calls)
+
+	suite.Run("When the cardholder has not answered it should conflict", func() {
+		_, err := suite.service.CompleteThreeDSChallenge(payment.Id, payment.ThreeDS.ChallengeId)
+		suite.ErrorIs(err, services.ErrConflict)
+		suite.ErrorIs(err, threeds.ErrChallengePending)
+		suite.Equal(0, suite.acquirer.calls)
+	})
+	suite.Require().NoError(suite.directoryServer.Respond(payment.ThreeDS.ChallengeId, threeds.AUTHENTICATED))
+
+	suite.Run("When the challenge id does not match it should be invalid", func() {
+		_, err := suite.service.CompleteThreeDSChallenge(payment.Id, "chl_unknown")
+		suite.ErrorIs(err, services.ErrInvalid)
+	})
+
+	suite.Run("When the bank is unavailable it should keep the challenge open for a retry", func() {
+		suite.acquirer.err = &http_clients.TransientError{Err: errors.New("connection refused")}
+		defer func() { suite.acquirer.err = nil }()
+		_, err := suite.service.CompleteThreeDSChallenge(payment.Id, payment.ThreeDS.ChallengeId)
+		suite.ErrorIs(err, services.ErrUnavailable)
+
+		current, err := suite.service.GetPayment(payment.Id, "merchant_a")
+		suite.Require().NoError(err)
+		suite.Equal(enums.REQUIRES_ACTION, current.Status)
+	})
+
+	suite.Run("When dependencies are replaced it should keep the open challenge", func() {
+		suite.service.SetDependencies(suite.deps)
+		completed, err := suite.service.CompleteThreeDSChallenge(payment.Id, payment.ThreeDS.ChallengeId)
+		suite.NoError(err)
+		suite.Equal(enums.AUTHORIZED, completed.Status)
+		suite.Equal(threeds.AUTHENTICATED, completed.ThreeDS.Status)
+		suite.NotEmpty(completed.MandateId)
+	})
+
+	suite.Run("When the challenge was already completed it should conflict", func() {
+		_, err := suite.service.CompleteThreeDSChallenge(payment.Id, payment.ThreeDS.ChallengeId)
+		suite.ErrorIs(err, services.ErrConflict)
+	})
+}
+
+func (suite *paymentServiceTestSuite) Test_ExpiredThreeDSChallenge() {
+	input := cardPayment(authorizedCard)
+	input.ThreeDS = true
+
+	suite.Run("When the challenge is answered after it expired it should decline the payment", func() {
+		payment, err := suite.service.CreatePayment(input)
+		suite.Require().NoError(err)
+		suite.Require().NoError(suite.directoryServer.Respond(payment.ThreeDS.ChallengeId, threeds.AUTHENTICATED))
+
+		suite.fakeClock.Advance(threeds.CHALLENGE_TTL)
+		_, err = suite.service.CompleteThreeDSChallenge(payment.Id, payment.ThreeDS.ChallengeId)
+		suite.ErrorIs(err, services.ErrConflict)
+		suite.Equal(0, suite.acquirer.calls)
+
+		current, err := suite.service.GetPayment(payment.Id, "merchant_a")
+		suite.Require().NoError(err)
+		suite.Equal(enums.DECLIEND, current.Status)
+		suite.Equal(threeds.FAILED, current.ThreeDS.Status)
+	})
+
+	suite.Run("When a challenge is left unanswered it should be declined once it expires", func() {
+		payment, err := suite.service.CreatePayment(input)
+		suite.Require().NoError(err)
+
+		suite.fakeClock.Advance(threeds.CHALLENGE_TTL - time.Second)
+		suite.NoError(suite.service.ExpireThreeDSChallenges())
+		current, err := suite.service.GetPayment(payment.Id, "merchant_a")
+		suite.Require().NoError(err)
+		suite.Equal(enums.REQUIRES_ACTION, current.Status)
+
+		suite.fakeClock.Advance(time.Second)
+		suite.NoError(suite.service.ExpireThreeDSChallenges())
+		current, err = suite.service.GetPayment(payment.Id, "merchant_a")
+		suite.Require().NoError(err)
+		suite.Equal(enums.DECLIEND, current.Status)
+
+		_, err = suite.service.CompleteThreeDSChallenge(payment.Id, payment.ThreeDS.ChallengeId)
+		suite.ErrorIs(err, services.ErrConflict)
+	})
+}
+
+func (suite *paymentServiceTestSuite) Test_ChargeMandate() {
+	input := cardPayment(authorizedCard)
+	input.SetupMandate = true
+	initial, err := suite.service.CreatePayment(input)
+	suite.Require().NoError(err)
+	suite.Require().NotEmpty(initial.MandateId)
+
+	suite.Run("When the mandate is active it should charge its card without a CVV", func() {
+		payment, err := suite.service.ChargeMandate(services.MandatePaymentInput{MandateId: initial.MandateId, MerchantId: "merchant_a", Currency: "GBP", Amount: 500})
+		suite.NoError(err)
+		suite.Equal(enums.AUTHORIZED, payment.Status)
+		suite.True(payment.MerchantInitiated)
+		suite.Equal(initial.MandateId, payment.MandateId)
+	})
+
+	suite.Run("When the mandate belongs to another merchant it should not be found", func() {
+		_, err := suite.service.ChargeMandate(services.MandatePaymentInput{MandateId: initial.MandateId, MerchantId: "merchant_b", Currency: "GBP", Amount: 500})
+		suite.ErrorIs(err, services.ErrNotFound)
+	})
+
+	suite.Run("When the mandate was revoked it should conflict", func() {
+		suite.deps.Mandates.Revoke(initial.MandateId, suite.fakeClock.Now())
+		_, err := suite.service.ChargeMandate(services.MandatePaymentInput{MandateId: initial.MandateId, MerchantId: "merchant_a", Currency: "GBP", Amount: 500})
+		suite.ErrorIs(err, services.ErrConflict)
+		suite.ErrorIs(err, mandates.ErrMandateRevoked)
+	})
+}
+
+func (suite *paymentServiceTestSuite) Test_CardNumberIsNotPersisted() {
+	dir := suite.T().TempDir()
+	logPath := filepath.Join(dir, "payments.log")
+	snapshotPath := filepath.Join(dir, "payments.snapshot")
+	fileLog, err := eventlog.OpenFileLog(logPath)
+	suite.Require().NoError(err)
+	defer fileLog.Close()
+	suite.deps.Store = store.NewPaymentStore(fileLog, store.Options{SnapshotPath: snapshotPath, SnapshotEvery: 1})
+	suite.service.SetDependencies(suite.deps)
+
+	payment, err := suite.service.CreatePayment(cardPayment(authorizedCard))
+	suite.Require().NoError(err)
+
+	suite.Run("The payment should refer to the card by token and last4", func() {
+		suite.Equal("8877", payment.CardLast4)
+		number, err := suite.deps.Vault.Detokenize(payment.CardToken)
+		suite.NoError(err)
+		suite.Equal(authorizedCard, number)
+	})
+
+	suite.Run("Neither the event log nor the snapshot should contain the card number", func() {
+		for _, path := range []string{logPath, snapshotPath} {
+			content, err := os.ReadFile(path)
+			suite.Require().NoError(err)
+			suite.Contains(string(content), payment.CardToken)
+			suite.NotContains(string(content), authorizedCard)
+		}
+	})
+}
+
+func TestPaymentServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(paymentServiceTestSuite))
+}
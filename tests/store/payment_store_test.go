@@ -0,0 +1,198 @@
+package tests
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/enums"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/eventlog"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/store"
+	"github.com/stretchr/testify/suite"
+)
+
+type paymentStoreTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (suite *paymentStoreTestSuite) SetupTest() {
+	suite.dir = suite.T().TempDir()
+}
+
+func (suite *paymentStoreTestSuite) newEvent(paymentId string, eventType string, data interface{}) eventlog.Event {
+	event, err := store.NewEvent(paymentId, eventType, enums.ACTOR_GATEWAY, eventType, time.Now(), data)
+	suite.NoError(err)
+	return event
+}
+
+func (suite *paymentStoreTestSuite) authorizeAndCapture(paymentStore *store.PaymentStore, paymentId string) {
+	_, err := paymentStore.Append(paymentId,
+		suite.newEvent(paymentId, store.PAYMENT_REQUESTED, store.PaymentRequestedData{
+			CardToken:       "crd_1",
+			CardLast4:       "8877",
+			CardFingerprint: "fingerprint",
+			ExpirationMonth: 4,
+			ExpirationYear:  2030,
+			CurrencyCode:    "GBP",
+			Amount:          100,
+		}),
+		suite.newEvent(paymentId, store.BANK_AUTHORIZED, nil),
+	)
+	suite.NoError(err)
+	_, err = paymentStore.Append(paymentId, suite.newEvent(paymentId, store.CAPTURED, store.CapturedData{Amount: 100}))
+	suite.NoError(err)
+}
+
+func (suite *paymentStoreTestSuite) Test_Projection() {
+	paymentStore := store.NewPaymentStore(eventlog.NewMemoryLog(), store.Options{})
+	suite.authorizeAndCapture(paymentStore, "pay_1")
+
+	suite.Run("It should project the current status and history", func() {
+		payment, ok := paymentStore.Get("pay_1")
+		suite.True(ok)
+		suite.Equal(enums.CAPTURED, payment.Status)
+		suite.Equal(100, payment.CapturedAmount)
+		suite.Equal("fingerprint", payment.CardFingerprint)
+		suite.Equal("crd_1", payment.CardToken)
+		suite.Equal("8877", payment.CardLast4)
+		suite.Len(payment.StatusHistory, 3)
+	})
+
+	suite.Run("When a refund exceeds the captured amount it should be rejected", func() {
+		_, err := paymentStore.Append("pay_1", suite.newEvent("pay_1", store.REFUNDED, store.RefundedData{RefundId: "ref_1", Amount: 101}))
+		suite.ErrorIs(err, store.ErrInvalidAmount)
+
+		events, err := paymentStore.Events("pay_1")
+		suite.NoError(err)
+		suite.Len(events, 3)
+	})
+
+	suite.Run("When the payment is not authorized it should reject a capture", func() {
+		_, err := paymentStore.Append("pay_1", suite.newEvent("pay_1", store.CAPTURED, store.CapturedData{Amount: 10}))
+		suite.ErrorIs(err, store.ErrInvalidTransition)
+	})
+}
+
+func (suite *paymentStoreTestSuite) Test_Version() {
+	paymentStore := store.NewPaymentStore(eventlog.NewMemoryLog(), store.Options{})
+	suite.authorizeAndCapture(paymentStore, "pay_1")
+
+	suite.Run("It should count the events applied to the payment", func() {
+		payment, _ := paymentStore.Get("pay_1")
+		suite.Equal(3, payment.Version)
+	})
+
+	suite.Run("When appending at a stale version it should write nothing", func() {
+		_, err := paymentStore.AppendAt("pay_1", 2, suite.newEvent("pay_1", store.REFUNDED, store.RefundedData{RefundId: "ref_1", Amount: 10}))
+		suite.ErrorIs(err, store.ErrVersionMismatch)
+
+		events, err := paymentStore.Events("pay_1")
+		suite.NoError(err)
+		suite.Len(events, 3)
+	})
+
+	suite.Run("When appending at the current version it should bump it", func() {
+		payment, err := paymentStore.AppendAt("pay_1", 3, suite.newEvent("pay_1", store.REFUNDED, store.RefundedData{RefundId: "ref_1", Amount: 10}))
+		suite.NoError(err)
+		suite.Equal(4, payment.Version)
+	})
+}
+
+func (suite *paymentStoreTestSuite) Test_Recover() {
+	logPath := filepath.Join(suite.dir, "payments.log")
+	snapshotPath := filepath.Join(suite.dir, "payments.snapshot")
+
+	fileLog, err := eventlog.OpenFileLog(logPath)
+	suite.NoError(err)
+	paymentStore := store.NewPaymentStore(fileLog, store.Options{SnapshotPath: snapshotPath, SnapshotEvery: 3})
+	suite.authorizeAndCapture(paymentStore, "pay_1")
+	suite.authorizeAndCapture(paymentStore, "pay_2")
+	_, err = paymentStore.Append("pay_2", suite.newEvent("pay_2", store.REFUNDED, store.RefundedData{RefundId: "ref_1", Amount: 40}))
+	suite.NoError(err)
+	suite.NoError(fileLog.Close())
+
+	suite.Run("It should rebuild the same state from the snapshot and the log", func() {
+		reopened, err := eventlog.OpenFileLog(logPath)
+		suite.NoError(err)
+		defer reopened.Close()
+		suite.Equal(int64(7), reopened.LastSequence())
+
+		recovered := store.NewPaymentStore(reopened, store.Options{SnapshotPath: snapshotPath, SnapshotEvery: 3})
+		suite.NoError(recovered.Recover())
+
+		payment, ok := recovered.Get("pay_2")
+		suite.True(ok)
+		suite.Equal(enums.PARTIALLY_REFUNDED, payment.Status)
+		suite.Equal(40, payment.RefundedAmount)
+		suite.Len(payment.StatusHistory, 4)
+		suite.Equal(4, payment.Version)
+
+		payment, ok = recovered.Get("pay_1")
+		suite.True(ok)
+		suite.Equal(enums.CAPTURED, payment.Status)
+	})
+
+	suite.Run("It should rebuild the same state from the log alone", func() {
+		reopened, err := eventlog.OpenFileLog(logPath)
+		suite.NoError(err)
+		defer reopened.Close()
+
+		recovered := store.NewPaymentStore(reopened, store.Options{})
+		suite.NoError(recovered.Recover())
+
+		payment, ok := recovered.Get("pay_2")
+		suite.True(ok)
+		suite.Equal(enums.PARTIALLY_REFUNDED, payment.Status)
+	})
+}
+
+// failingLog fails every append once err is set.
+type failingLog struct {
+	*eventlog.MemoryLog
+	err error
+}
+
+func (log *failingLog) Append(events ...eventlog.Event) ([]eventlog.Event, error) {
+	if log.err != nil {
+		return nil, log.err
+	}
+	return log.MemoryLog.Append(events...)
+}
+
+func (suite *paymentStoreTestSuite) Test_FailedWrites() {
+	suite.Run("When the log fails it should record none of the events", func() {
+		paymentLog := &failingLog{MemoryLog: eventlog.NewMemoryLog()}
+		paymentStore := store.NewPaymentStore(paymentLog, store.Options{})
+		suite.authorizeAndCapture(paymentStore, "pay_1")
+
+		paymentLog.err = errors.New("disk full")
+		_, err := paymentStore.Append("pay_1",
+			suite.newEvent("pay_1", store.REFUNDED, store.RefundedData{RefundId: "ref_1", Amount: 40}),
+			suite.newEvent("pay_1", store.REFUNDED, store.RefundedData{RefundId: "ref_2", Amount: 60}),
+		)
+		suite.ErrorIs(err, paymentLog.err)
+
+		payment, _ := paymentStore.Get("pay_1")
+		suite.Equal(enums.CAPTURED, payment.Status)
+		suite.Equal(0, payment.RefundedAmount)
+		events, err := paymentStore.Events("pay_1")
+		suite.NoError(err)
+		suite.Len(events, 3)
+	})
+
+	suite.Run("When the snapshot cannot be written it should still report the events as recorded", func() {
+		snapshotPath := filepath.Join(suite.dir, "missing", "payments.snapshot")
+		paymentStore := store.NewPaymentStore(eventlog.NewMemoryLog(), store.Options{SnapshotPath: snapshotPath, SnapshotEvery: 1})
+		suite.authorizeAndCapture(paymentStore, "pay_1")
+
+		payment, ok := paymentStore.Get("pay_1")
+		suite.True(ok)
+		suite.Equal(enums.CAPTURED, payment.Status)
+	})
+}
+
+func TestPaymentStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(paymentStoreTestSuite))
+}
@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/clock"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/eventlog"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/subscriptions"
+	"github.com/stretchr/testify/suite"
+)
+
+type schedulerTestSuite struct {
+	suite.Suite
+}
+
+var start = time.Date(2024, time.January, 31, 9, 0, 0, 0, time.UTC)
+
+func (suite *schedulerTestSuite) Test_AddMonths() {
+	suite.Equal(time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC), subscriptions.AddMonths(start, 1))
+	suite.Equal(time.Date(2024, time.March, 31, 9, 0, 0, 0, time.UTC), subscriptions.AddMonths(start, 2))
+	suite.Equal(time.Date(2025, time.January, 31, 9, 0, 0, 0, time.UTC), subscriptions.AddMonths(start, 12))
+}
+
+func (suite *schedulerTestSuite) Test_RunDue() {
+	suite.Run("When charges are authorized it should keep the billing day", func() {
+		store := subscriptions.NewStore()
+		subscription, _ := store.Create(subscriptions.Subscription{Amount: 100, Currency: "GBP", IntervalMonths: 1, StartAt: start})
+		scheduler := subscriptions.NewScheduler(store, func(subscriptions.Subscription) (string, bool, error) {
+			return "pay_1", true, nil
+		}, clock.NewFake(start))
+
+		suite.Equal(1, scheduler.RunDue(start))
+		charged, _ := store.Get(subscription.Id)
+		suite.Equal(time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC), charged.NextChargeAt)
+
+		suite.Equal(1, scheduler.RunDue(charged.NextChargeAt))
+		charged, _ = store.Get(subscription.Id)
+		suite.Equal(time.Date(2024, time.March, 31, 9, 0, 0, 0, time.UTC), charged.NextChargeAt)
+		suite.Equal([]string{"pay_1", "pay_1"}, charged.PaymentIds)
+	})
+
+	suite.Run("When a charge is declined it should retry on schedule and recover on success", func() {
+		store := subscriptions.NewStore()
+		subscription, _ := store.Create(subscriptions.Subscription{
+			Amount:         100,
+			Currency:       "GBP",
+			IntervalMonths: 1,
+			StartAt:        start,
+			RetrySchedule:  []time.Duration{24 * time.Hour, 72 * time.Hour},
+		})
+		authorized := false
+		scheduler := subscriptions.NewScheduler(store, func(subscriptions.Subscription) (string, bool, error) {
+			return "pay_1", authorized, nil
+		}, clock.NewFake(start))
+
+		scheduler.RunDue(start)
+		retried, _ := store.Get(subscription.Id)
+		suite.Equal(subscriptions.PAST_DUE, retried.Status)
+		suite.Equal(start.Add(24*time.Hour), retried.NextChargeAt)
+
+		suite.Equal(0, scheduler.RunDue(start.Add(time.Hour)))
+
+		authorized = true
+		scheduler.RunDue(start.Add(24 * time.Hour))
+		recovered, _ := store.Get(subscription.Id)
+		suite.Equal(subscriptions.ACTIVE, recovered.Status)
+		suite.Equal(0, recovered.Attempt)
+		suite.Equal(time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC), recovered.NextChargeAt)
+	})
+
+	suite.Run("When every retry fails it should mark the subscription unpaid", func() {
+		store := subscriptions.NewStore()
+		subscription, _ := store.Create(subscriptions.Subscription{
+			Amount:         100,
+			Currency:       "GBP",
+			IntervalMonths: 1,
+			StartAt:        start,
+			RetrySchedule:  []time.Duration{24 * time.Hour},
+		})
+		scheduler := subscriptions.NewScheduler(store, func(subscriptions.Subscription) (string, bool, error) {
+			return "", false, errors.New("bank unavailable")
+		}, clock.NewFake(start))
+
+		scheduler.RunDue(start)
+		scheduler.RunDue(start.Add(24 * time.Hour))
+		unpaid, _ := store.Get(subscription.Id)
+		suite.Equal(subscriptions.UNPAID, unpaid.Status)
+		suite.Empty(unpaid.PaymentIds)
+		suite.Equal(0, scheduler.RunDue(start.Add(48*time.Hour)))
+	})
+
+	suite.Run("When the subscription is cancelled it should not be charged", func() {
+		store := subscriptions.NewStore()
+		subscription, _ := store.Create(subscriptions.Subscription{Amount: 100, Currency: "GBP", IntervalMonths: 1, StartAt: start})
+		store.Cancel(subscription.Id, start)
+		scheduler := subscriptions.NewScheduler(store, func(subscriptions.Subscription) (string, bool, error) {
+			return "pay_1", true, nil
+		}, clock.NewFake(start))
+		suite.Equal(0, scheduler.RunDue(start))
+	})
+}
+
+func (suite *schedulerTestSuite) Test_Persistence() {
+	path := filepath.Join(suite.T().TempDir(), "subscriptions.log")
+	fileLog, err := eventlog.OpenFileLog(path)
+	suite.Require().NoError(err)
+	store, err := subscriptions.OpenStore(fileLog)
+	suite.Require().NoError(err)
+	retrying, err := store.Create(subscriptions.Subscription{MandateId: "man_1", Amount: 100, Currency: "GBP", IntervalMonths: 1, StartAt: start, RetrySchedule: []time.Duration{24 * time.Hour}})
+	suite.Require().NoError(err)
+	cancelled, err := store.Create(subscriptions.Subscription{MandateId: "man_2", Amount: 200, Currency: "GBP", IntervalMonths: 1, StartAt: start})
+	suite.Require().NoError(err)
+	retrying, err = store.RecordCharge(retrying.Id, "pay_1", false, start)
+	suite.Require().NoError(err)
+	cancelled, err = store.Cancel(cancelled.Id, start)
+	suite.Require().NoError(err)
+	fileLog.Close()
+
+	reopened, err := eventlog.OpenFileLog(path)
+	suite.Require().NoError(err)
+	defer reopened.Close()
+	recovered, err := subscriptions.OpenStore(reopened)
+	suite.Require().NoError(err)
+
+	recoveredRetrying, ok := recovered.Get(retrying.Id)
+	suite.True(ok)
+	suite.Equal(retrying, recoveredRetrying)
+	recoveredCancelled, ok := recovered.Get(cancelled.Id)
+	suite.True(ok)
+	suite.Equal(subscriptions.CANCELLED, recoveredCancelled.Status)
+	suite.Len(recovered.Due(start.Add(24*time.Hour)), 1)
+}
+
+func (suite *schedulerTestSuite) Test_Stop() {
+	scheduler := subscriptions.NewScheduler(subscriptions.NewStore(), func(subscriptions.Subscription) (string, bool, error) {
+		return "", false, nil
+	}, clock.NewFake(start))
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		scheduler.Start(time.Hour, stop)
+		close(stopped)
+	}()
+	close(stop)
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		suite.Fail("the scheduler did not stop")
+	}
+}
+
+func TestSchedulerTestSuite(t *testing.T) {
+	suite.Run(t, new(schedulerTestSuite))
+}
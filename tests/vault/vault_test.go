@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/clock"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/eventlog"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/ids"
+	"github.com/cko-recruitment/payment-gateway-challenge-go/pkg/vault"
+	"github.com/stretchr/testify/suite"
+)
+
+const cardNumber = "2222405343248877"
+
+type vaultTestSuite struct {
+	suite.Suite
+	key       []byte
+	fakeClock *clock.Fake
+}
+
+func (suite *vaultTestSuite) SetupTest() {
+	suite.key = []byte(strings.Repeat("k", 32))
+	suite.fakeClock = clock.NewFake(time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC))
+}
+
+func (suite *vaultTestSuite) Test_Tokenize() {
+	cardVault := vault.New()
+
+	suite.Run("It should return an opaque token for the card", func() {
+		token, err := cardVault.Tokenize(cardNumber)
+		suite.NoError(err)
+		suite.NoError(ids.Validate(ids.CARD, token))
+		suite.NotContains(token, "8877")
+
+		number, err := cardVault.Detokenize(token)
+		suite.NoError(err)
+		suite.Equal(cardNumber, number)
+	})
+
+	suite.Run("The same card should get the same token", func() {
+		first, err := cardVault.Tokenize(cardNumber)
+		suite.NoError(err)
+		second, err := cardVault.Tokenize(cardNumber)
+		suite.NoError(err)
+		other, err := cardVault.Tokenize("2222405343248112")
+		suite.NoError(err)
+		suite.Equal(first, second)
+		suite.NotEqual(first, other)
+	})
+
+	suite.Run("When the token is unknown it should return ErrTokenNotFound", func() {
+		_, err := cardVault.Detokenize("crd_unknown")
+		suite.ErrorIs(err, vault.ErrTokenNotFound)
+	})
+}
+
+func (suite *vaultTestSuite) Test_Persistence() {
+	path := filepath.Join(suite.T().TempDir(), "vault.log")
+	fileLog, err := eventlog.OpenFileLog(path)
+	suite.Require().NoError(err)
+	cardVault, err := vault.Open(fileLog, suite.key, suite.fakeClock)
+	suite.Require().NoError(err)
+	token, err := cardVault.Tokenize(cardNumber)
+	suite.Require().NoError(err)
+	fileLog.Close()
+
+	suite.Run("The event should be timestamped with the vault's clock", func() {
+		err := fileLog.Replay(0, func(event eventlog.Event) error {
+			suite.Equal(suite.fakeClock.Now(), event.Timestamp.UTC())
+			return nil
+		})
+		suite.NoError(err)
+	})
+
+	suite.Run("The log should not contain the card number", func() {
+		content, err := os.ReadFile(path)
+		suite.NoError(err)
+		suite.Contains(string(content), token)
+		suite.NotContains(string(content), cardNumber)
+	})
+
+	suite.Run("It should recover its tokens from the log", func() {
+		reopened, err := eventlog.OpenFileLog(path)
+		suite.Require().NoError(err)
+		defer reopened.Close()
+		recovered, err := vault.Open(reopened, suite.key, suite.fakeClock)
+		suite.Require().NoError(err)
+
+		number, err := recovered.Detokenize(token)
+		suite.NoError(err)
+		suite.Equal(cardNumber, number)
+		again, err := recovered.Tokenize(cardNumber)
+		suite.NoError(err)
+		suite.Equal(token, again)
+	})
+
+	suite.Run("When the key is wrong it should refuse to open", func() {
+		reopened, err := eventlog.OpenFileLog(path)
+		suite.Require().NoError(err)
+		defer reopened.Close()
+		_, err = vault.Open(reopened, []byte(strings.Repeat("x", 32)), suite.fakeClock)
+		suite.Error(err)
+	})
+
+	suite.Run("When the key is not 32 bytes it should refuse to open", func() {
+		_, err := vault.Open(eventlog.NewMemoryLog(), []byte("short"), suite.fakeClock)
+		suite.Error(err)
+	})
+}
+
+func TestVaultTestSuite(t *testing.T) {
+	suite.Run(t, new(vaultTestSuite))
+}